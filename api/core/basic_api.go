@@ -1,7 +1,7 @@
 package core
 
 import (
-	appsv1beta1 "github.com/kuberator/api/v1beta1"
+	appsv1 "github.com/kuberator/api/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -10,18 +10,21 @@ import (
 
 type BasicCrd interface {
 	client.Object
-	GetSpec() appsv1beta1.MiddlewareClusterSpec
-	GetStatus() *appsv1beta1.MiddlewareClusterStatus
-	SetStatus(status appsv1beta1.MiddlewareClusterStatus)
+	GetSpec() appsv1.MiddlewareClusterSpec
+	GetStatus() *appsv1.MiddlewareClusterStatus
+	SetStatus(status appsv1.MiddlewareClusterStatus)
 	GetObjectMeta() metav1.ObjectMeta
 	GetTypeMeta() metav1.TypeMeta
+	// Default set the defaults of the spec, it is the same with the defaulting webhook.
+	Default()
 }
 
 type BasicSpec interface {
 	GetVersion() string
-	GetComponents() []*appsv1beta1.CategoryClusterComponent
-	GetConf() []*appsv1beta1.NamedProperties
-	GetService() []*appsv1beta1.CategoryClusterService
-	GetIngress() []*appsv1beta1.CategoryClusterIngress
-	GetCategoryResource(category appsv1beta1.Category) interface{}
+	GetComponents() []*appsv1.CategoryClusterComponent
+	GetConf() []*appsv1.NamedProperties
+	GetService() []*appsv1.CategoryClusterService
+	GetIngress() []*appsv1.CategoryClusterIngress
+	GetCategoryResource(category appsv1.Category) interface{}
+	GetPrunePolicy(kind appsv1.ComponentKind) appsv1.PrunePolicy
 }
@@ -3,7 +3,7 @@ package core
 import (
 	"context"
 	"github.com/go-logr/logr"
-	appsv1beta1 "github.com/kuberator/api/v1beta1"
+	appsv1 "github.com/kuberator/api/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,7 +36,7 @@ type (
 
 	// ActionCommand action command
 	ActionCommand struct {
-		Action         appsv1beta1.Action     `json:"action,omitempty"`
+		Action         appsv1.Action          `json:"action,omitempty"`
 		Message        string                 `json:"message"`
 		TargetResource *ReferenceObject       `json:"targetResource"`
 		ResourceMeta   TypedCategoryComponent `json:"resourceMeta,omitempty"`
@@ -48,7 +48,7 @@ type (
 	// ReferenceObject reference object.
 	ReferenceObject struct {
 		// target resource category
-		Category appsv1beta1.Category `json:"category,omitempty"`
+		Category appsv1.Category `json:"category,omitempty"`
 		// target build-in object template pointer. if restart/pvc/pdb operator, it is the select template with select labels.
 		Target client.Object `json:"target,omitempty"`
 		// the extends args
@@ -57,9 +57,9 @@ type (
 
 	// CategoryComponentObject category object.
 	CategoryComponentObject struct {
-		appsv1beta1.CommonCategoryComponent `json:",inline"`
-		Object                              interface{}
-		Reference                           TypedCategoryComponent
+		appsv1.CommonCategoryComponent `json:",inline"`
+		Object                         interface{}
+		Reference                      TypedCategoryComponent
 	}
 
 	// ComponentArgs component context
@@ -89,7 +89,7 @@ func Result() CommandResult {
 }
 
 func (this CommandResult) Print(log logr.Logger) {
-	if log == nil {
+	if log.GetSink() == nil {
 		log = ctrl.Log.WithName("CommandResult")
 	}
 	this.Get()
@@ -2,7 +2,7 @@ package extends
 
 import (
 	"github.com/kuberator/api/core"
-	appsv1beta1 "github.com/kuberator/api/v1beta1"
+	appsv1 "github.com/kuberator/api/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -53,10 +53,20 @@ type (
 		// return: result
 		PostApply(args core.ComponentArgs, cmd core.ActionCommand, result core.CommandResult) core.CommandResult
 	}
+
+	// UserProvisioner the extension of the component category implements it to provision the named users inside the middleware.
+	// +kubebuilder:object:generate=false
+	UserProvisioner interface {
+		// ProvisionUser create or update the user, it is called again when the password of the user is changed.
+		// args: context arg, the desired is the credentials secret of the user.
+		// user: the named user of the component.
+		// return: the user is provisioned again in the next reconcile when failed.
+		ProvisionUser(args core.ComponentArgs, user appsv1.ComponentUser) error
+	}
 )
 
 type ComponentExtendStageLifeCycle struct {
-	appsv1beta1.CommonCategoryComponent
+	appsv1.CommonCategoryComponent
 }
 
 func (this ComponentExtendStageLifeCycle) PostMake(args core.ComponentArgs, resourceLine *core.ResourcesLine) (*core.ResourcesLine, error) {
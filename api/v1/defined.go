@@ -0,0 +1,568 @@
+package v1
+
+import (
+	"encoding/json"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+const (
+	Creating         State = "Creating"
+	Reconciling      State = "Reconciling"
+	WaitRestart      State = "WaitRestart"
+	Restarting       State = "Restarting"
+	Updating         State = "Updating"
+	Stopping         State = "Stopping"
+	PartiallyStopped State = "PartiallyStopped"
+	Stopped          State = "Stopped"
+	Running          State = "Running"
+	Upgrading        State = "Upgrading"
+	Pending          State = "Pending"
+	Complicate       State = "Complicate"
+	Success          State = "Success"
+	Failed           State = "Failed"
+	Unknown          State = "Unknown"
+	Init             State = "Init"
+	Waiting          State = "Waiting"
+	Preparing        State = "Preparing"
+	InProgress       State = "InProgress"
+	NotReady         State = "NotReady"
+	Ready            State = "Ready"
+	Deleted          State = "Deleted"
+	Cancelled        State = "Cancelled"
+	Suspended        State = "Suspended"
+	Completed        State = "Completed"
+	Terminated       State = "Terminated"
+	Error            State = "Error"
+)
+
+const (
+	Create        Action = "Create"
+	Update        Action = "Update"
+	RollingUpdate Action = "RollingUpdate"
+	Scale         Action = "Scale"
+	Recycle       Action = "Recycle"
+	Delete        Action = "Delete"
+	SoftDelete    Action = "SoftDelete"
+	Stop          Action = "Stop"
+	Start         Action = "Start"
+	Restart       Action = "Restart"
+	ReCreate      Action = "ReCreate"
+	Non           Action = "Non"
+	FailOver      Action = "FailOver"
+	Rotate        Action = "Rotate"
+	Provision     Action = "Provision"
+	Issue         Action = "Issue"
+	Reload        Action = "Reload"
+)
+
+const (
+	// ConditionReady all the components are ready.
+	ConditionReady = "Ready"
+	// ConditionProgressing the reconcile action is in progress.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded the reconcile action failed.
+	ConditionDegraded = "Degraded"
+	// ConditionPaused the reconcile is paused by the instance pause label.
+	ConditionPaused = "Paused"
+	// ConditionVersionSupported the version is listed by the catalog and not deprecated.
+	ConditionVersionSupported = "VersionSupported"
+)
+
+const (
+	// UpgradePreHookStep the upgrade step which runs the PreUpgrade hook.
+	UpgradePreHookStep = "PreUpgrade"
+	// UpgradePostHookStep the upgrade step which runs the PostUpgrade hook.
+	UpgradePostHookStep = "PostUpgrade"
+)
+
+const (
+	// PolicyPrune delete the resource when it is removed from the spec.
+	PolicyPrune PrunePolicy = "Prune"
+	// PolicyRetain keep the resource when it is removed from the spec.
+	PolicyRetain PrunePolicy = "Retain"
+)
+
+const (
+	Yaml ConfType = "yaml"
+	Json ConfType = "json"
+	Ini  ConfType = "ini"
+	Text ConfType = "text"
+)
+
+const (
+	// ReloadRestart restart all the pods of the category at once.
+	ReloadRestart ReloadPolicy = "Restart"
+	// ReloadRollingRestart roll the pods of the workload by the conf checksum of the pod template.
+	ReloadRollingRestart ReloadPolicy = "RollingRestart"
+	// ReloadSignal send the SIGHUP to the main process of the pods.
+	ReloadSignal ReloadPolicy = "Signal"
+	// ReloadHTTP call the reload endpoint of the pods.
+	ReloadHTTP ReloadPolicy = "HTTP"
+	// ReloadNone the pods load the changed conf by themselves.
+	ReloadNone ReloadPolicy = "None"
+)
+
+type (
+	// Category component app role
+	Category string
+
+	// ComponentName component name
+	ComponentName string
+
+	// ComponentKind build-in resource kind name
+	ComponentKind string
+
+	// State of component
+	State string
+
+	// Action operator action
+	Action string
+
+	// Component the app component
+	Component struct {
+		// build in resource kind
+		// +kubebuilder:validation:Required
+		Kind ComponentKind `json:"kind,omitempty"`
+	}
+
+	// ConfType conf file type (support: yaml,json,ini,text)
+	ConfType string
+
+	// ReloadPolicy how the pods load the changed conf (support: Restart,RollingRestart,Signal,HTTP,None)
+	// +kubebuilder:validation:Enum=Restart;RollingRestart;Signal;HTTP;None
+	ReloadPolicy string
+
+	// PrunePolicy how to handle the resource which is removed from the spec (support: Prune,Retain)
+	// +kubebuilder:validation:Enum=Prune;Retain
+	PrunePolicy string
+
+	// BasicAuth the basic auth of the component, the password is never stored in the spec.
+	BasicAuth struct {
+		// +optional
+		Role string `json:"role,omitempty"`
+		// +optional
+		Username string `json:"username,omitempty"`
+		// Salt is no longer used, the password is generated randomly.
+		// +optional
+		Salt       string `json:"salt,omitempty"`
+		AuthSource `json:",inline"`
+	}
+
+	// AuthSource where the password of the component comes from.
+	// When none is set, the password is generated once and kept in the managed secret.
+	AuthSource struct {
+		// PasswordSecretRef the password is read from the key of the secret by the component,
+		// the managed secret keeps the role and username only.
+		// +optional
+		PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+		// ExistingSecret the secret provides all the credentials by the keys username and password, the managed secret is not created.
+		// +optional
+		ExistingSecret string `json:"existingSecret,omitempty"`
+	}
+
+	// ComponentUser the named user of the component, the credentials are kept in the secret of the user.
+	ComponentUser struct {
+		// Name the username, it is unique in the component.
+		// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+		// +kubebuilder:validation:MaxLength=63
+		Name string `json:"name"`
+		// +optional
+		Role string `json:"role,omitempty"`
+		// Env inject the <NAME>_USERNAME and <NAME>_PASSWORD env into the containers of the component.
+		// +optional
+		Env        bool `json:"env,omitempty"`
+		AuthSource `json:",inline"`
+	}
+
+	// LegacyAuth the plaintext auth fields of v1beta1, they are left in the annotation by the conversion of the previous
+	// release, and moved into the managed secret by the reconcile.
+	LegacyAuth struct {
+		Password string `json:"password,omitempty"`
+		Auth     string `json:"auth,omitempty"`
+	}
+
+	// ComponentNetworkPolicy the ingress rules of the generated network policy.
+	// The pods of the same cluster are always allowed, the others are only allowed on the ports of the component services.
+	ComponentNetworkPolicy struct {
+		// Clients the peers which are allowed to access the ports of the component services.
+		// The ports are allowed from anywhere when it is empty.
+		// +optional
+		Clients []networkingv1.NetworkPolicyPeer `json:"clients,omitempty"`
+	}
+
+	// ComponentMonitoring the prometheus monitoring of the component.
+	// The objects of the prometheus operator are not created when its CRDs are not installed.
+	ComponentMonitoring struct {
+		// Kind the monitor kind (support: ServiceMonitor,PodMonitor), defaults to ServiceMonitor.
+		// The ServiceMonitor scrapes the services of the component category.
+		// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+		// +optional
+		Kind ComponentKind `json:"kind,omitempty"`
+		// Endpoints the scrape endpoints of the pods.
+		Endpoints []MonitorEndpoint `json:"endpoints"`
+		// Labels the extra labels of the monitor and rule, they are usually used by the prometheus to select them.
+		// +optional
+		Labels map[string]string `json:"labels,omitempty"`
+		// RuleGroups the alert rules, the PrometheusRule is created when it is not empty.
+		// +optional
+		RuleGroups []MonitorRuleGroup `json:"ruleGroups,omitempty"`
+	}
+
+	// MonitorEndpoint the scrape endpoint.
+	MonitorEndpoint struct {
+		// Port the name of the service port or the container port.
+		Port string `json:"port"`
+		// Path the metrics path, defaults to /metrics.
+		// +optional
+		Path string `json:"path,omitempty"`
+		// Scheme the http scheme (support: http,https).
+		// +kubebuilder:validation:Enum=http;https
+		// +optional
+		Scheme string `json:"scheme,omitempty"`
+		// Interval the scrape interval, e.g. 30s.
+		// +optional
+		Interval string `json:"interval,omitempty"`
+	}
+
+	// MonitorRuleGroup the group of the alert rules.
+	MonitorRuleGroup struct {
+		Name string `json:"name"`
+		// Interval the evaluation interval of the group.
+		// +optional
+		Interval string        `json:"interval,omitempty"`
+		Rules    []MonitorRule `json:"rules"`
+	}
+
+	// MonitorRule the alert rule.
+	MonitorRule struct {
+		Alert string `json:"alert"`
+		// Expr the PromQL expression.
+		Expr string `json:"expr"`
+		// For the alert fires when the expression is true for the duration.
+		// +optional
+		For string `json:"for,omitempty"`
+		// +optional
+		Labels map[string]string `json:"labels,omitempty"`
+		// +optional
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+
+	// ClusterTLS the certificates of the components are issued by the cluster CA.
+	// The certificate covers the services of the component and the pod names behind the peer service,
+	// it is mounted into all the containers and renewed with a rolling restart before expiry.
+	ClusterTLS struct {
+		// MountPath the directory of the tls.crt, tls.key and ca.crt in the containers, defaults to /etc/tls.
+		// +optional
+		MountPath string `json:"mountPath,omitempty"`
+		// Duration the validity of the component certificate, defaults to 2160h.
+		// +optional
+		Duration *metav1.Duration `json:"duration,omitempty"`
+		// RenewBefore the certificate is renewed when it expires within the duration, defaults to 720h.
+		// +optional
+		RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	}
+
+	// ComponentCertificate the certificate of the component is issued by the cert-manager instead of the cluster CA.
+	// The Certificate is not created when the cert-manager CRDs are not installed.
+	ComponentCertificate struct {
+		// IssuerRef the Issuer or ClusterIssuer which signs the certificate.
+		IssuerRef CertificateIssuerRef `json:"issuerRef"`
+		// MountPath the directory of the tls.crt, tls.key and ca.crt in the containers, defaults to /etc/tls.
+		// +optional
+		MountPath string `json:"mountPath,omitempty"`
+		// Duration the validity of the certificate, defaults to 2160h.
+		// +optional
+		Duration *metav1.Duration `json:"duration,omitempty"`
+		// RenewBefore the certificate is renewed by the cert-manager when it expires within the duration, defaults to 720h.
+		// +optional
+		RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+	}
+
+	// ComponentReload how the pods of the component load the changed conf.
+	ComponentReload struct {
+		// Policy the reload policy of the files which have no policy, defaults to Restart.
+		// +optional
+		Policy ReloadPolicy `json:"policy,omitempty"`
+		// Container the container of the main process which receives the SIGHUP, defaults to the first container.
+		// +optional
+		Container string `json:"container,omitempty"`
+		// HTTP the reload endpoint of the pods, it is required by the HTTP policy.
+		// +optional
+		HTTP *ReloadEndpoint `json:"http,omitempty"`
+	}
+
+	// ImmutableConf the configMap of the component is immutable and named by the revision of its content.
+	// The pods are restarted with the new configMap when the conf is changed, so that the reload policy is not used.
+	ImmutableConf struct {
+		// RevisionHistoryLimit the number of the previous configMaps kept for the rollback, defaults to 3.
+		// The configMap mounted by the pods is kept until the pods are restarted.
+		// +kubebuilder:validation:Minimum=0
+		// +optional
+		RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	}
+
+	// ReloadEndpoint the http endpoint which reloads the conf, it is called by POST.
+	ReloadEndpoint struct {
+		// Port the container port of the endpoint.
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=65535
+		Port int32 `json:"port"`
+		// Path the path of the endpoint, defaults to /reload.
+		// +optional
+		Path string `json:"path,omitempty"`
+		// Scheme the http scheme (support: HTTP,HTTPS), defaults to HTTP.
+		// The certificate of the pod is not verified.
+		// +kubebuilder:validation:Enum=HTTP;HTTPS
+		// +optional
+		Scheme corev1.URIScheme `json:"scheme,omitempty"`
+	}
+
+	// UpgradePlan how the components are upgraded when the version of the cluster is changed.
+	// The components are upgraded one by one, the next one waits until the previous one passes the health gate.
+	UpgradePlan struct {
+		// Paths the allowed upgrade paths, the version change which matches none of them is refused.
+		// If not set, all the version changes are allowed.
+		// +optional
+		Paths []UpgradePath `json:"paths,omitempty"`
+		// Order the categories of the components in the upgrade order, e.g. the followers before the leader.
+		// The components which are not listed are upgraded after them in the spec order.
+		// +optional
+		Order []Category `json:"order,omitempty"`
+		// PreUpgrade the hook Job which runs before the first component is upgraded.
+		// +optional
+		PreUpgrade *UpgradeHook `json:"preUpgrade,omitempty"`
+		// PostUpgrade the hook Job which runs after the last component is upgraded.
+		// +optional
+		PostUpgrade *UpgradeHook `json:"postUpgrade,omitempty"`
+		// HealthGate the condition which the upgraded component meets before the next step.
+		// +optional
+		HealthGate *UpgradeHealthGate `json:"healthGate,omitempty"`
+	}
+
+	// UpgradePath the versions which the source version can be upgraded to.
+	// The versions are the glob patterns, e.g. 3.8.* matches all the patch versions of 3.8.
+	UpgradePath struct {
+		From string `json:"from"`
+		// +kubebuilder:validation:MinItems=1
+		To []string `json:"to"`
+	}
+
+	// UpgradeHook the Job which runs with the UPGRADE_FROM and UPGRADE_TO env, the upgrade waits until it is completed.
+	// The failed Job is run again when it is deleted.
+	UpgradeHook struct {
+		Job batchv1.JobSpec `json:"job"`
+	}
+
+	// UpgradeHealthGate all the pods of the upgraded component are updated and ready.
+	UpgradeHealthGate struct {
+		// Delay the least duration of the step, so that the component is checked after its workload is applied, defaults to 10s.
+		// +optional
+		Delay *metav1.Duration `json:"delay,omitempty"`
+		// Timeout the upgrade is failed when the component is not ready in the duration, defaults to 10m.
+		// The upgrade goes on when the component becomes ready later.
+		// +optional
+		Timeout *metav1.Duration `json:"timeout,omitempty"`
+	}
+
+	// CertificateIssuerRef the issuer of the cert-manager.
+	CertificateIssuerRef struct {
+		// Name the name of the issuer.
+		Name string `json:"name"`
+		// Kind the kind of the issuer (support: Issuer,ClusterIssuer), defaults to Issuer.
+		// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+		// +optional
+		Kind string `json:"kind,omitempty"`
+		// Group the api group of the issuer, defaults to cert-manager.io.
+		// +optional
+		Group string `json:"group,omitempty"`
+	}
+
+	// PropertiesOptions the options of the properties which are not in v1beta1.
+	PropertiesOptions struct {
+		// Template the data is a go template rendered with the topology of the cluster, e.g. {{ .Namespace }}.
+		// +optional
+		Template bool `json:"template,omitempty"`
+		// SubPath the file is mounted by the sub path, so that the other files of the path are not hidden.
+		// The file mounted by the sub path is not updated until the pod is restarted, so it is rolled unless it is restarted.
+		// The path is mounted as a directory when any of its files is not mounted by the sub path.
+		// +optional
+		SubPath bool `json:"subPath,omitempty"`
+		// Containers the names of the containers and init containers which mount the file, defaults to all the containers.
+		// +optional
+		Containers []string `json:"containers,omitempty"`
+		// ReloadPolicy how the pods load the file when it is changed, defaults to the policy of the component.
+		// +optional
+		ReloadPolicy ReloadPolicy `json:"reloadPolicy,omitempty"`
+		// Schema the JSON Schema which the yaml, json or ini data is validated with before it is applied,
+		// the ini is validated as the object of its sections, and the keys out of the sections are at the top.
+		// The external references of the schema are not loaded.
+		// +optional
+		Schema string `json:"schema,omitempty"`
+	}
+
+	// HubSpec the spec fields which are not in v1beta1, they are kept in the annotation for the round trip.
+	HubSpec struct {
+		TLS     *ClusterTLS                  `json:"tls,omitempty"`
+		Upgrade *UpgradePlan                 `json:"upgrade,omitempty"`
+		Catalog string                       `json:"catalog,omitempty"`
+		Conf    map[string]PropertiesOptions `json:"conf,omitempty"`
+	}
+
+	// HubFields the component fields which are not in v1beta1, they are kept in the annotation for the round trip.
+	HubFields struct {
+		NetworkPolicy *ComponentNetworkPolicy      `json:"networkPolicy,omitempty"`
+		Monitoring    *ComponentMonitoring         `json:"monitoring,omitempty"`
+		AuthSource    *AuthSource                  `json:"authSource,omitempty"`
+		Users         []ComponentUser              `json:"users,omitempty"`
+		Certificate   *ComponentCertificate        `json:"certificate,omitempty"`
+		Reload        *ComponentReload             `json:"reload,omitempty"`
+		ImmutableConf *ImmutableConf               `json:"immutableConf,omitempty"`
+		Properties    map[string]PropertiesOptions `json:"properties,omitempty"`
+	}
+)
+
+// RotateCredentialsAnnotation the generated passwords are rotated when the value of the annotation is changed.
+const RotateCredentialsAnnotation = "apps.devless.toplogy.com/rotate-credentials"
+
+// LegacyAuthAnnotation the annotation keeps the v1beta1 plaintext auth by the component name. It is never written by the
+// conversion any more, the annotation left by the previous release is removed once the managed secret keeps the password.
+const LegacyAuthAnnotation = "apps.devless.toplogy.com/v1beta1-auth"
+
+// GetLegacyAuth the v1beta1 plaintext auth of the component converted to v1.
+func GetLegacyAuth(annotations map[string]string, name ComponentName) LegacyAuth {
+	var legacy map[ComponentName]LegacyAuth
+	if data, ok := annotations[LegacyAuthAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &legacy)
+	}
+	return legacy[name]
+}
+
+// RemoveLegacyAuth remove the v1beta1 plaintext auth of the component from the annotations,
+// the annotation is removed when no component keeps it.
+func RemoveLegacyAuth(annotations map[string]string, name ComponentName) error {
+	var legacy map[ComponentName]LegacyAuth
+	if data, ok := annotations[LegacyAuthAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &legacy)
+	}
+	delete(legacy, name)
+	if len(legacy) == 0 {
+		delete(annotations, LegacyAuthAnnotation)
+		return nil
+	}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		return err
+	}
+	annotations[LegacyAuthAnnotation] = string(data)
+	return nil
+}
+
+// HubFieldsAnnotation the annotation keeps the v1 only fields by the component name when converted to v1beta1.
+const HubFieldsAnnotation = "apps.devless.toplogy.com/v1-fields"
+
+// HubSpecAnnotation the annotation keeps the v1 only fields of the spec when converted to v1beta1.
+const HubSpecAnnotation = "apps.devless.toplogy.com/v1-spec"
+
+// ClusterAnnotations the annotations which the operator keeps on the cluster for itself, they are never inherited by
+// the resources of the cluster, the legacy auth holds the plaintext password.
+var ClusterAnnotations = []string{LegacyAuthAnnotation, HubFieldsAnnotation, HubSpecAnnotation, RotateCredentialsAnnotation}
+
+// GetHubSpec the v1 only fields of the spec.
+func GetHubSpec(spec MiddlewareClusterSpec) HubSpec {
+	return HubSpec{TLS: spec.TLS, Upgrade: spec.Upgrade, Catalog: spec.Catalog, Conf: GetPropertiesOptions(spec.Conf)}
+}
+
+// IsEmpty none of the v1 only fields is set.
+func (this HubSpec) IsEmpty() bool {
+	return reflect.DeepEqual(this, HubSpec{})
+}
+
+// Restore set the v1 only fields to the spec.
+func (this HubSpec) Restore(spec *MiddlewareClusterSpec) {
+	spec.TLS = this.TLS
+	spec.Upgrade = this.Upgrade
+	spec.Catalog = this.Catalog
+	RestorePropertiesOptions(spec.Conf, this.Conf)
+}
+
+// GetAnnotatedHubSpec the v1 only fields of the spec kept in the v1beta1 annotation.
+func GetAnnotatedHubSpec(annotations map[string]string) HubSpec {
+	var spec HubSpec
+	if data, ok := annotations[HubSpecAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &spec)
+	}
+	return spec
+}
+
+// GetHubFields the v1 only fields of the component.
+func GetHubFields(component *CategoryClusterComponent) HubFields {
+	fields := HubFields{
+		NetworkPolicy: component.NetworkPolicy,
+		Monitoring:    component.Monitoring,
+		Users:         component.Users,
+		Certificate:   component.Certificate,
+		Reload:        component.Reload,
+		ImmutableConf: component.ImmutableConf,
+		Properties:    GetPropertiesOptions(component.Properties),
+	}
+	if component.Auth != nil && !reflect.DeepEqual(component.Auth.AuthSource, AuthSource{}) {
+		fields.AuthSource = &component.Auth.AuthSource
+	}
+	return fields
+}
+
+// IsEmpty none of the v1 only fields is set.
+func (this HubFields) IsEmpty() bool {
+	return reflect.DeepEqual(this, HubFields{})
+}
+
+// Restore set the v1 only fields to the component.
+func (this HubFields) Restore(component *CategoryClusterComponent) {
+	component.NetworkPolicy = this.NetworkPolicy
+	component.Monitoring = this.Monitoring
+	component.Users = this.Users
+	component.Certificate = this.Certificate
+	component.Reload = this.Reload
+	component.ImmutableConf = this.ImmutableConf
+	RestorePropertiesOptions(component.Properties, this.Properties)
+	if this.AuthSource != nil && component.Auth != nil {
+		component.Auth.AuthSource = *this.AuthSource
+	}
+}
+
+// GetPropertiesOptions the options of the properties by the properties name, the empty options are omitted.
+func GetPropertiesOptions(properties []*NamedProperties) map[string]PropertiesOptions {
+	var options map[string]PropertiesOptions
+	for _, p := range properties {
+		if p == nil || reflect.DeepEqual(p.PropertiesOptions, PropertiesOptions{}) {
+			continue
+		}
+		if options == nil {
+			options = map[string]PropertiesOptions{}
+		}
+		options[p.PropertiesName()] = p.PropertiesOptions
+	}
+	return options
+}
+
+// RestorePropertiesOptions set the options to the properties by the properties name.
+func RestorePropertiesOptions(properties []*NamedProperties, options map[string]PropertiesOptions) {
+	for _, p := range properties {
+		if p != nil {
+			p.PropertiesOptions = options[p.PropertiesName()]
+		}
+	}
+}
+
+// GetAnnotatedHubFields the v1 only fields of the component kept in the v1beta1 annotation.
+func GetAnnotatedHubFields(annotations map[string]string, name ComponentName) HubFields {
+	var fields map[ComponentName]HubFields
+	if data, ok := annotations[HubFieldsAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &fields)
+	}
+	return fields[name]
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/api/autoscaling/v2beta2"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The helpers convert the spec between the current and the deprecated kubernetes api types.
+// They are used by the conversion webhook and the handlers building the resource for the old clusters.
+
+// ConvertJSON convert the types which have the same schema in both versions.
+func ConvertJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// FromAutoscalingV2beta2 the autoscaling v2 metrics and behavior have the same schema with v2beta2.
+func FromAutoscalingV2beta2(metrics []v2beta2.MetricSpec, behavior *v2beta2.HorizontalPodAutoscalerBehavior) ([]autoscalingv2.MetricSpec, *autoscalingv2.HorizontalPodAutoscalerBehavior, error) {
+	var dstMetrics []autoscalingv2.MetricSpec
+	var dstBehavior *autoscalingv2.HorizontalPodAutoscalerBehavior
+	if metrics != nil {
+		dstMetrics = []autoscalingv2.MetricSpec{}
+		if err := ConvertJSON(metrics, &dstMetrics); err != nil {
+			return nil, nil, err
+		}
+	}
+	if behavior != nil {
+		dstBehavior = &autoscalingv2.HorizontalPodAutoscalerBehavior{}
+		if err := ConvertJSON(behavior, dstBehavior); err != nil {
+			return nil, nil, err
+		}
+	}
+	return dstMetrics, dstBehavior, nil
+}
+
+// ToAutoscalingV2beta2 convert the autoscaling v2 metrics and behavior to v2beta2.
+func ToAutoscalingV2beta2(metrics []autoscalingv2.MetricSpec, behavior *autoscalingv2.HorizontalPodAutoscalerBehavior) ([]v2beta2.MetricSpec, *v2beta2.HorizontalPodAutoscalerBehavior, error) {
+	var dstMetrics []v2beta2.MetricSpec
+	var dstBehavior *v2beta2.HorizontalPodAutoscalerBehavior
+	if metrics != nil {
+		dstMetrics = []v2beta2.MetricSpec{}
+		if err := ConvertJSON(metrics, &dstMetrics); err != nil {
+			return nil, nil, err
+		}
+	}
+	if behavior != nil {
+		dstBehavior = &v2beta2.HorizontalPodAutoscalerBehavior{}
+		if err := ConvertJSON(behavior, dstBehavior); err != nil {
+			return nil, nil, err
+		}
+	}
+	return dstMetrics, dstBehavior, nil
+}
+
+// FromIngressSpecV1beta1 convert the networking v1beta1 ingress spec to v1.
+func FromIngressSpecV1beta1(src v1beta1.IngressSpec) networkingv1.IngressSpec {
+	src = *src.DeepCopy()
+	dst := networkingv1.IngressSpec{
+		IngressClassName: src.IngressClassName,
+		DefaultBackend:   fromIngressBackendV1beta1(src.Backend),
+	}
+	for _, tls := range src.TLS {
+		dst.TLS = append(dst.TLS, networkingv1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range src.Rules {
+		target := networkingv1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			target.HTTP = &networkingv1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				target.HTTP.Paths = append(target.HTTP.Paths, networkingv1.HTTPIngressPath{
+					Path:     path.Path,
+					PathType: (*networkingv1.PathType)(path.PathType),
+					Backend:  *fromIngressBackendV1beta1(&path.Backend),
+				})
+			}
+		}
+		dst.Rules = append(dst.Rules, target)
+	}
+	return dst
+}
+
+// ToIngressSpecV1beta1 convert the networking v1 ingress spec to v1beta1.
+func ToIngressSpecV1beta1(src networkingv1.IngressSpec) v1beta1.IngressSpec {
+	src = *src.DeepCopy()
+	dst := v1beta1.IngressSpec{
+		IngressClassName: src.IngressClassName,
+		Backend:          toIngressBackendV1beta1(src.DefaultBackend),
+	}
+	for _, tls := range src.TLS {
+		dst.TLS = append(dst.TLS, v1beta1.IngressTLS{Hosts: tls.Hosts, SecretName: tls.SecretName})
+	}
+	for _, rule := range src.Rules {
+		target := v1beta1.IngressRule{Host: rule.Host}
+		if rule.HTTP != nil {
+			target.HTTP = &v1beta1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				target.HTTP.Paths = append(target.HTTP.Paths, v1beta1.HTTPIngressPath{
+					Path:     path.Path,
+					PathType: (*v1beta1.PathType)(path.PathType),
+					Backend:  *toIngressBackendV1beta1(&path.Backend),
+				})
+			}
+		}
+		dst.Rules = append(dst.Rules, target)
+	}
+	return dst
+}
+
+func fromIngressBackendV1beta1(src *v1beta1.IngressBackend) *networkingv1.IngressBackend {
+	if src == nil {
+		return nil
+	}
+	dst := &networkingv1.IngressBackend{Resource: src.Resource}
+	if len(src.ServiceName) > 0 || src.ServicePort != (intstr.IntOrString{}) {
+		dst.Service = &networkingv1.IngressServiceBackend{Name: src.ServiceName}
+		if src.ServicePort.Type == intstr.String {
+			dst.Service.Port.Name = src.ServicePort.StrVal
+		} else {
+			dst.Service.Port.Number = src.ServicePort.IntVal
+		}
+	}
+	return dst
+}
+
+func toIngressBackendV1beta1(src *networkingv1.IngressBackend) *v1beta1.IngressBackend {
+	if src == nil {
+		return nil
+	}
+	dst := &v1beta1.IngressBackend{Resource: src.Resource}
+	if src.Service != nil {
+		dst.ServiceName = src.Service.Name
+		if len(src.Service.Port.Name) > 0 {
+			dst.ServicePort = intstr.FromString(src.Service.Port.Name)
+		} else {
+			dst.ServicePort = intstr.FromInt(int(src.Service.Port.Number))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+const (
+	// DefaultComponentKind the build-in kind of the component.
+	DefaultComponentKind ComponentKind = "StatefulSet"
+	// DeploymentComponentKind the build-in kind of the stateless component.
+	DeploymentComponentKind ComponentKind = "Deployment"
+	// DaemonSetComponentKind the build-in kind of the per-node component.
+	DaemonSetComponentKind ComponentKind = "DaemonSet"
+	// DefaultMonitorKind the monitor kind of the component monitoring.
+	DefaultMonitorKind ComponentKind = "ServiceMonitor"
+	// DefaultMixJobKind the build-in kind of the mix job.
+	DefaultMixJobKind ComponentKind = "CronJob"
+	// DefaultRevisionHistoryLimit the revision history limit of the component.
+	DefaultRevisionHistoryLimit int32 = 10
+	// DefaultTLSMountPath the directory of the component certificate.
+	DefaultTLSMountPath = "/etc/tls"
+	// DefaultTLSDuration the validity of the component certificate.
+	DefaultTLSDuration = 2160 * time.Hour
+	// DefaultTLSRenewBefore the component certificate is renewed when it expires within the duration.
+	DefaultTLSRenewBefore = 720 * time.Hour
+	// DefaultIssuerKind the kind of the cert-manager issuer.
+	DefaultIssuerKind = "Issuer"
+	// DefaultIssuerGroup the api group of the cert-manager issuer.
+	DefaultIssuerGroup = "cert-manager.io"
+	// DefaultReloadPath the path of the reload endpoint.
+	DefaultReloadPath = "/reload"
+	// DefaultConfRevisionHistoryLimit the number of the previous configMaps kept for the rollback.
+	DefaultConfRevisionHistoryLimit int32 = 3
+	// DefaultUpgradeGateDelay the least duration of the upgrade step of the component.
+	DefaultUpgradeGateDelay = 10 * time.Second
+	// DefaultUpgradeGateTimeout the upgrade is failed when the component is not ready in the duration.
+	DefaultUpgradeGateTimeout = 10 * time.Minute
+	// DefaultAuthRole the role of the basic auth.
+	DefaultAuthRole = "root"
+	// DefaultAuthUsername the username of the basic auth.
+	DefaultAuthUsername = "root"
+)
+
+// SetDefaultsMiddlewareCluster set the defaults which the operator used to build the resource,
+// so that the stored object shows the effective spec.
+func SetDefaultsMiddlewareCluster(cluster *MiddlewareCluster) {
+	spec := &cluster.Spec
+	for _, component := range spec.Components {
+		if component != nil {
+			SetDefaultsCategoryClusterComponent(component)
+		}
+	}
+	for _, svc := range spec.Service {
+		if svc != nil {
+			svc.GetKind()
+			svc.GetCategory()
+		}
+	}
+	for _, is := range spec.Ingress {
+		if is != nil {
+			is.GetKind()
+			is.GetCategory()
+		}
+	}
+	for _, job := range spec.MixJob {
+		if job != nil {
+			job.GetKind()
+			job.GetCategory()
+		}
+	}
+	if spec.TLS != nil {
+		if len(spec.TLS.MountPath) == 0 {
+			spec.TLS.MountPath = DefaultTLSMountPath
+		}
+		if spec.TLS.Duration == nil {
+			spec.TLS.Duration = &metav1.Duration{Duration: DefaultTLSDuration}
+		}
+		if spec.TLS.RenewBefore == nil {
+			spec.TLS.RenewBefore = &metav1.Duration{Duration: DefaultTLSRenewBefore}
+		}
+	}
+	if spec.Upgrade != nil {
+		if spec.Upgrade.HealthGate == nil {
+			spec.Upgrade.HealthGate = &UpgradeHealthGate{}
+		}
+		if spec.Upgrade.HealthGate.Delay == nil {
+			spec.Upgrade.HealthGate.Delay = &metav1.Duration{Duration: DefaultUpgradeGateDelay}
+		}
+		if spec.Upgrade.HealthGate.Timeout == nil {
+			spec.Upgrade.HealthGate.Timeout = &metav1.Duration{Duration: DefaultUpgradeGateTimeout}
+		}
+	}
+}
+
+// SetDefaultsCategoryClusterComponent set the defaults of the component.
+func SetDefaultsCategoryClusterComponent(component *CategoryClusterComponent) {
+	component.GetKind()
+	// the category is synthesized by the kind, so it must be set after the kind.
+	component.GetCategory()
+	switch component.GetKind() {
+	case DeploymentComponentKind, DaemonSetComponentKind:
+		// the workload controller rolls the pods, the ordered pod management is meaningless.
+		if len(component.UpdateStrategy.Type) == 0 {
+			component.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+		}
+	default:
+		if len(component.UpdateStrategy.Type) == 0 {
+			// OnDelete keeps the legacy behavior, the pods are restarted by the operator.
+			component.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+		}
+		if len(component.PodManagementPolicy) == 0 {
+			component.PodManagementPolicy = appsv1.ParallelPodManagement
+		}
+	}
+	if component.RevisionHistoryLimit == nil {
+		limit := DefaultRevisionHistoryLimit
+		component.RevisionHistoryLimit = &limit
+	}
+	if component.Monitoring != nil && len(component.Monitoring.Kind) == 0 {
+		component.Monitoring.Kind = DefaultMonitorKind
+	}
+	if reload := component.Reload; reload != nil {
+		if len(reload.Policy) == 0 {
+			reload.Policy = ReloadRestart
+		}
+		if reload.HTTP != nil && len(reload.HTTP.Path) == 0 {
+			reload.HTTP.Path = DefaultReloadPath
+		}
+		if reload.HTTP != nil && len(reload.HTTP.Scheme) == 0 {
+			reload.HTTP.Scheme = corev1.URISchemeHTTP
+		}
+	}
+	if conf := component.ImmutableConf; conf != nil && conf.RevisionHistoryLimit == nil {
+		limit := DefaultConfRevisionHistoryLimit
+		conf.RevisionHistoryLimit = &limit
+	}
+	if cert := component.Certificate; cert != nil {
+		if len(cert.IssuerRef.Kind) == 0 {
+			cert.IssuerRef.Kind = DefaultIssuerKind
+		}
+		if len(cert.IssuerRef.Group) == 0 {
+			cert.IssuerRef.Group = DefaultIssuerGroup
+		}
+		if len(cert.MountPath) == 0 {
+			cert.MountPath = DefaultTLSMountPath
+		}
+		if cert.Duration == nil {
+			cert.Duration = &metav1.Duration{Duration: DefaultTLSDuration}
+		}
+		if cert.RenewBefore == nil {
+			cert.RenewBefore = &metav1.Duration{Duration: DefaultTLSRenewBefore}
+		}
+	}
+	if component.Auth != nil {
+		if len(component.Auth.Role) == 0 {
+			component.Auth.Role = DefaultAuthRole
+		}
+		if len(component.Auth.Username) == 0 {
+			component.Auth.Username = DefaultAuthUsername
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// The templated properties are rendered with the topology of the cluster, e.g. the server list of the ZooKeeper:
+//
+//	{{- range $i, $host := (index .Components "zookeeper").Hosts }}
+//	server.{{ add $i 1 }}={{ $host }}:2888:3888
+//	{{- end }}
+type (
+	// ConfTopology the variables of the templated properties.
+	ConfTopology struct {
+		// Name the name of the cluster.
+		Name string
+		// Namespace the namespace of the cluster.
+		Namespace string
+		// ClusterDomain the domain of the kubernetes cluster, it may be empty.
+		ClusterDomain string
+		// Version the version of the cluster.
+		Version string
+		// Components the topology of the components by the category.
+		Components map[string]ComponentTopology
+		// Services the topology of the services by the category.
+		Services map[string]ServiceTopology
+	}
+
+	// ComponentTopology the topology of the component.
+	ComponentTopology struct {
+		// Name the name of the workload.
+		Name string
+		// Replicas the desired replicas of the component.
+		Replicas int32
+		// ServiceName the name of the peer service.
+		ServiceName string
+		// Pods the ordered pod names of the StatefulSet.
+		Pods []string
+		// Hosts the ordered pod dns names of the StatefulSet behind the peer service.
+		Hosts []string
+	}
+
+	// ServiceTopology the topology of the service.
+	ServiceTopology struct {
+		// Name the name of the service.
+		Name string
+		// Host the dns name of the service.
+		Host string
+		// Ports the ports of the service by the port name.
+		Ports map[string]int32
+	}
+)
+
+// confTemplateFuncs the functions of the templated properties.
+var confTemplateFuncs = template.FuncMap{
+	"add":  func(a, b int) int { return a + b },
+	"join": func(sep string, elems []string) string { return strings.Join(elems, sep) },
+}
+
+// ParseConfTemplate parse the templated properties, the missing variable is an error when it is executed.
+func ParseConfTemplate(properties *NamedProperties) (*template.Template, error) {
+	return template.New(properties.Name).Funcs(confTemplateFuncs).Option("missingkey=error").Parse(properties.Data)
+}
+
+// CompileConfSchema compile the JSON Schema of the properties, the external references are not loaded.
+func CompileConfSchema(properties *NamedProperties) (*jsonschema.Schema, error) {
+	url := fmt.Sprintf("file:///%s.schema.json", properties.Name)
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, errors.New("the external schema is not supported")
+	}
+	if err := compiler.AddResource(url, strings.NewReader(properties.Schema)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
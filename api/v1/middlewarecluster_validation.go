@@ -0,0 +1,554 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net"
+	"sort"
+	"strings"
+)
+
+// the labels injected into the pod template by the operator.
+const (
+	categoryLabel = "app.kubernetes.io/category"
+	instanceLabel = "app.kubernetes.io/instance"
+)
+
+// ValidateMiddlewareCluster validate the cluster without the api server, it can be used to check the manifests in CI.
+func ValidateMiddlewareCluster(cluster *MiddlewareCluster) field.ErrorList {
+	var errs field.ErrorList
+	cluster = cluster.DeepCopy()
+	SetDefaultsMiddlewareCluster(cluster)
+	spec := &cluster.Spec
+	specPath := field.NewPath("spec")
+
+	if len(spec.Components) == 0 {
+		errs = append(errs, field.Required(specPath.Child("components"), "at least one component is required"))
+	}
+
+	// the category is the identity of the resource, it must be unique in the cluster.
+	categories := map[Category]*field.Path{}
+	services := map[Category]bool{}
+	// the objects are named by the resolved name, the inventory of the status is keyed by it too.
+	names := map[string]*field.Path{}
+	checkCategory := func(path *field.Path, component *CommonCategoryComponent) {
+		category := component.GetCategory()
+		if first, ok := categories[category]; ok {
+			errs = append(errs, field.Duplicate(path.Child("category"), fmt.Sprintf("%s, first defined in %s", category, first)))
+			return
+		}
+		categories[category] = path
+		name := resolvedName(cluster.GetName(), component)
+		if first, ok := names[name]; ok {
+			errs = append(errs, field.Duplicate(path.Child("name"), fmt.Sprintf("the object name %s, first used by %s", name, first)))
+			return
+		}
+		names[name] = path
+	}
+	for i, svc := range spec.Service {
+		if svc == nil {
+			continue
+		}
+		checkCategory(specPath.Child("service").Index(i), &svc.CommonCategoryComponent)
+		services[svc.GetCategory()] = true
+	}
+	for i, is := range spec.Ingress {
+		if is != nil {
+			checkCategory(specPath.Child("ingress").Index(i), &is.CommonCategoryComponent)
+		}
+	}
+	for i, job := range spec.MixJob {
+		if job != nil {
+			checkCategory(specPath.Child("mixJob").Index(i), &job.CommonCategoryComponent)
+		}
+	}
+
+	errs = append(errs, validateProperties(specPath.Child("conf"), spec.Conf)...)
+	for i, component := range spec.Components {
+		if component == nil {
+			continue
+		}
+		path := specPath.Child("components").Index(i)
+		checkCategory(path, &component.CommonCategoryComponent)
+		errs = append(errs, validateComponent(cluster.GetName(), path, component, services)...)
+		errs = append(errs, validateConfMounts(path.Child("properties"), spec.Conf, component)...)
+		errs = append(errs, validateReload(path, spec.Conf, component)...)
+	}
+	if tls := spec.TLS; tls != nil {
+		errs = append(errs, validateCertificate(specPath.Child("tls"), tls.MountPath, tls.Duration, tls.RenewBefore)...)
+	}
+	if plan := spec.Upgrade; plan != nil {
+		errs = append(errs, validateUpgradePlan(specPath.Child("upgrade"), spec, plan)...)
+	}
+	return errs
+}
+
+// resolvedName the name of the object of the component, it is prefixed by the cluster name as the reconcile formats it.
+func resolvedName(cluster string, component *CommonCategoryComponent) string {
+	name := string(component.GetName())
+	if len(name) == 0 {
+		name = string(component.GetCategory())
+	}
+	if !strings.HasPrefix(name, cluster) {
+		name = strings.ToLower(fmt.Sprintf("%s-%s", cluster, name))
+	}
+	return name
+}
+
+// validateUpgradePlan the paths are the valid glob patterns, and the order refers to the components once.
+func validateUpgradePlan(path *field.Path, spec *MiddlewareClusterSpec, plan *UpgradePlan) field.ErrorList {
+	var errs field.ErrorList
+	for i, p := range plan.Paths {
+		if _, err := matchVersion(p.From, ""); err != nil {
+			errs = append(errs, field.Invalid(path.Child("paths").Index(i).Child("from"), p.From, err.Error()))
+		}
+		if len(p.To) == 0 {
+			errs = append(errs, field.Required(path.Child("paths").Index(i).Child("to"), "at least one target version is required"))
+		}
+		for j, to := range p.To {
+			if _, err := matchVersion(to, ""); err != nil {
+				errs = append(errs, field.Invalid(path.Child("paths").Index(i).Child("to").Index(j), to, err.Error()))
+			}
+		}
+	}
+	ordered := map[Category]bool{}
+	for i, category := range plan.Order {
+		if c, ok := spec.GetCategoryResource(category).(*CategoryClusterComponent); !ok || c == nil {
+			errs = append(errs, field.NotFound(path.Child("order").Index(i), category))
+		} else if ordered[category] {
+			errs = append(errs, field.Duplicate(path.Child("order").Index(i), category))
+		}
+		ordered[category] = true
+	}
+	if gate := plan.HealthGate; gate != nil {
+		if gate.Delay.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child("healthGate", "delay"), gate.Delay.String(), "must not be negative"))
+		}
+		if gate.Timeout.Duration <= gate.Delay.Duration {
+			errs = append(errs, field.Invalid(path.Child("healthGate", "timeout"), gate.Timeout.String(), "must be greater than the delay"))
+		}
+	}
+	return errs
+}
+
+// validateCertificate the certificate is renewed within its validity, and mounted by the absolute path.
+func validateCertificate(path *field.Path, mountPath string, duration, renewBefore *metav1.Duration) field.ErrorList {
+	var errs field.ErrorList
+	if duration.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("duration"), duration.String(), "must be positive"))
+	}
+	if renewBefore.Duration <= 0 || renewBefore.Duration >= duration.Duration {
+		errs = append(errs, field.Invalid(path.Child("renewBefore"), renewBefore.String(), "must be positive and less than the duration"))
+	}
+	if !strings.HasPrefix(mountPath, "/") {
+		errs = append(errs, field.Invalid(path.Child("mountPath"), mountPath, "must be an absolute path"))
+	}
+	return errs
+}
+
+// ValidateMiddlewareClusterUpdate validate the cluster and the changes which can not be applied.
+func ValidateMiddlewareClusterUpdate(cluster, old *MiddlewareCluster) field.ErrorList {
+	errs := ValidateMiddlewareCluster(cluster)
+	// the old object may be stored before the defaulting webhook.
+	old = old.DeepCopy()
+	SetDefaultsMiddlewareCluster(old)
+	observed := map[ComponentName]*CategoryClusterComponent{}
+	for _, component := range old.Spec.Components {
+		if component != nil {
+			observed[component.GetName()] = component
+		}
+	}
+	cluster = cluster.DeepCopy()
+	SetDefaultsMiddlewareCluster(cluster)
+	for i, component := range cluster.Spec.Components {
+		if component == nil || observed[component.GetName()] == nil {
+			continue
+		}
+		path := field.NewPath("spec", "components").Index(i)
+		errs = append(errs, validateComponentUpdate(path, component, observed[component.GetName()])...)
+	}
+	// the version jump which is not declared by the plan is refused.
+	if plan := cluster.Spec.Upgrade; plan != nil && len(old.Spec.Version) > 0 && old.Spec.Version != cluster.Spec.Version &&
+		!plan.IsAllowed(old.Spec.Version, cluster.Spec.Version) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "version"),
+			fmt.Sprintf("the upgrade from %s to %s is not supported by the upgrade paths", old.Spec.Version, cluster.Spec.Version)))
+	}
+	return errs
+}
+
+func validateComponent(cluster string, path *field.Path, component *CategoryClusterComponent, services map[Category]bool) field.ErrorList {
+	var errs field.ErrorList
+	if len(component.GetName()) == 0 {
+		errs = append(errs, field.Required(path.Child("name"), "component name is required"))
+	}
+
+	// the selector must match the labels of the pod template.
+	if component.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(component.Selector)
+		if err != nil {
+			errs = append(errs, field.Invalid(path.Child("selector"), component.Selector, err.Error()))
+		} else {
+			podLabels := labels.Merge(component.Labels, component.Template.Labels)
+			podLabels = labels.Merge(podLabels, map[string]string{
+				instanceLabel: cluster,
+				categoryLabel: string(component.GetCategory()),
+			})
+			if selector.Empty() || !selector.Matches(labels.Set(podLabels)) {
+				errs = append(errs, field.Invalid(path.Child("selector"), component.Selector,
+					"selector does not match the template labels"))
+			}
+		}
+	}
+
+	if len(component.ServiceName) > 0 && !services[Category(component.ServiceName)] {
+		errs = append(errs, field.NotFound(path.Child("serviceName"), component.ServiceName))
+	}
+
+	if component.Replicas != nil && *component.Replicas < 0 {
+		errs = append(errs, field.Invalid(path.Child("replicas"), *component.Replicas, "must be greater than or equal to 0"))
+	}
+	if component.Replicas != nil && component.MaxReplicas != nil && *component.MaxReplicas < *component.Replicas {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), *component.MaxReplicas, "must be greater than or equal to replicas"))
+	}
+
+	if pvc := component.PersistentVolumeClaim; pvc != nil {
+		request, limit := pvc.Resources.Requests.Storage(), pvc.Resources.Limits.Storage()
+		if pvc.Resources.Requests == nil || request.IsZero() {
+			errs = append(errs, field.Required(path.Child("persistentVolumeClaim", "resources", "requests", "storage"), "request storage must be setting"))
+		} else if pvc.Resources.Limits != nil && !limit.IsZero() && request.Cmp(*limit) == 1 {
+			errs = append(errs, field.Invalid(path.Child("persistentVolumeClaim", "resources", "requests", "storage"), request.String(),
+				"request storage is more than the limit"))
+		}
+	}
+
+	errs = append(errs, validateWorkload(path, component)...)
+	if component.NetworkPolicy != nil {
+		errs = append(errs, validateNetworkPolicy(path.Child("networkPolicy"), component.NetworkPolicy)...)
+	}
+	if component.Monitoring != nil {
+		errs = append(errs, validateMonitoring(path.Child("monitoring"), component.Monitoring)...)
+	}
+	if component.Auth != nil {
+		errs = append(errs, validateAuthSource(path.Child("auth"), component.Auth.AuthSource)...)
+	}
+	if cert := component.Certificate; cert != nil {
+		certPath := path.Child("certificate")
+		if len(cert.IssuerRef.Name) == 0 {
+			errs = append(errs, field.Required(certPath.Child("issuerRef", "name"), "issuer name is required"))
+		}
+		errs = append(errs, validateCertificate(certPath, cert.MountPath, cert.Duration, cert.RenewBefore)...)
+	}
+	if conf := component.ImmutableConf; conf != nil && conf.RevisionHistoryLimit != nil && *conf.RevisionHistoryLimit < 0 {
+		errs = append(errs, field.Invalid(path.Child("immutableConf", "revisionHistoryLimit"), *conf.RevisionHistoryLimit, "must be greater than or equal to 0"))
+	}
+	users := map[string]bool{}
+	for i, user := range component.Users {
+		userPath := path.Child("users").Index(i)
+		if len(user.Name) == 0 {
+			errs = append(errs, field.Required(userPath.Child("name"), "user name is required"))
+		} else if users[user.Name] {
+			errs = append(errs, field.Duplicate(userPath.Child("name"), user.Name))
+		}
+		users[user.Name] = true
+		errs = append(errs, validateAuthSource(userPath, user.AuthSource)...)
+	}
+	errs = append(errs, validateProperties(path.Child("properties"), component.Properties)...)
+	return errs
+}
+
+// validateWorkload the stateless and per-node component have no volume claim and are updated by their own strategy.
+func validateWorkload(path *field.Path, component *CategoryClusterComponent) field.ErrorList {
+	var supported []string
+	switch component.GetKind() {
+	case DeploymentComponentKind:
+		supported = []string{string(appsv1.RollingUpdateDeploymentStrategyType), string(appsv1.RecreateDeploymentStrategyType)}
+	case DaemonSetComponentKind:
+		// the operator tracks the rollout of the daemonSet, OnDelete would never finish it.
+		supported = []string{string(appsv1.RollingUpdateDaemonSetStrategyType)}
+	default:
+		return nil
+	}
+
+	var errs field.ErrorList
+	kind := component.GetKind()
+	if component.PersistentVolumeClaim != nil {
+		errs = append(errs, field.Forbidden(path.Child("persistentVolumeClaim"), fmt.Sprintf("pvc is not supported by the %s kind", kind)))
+	}
+	// the DaemonSet runs one pod per node, it can not be scaled by the HPA.
+	if kind == DaemonSetComponentKind && component.MaxReplicas != nil {
+		errs = append(errs, field.Forbidden(path.Child("maxReplicas"), fmt.Sprintf("hpa is not supported by the %s kind", kind)))
+	}
+	valid := false
+	for _, t := range supported {
+		valid = valid || string(component.UpdateStrategy.Type) == t
+	}
+	if !valid {
+		errs = append(errs, field.NotSupported(path.Child("updateStrategy", "type"), component.UpdateStrategy.Type, supported))
+	}
+	return errs
+}
+
+func validateNetworkPolicy(path *field.Path, policy *ComponentNetworkPolicy) field.ErrorList {
+	var errs field.ErrorList
+	for i, peer := range policy.Clients {
+		peerPath := path.Child("clients").Index(i)
+		if peer.PodSelector == nil && peer.NamespaceSelector == nil && peer.IPBlock == nil {
+			errs = append(errs, field.Required(peerPath, "one of podSelector, namespaceSelector and ipBlock is required"))
+		}
+		if peer.IPBlock == nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(peer.IPBlock.CIDR); err != nil {
+			errs = append(errs, field.Invalid(peerPath.Child("ipBlock", "cidr"), peer.IPBlock.CIDR, err.Error()))
+		}
+	}
+	return errs
+}
+
+func validateAuthSource(path *field.Path, auth AuthSource) field.ErrorList {
+	var errs field.ErrorList
+	if ref := auth.PasswordSecretRef; ref != nil {
+		if len(auth.ExistingSecret) > 0 {
+			errs = append(errs, field.Forbidden(path.Child("passwordSecretRef"), "passwordSecretRef and existingSecret are mutually exclusive"))
+		}
+		if len(ref.Name) == 0 {
+			errs = append(errs, field.Required(path.Child("passwordSecretRef", "name"), "secret name is required"))
+		}
+		if len(ref.Key) == 0 {
+			errs = append(errs, field.Required(path.Child("passwordSecretRef", "key"), "secret key is required"))
+		}
+	}
+	return errs
+}
+
+func validateMonitoring(path *field.Path, monitoring *ComponentMonitoring) field.ErrorList {
+	var errs field.ErrorList
+	if len(monitoring.Endpoints) == 0 {
+		errs = append(errs, field.Required(path.Child("endpoints"), "at least one endpoint is required"))
+	}
+	for i, endpoint := range monitoring.Endpoints {
+		if len(endpoint.Port) == 0 {
+			errs = append(errs, field.Required(path.Child("endpoints").Index(i).Child("port"), "the port name is required"))
+		}
+	}
+
+	groups := map[string]bool{}
+	for i, group := range monitoring.RuleGroups {
+		groupPath := path.Child("ruleGroups").Index(i)
+		if len(group.Name) == 0 {
+			errs = append(errs, field.Required(groupPath.Child("name"), "rule group name is required"))
+		} else if groups[group.Name] {
+			errs = append(errs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		groups[group.Name] = true
+		for j, rule := range group.Rules {
+			if len(rule.Alert) == 0 {
+				errs = append(errs, field.Required(groupPath.Child("rules").Index(j).Child("alert"), "alert name is required"))
+			}
+			if len(rule.Expr) == 0 {
+				errs = append(errs, field.Required(groupPath.Child("rules").Index(j).Child("expr"), "alert expression is required"))
+			}
+		}
+	}
+	return errs
+}
+
+func validateComponentUpdate(path *field.Path, desired, observed *CategoryClusterComponent) field.ErrorList {
+	var errs field.ErrorList
+	if desired.GetCategory() != observed.GetCategory() {
+		errs = append(errs, field.Forbidden(path.Child("category"),
+			fmt.Sprintf("category is immutable, the current value is %s", observed.GetCategory())))
+	}
+
+	pvcPath := path.Child("persistentVolumeClaim")
+	if observed.PersistentVolumeClaim == nil {
+		return errs
+	}
+	if desired.PersistentVolumeClaim == nil {
+		return append(errs, field.Forbidden(pvcPath, "pvc not support down scale"))
+	}
+	if !equalStorageClass(desired.PersistentVolumeClaim.StorageClassName, observed.PersistentVolumeClaim.StorageClassName) {
+		errs = append(errs, field.Forbidden(pvcPath.Child("storageClassName"), "storage class is immutable"))
+	}
+	errs = append(errs, validateStorageResize(pvcPath.Child("resources"), desired.PersistentVolumeClaim, observed.PersistentVolumeClaim)...)
+	return errs
+}
+
+// validateStorageResize the same rules with the pvc vector scale: only up scale and not more than the current limit.
+func validateStorageResize(path *field.Path, desired, observed *corev1.PersistentVolumeClaimSpec) field.ErrorList {
+	if observed.Resources.Requests == nil || observed.Resources.Requests.Storage().IsZero() {
+		return nil
+	}
+	if desired.Resources.Requests == nil || desired.Resources.Requests.Storage().IsZero() {
+		return field.ErrorList{field.Required(path.Child("requests", "storage"), "request storage must be setting")}
+	}
+
+	request := desired.Resources.Requests.Storage()
+	if request.Cmp(*observed.Resources.Requests.Storage()) == -1 {
+		return field.ErrorList{field.Forbidden(path.Child("requests", "storage"),
+			fmt.Sprintf("pvc not support down scale, the current request is %s", observed.Resources.Requests.Storage()))}
+	}
+	if observed.Resources.Limits != nil && !observed.Resources.Limits.Storage().IsZero() &&
+		request.Cmp(*observed.Resources.Limits.Storage()) == 1 {
+		return field.ErrorList{field.Forbidden(path.Child("requests", "storage"),
+			fmt.Sprintf("pvc up scale request more than current limit %s", observed.Resources.Limits.Storage()))}
+	}
+	return nil
+}
+
+func validateProperties(path *field.Path, properties []*NamedProperties) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	// the name of the file is the key of the configMap.
+	files := map[string]string{}
+	for i, p := range properties {
+		if p == nil {
+			continue
+		}
+		if len(p.Name) == 0 {
+			errs = append(errs, field.Required(path.Index(i).Child("name"), "properties name is required"))
+		}
+		if names[p.PropertiesName()] {
+			errs = append(errs, field.Duplicate(path.Index(i), p.PropertiesName()))
+		} else if first, ok := files[p.Name]; ok {
+			errs = append(errs, field.Duplicate(path.Index(i).Child("name"), fmt.Sprintf("%s, first mounted by %s", p.Name, first)))
+		}
+		names[p.PropertiesName()] = true
+		files[p.Name] = p.Path
+		if !strings.HasPrefix(p.Path, "/") {
+			errs = append(errs, field.Invalid(path.Index(i).Child("path"), p.Path, "must be an absolute path"))
+		}
+		switch p.Type {
+		case "", Yaml, Json, Ini, Text:
+		default:
+			errs = append(errs, field.NotSupported(path.Index(i).Child("type"), p.Type,
+				[]string{string(Yaml), string(Json), string(Ini), string(Text)}))
+		}
+		if p.Template {
+			if _, err := ParseConfTemplate(p); err != nil {
+				errs = append(errs, field.Invalid(path.Index(i).Child("data"), p.Name, err.Error()))
+			}
+		}
+		if len(p.Schema) > 0 {
+			if p.Type == Text {
+				errs = append(errs, field.Forbidden(path.Index(i).Child("schema"), "schema is not supported by the text type"))
+			} else if _, err := CompileConfSchema(p); err != nil {
+				errs = append(errs, field.Invalid(path.Index(i).Child("schema"), p.Name, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+// validateConfMounts the name of the file is the key of the configMap, it can not be mounted by the different paths.
+// The containers which mount the file must be defined by the pod template of the component.
+func validateConfMounts(path *field.Path, conf []*NamedProperties, component *CategoryClusterComponent) field.ErrorList {
+	var errs field.ErrorList
+	paths := map[string]string{}
+	for _, p := range conf {
+		if p != nil {
+			paths[p.Name] = p.Path
+		}
+	}
+	containers := map[string]bool{}
+	for _, c := range component.Template.Spec.InitContainers {
+		containers[c.Name] = true
+	}
+	for _, c := range component.Template.Spec.Containers {
+		containers[c.Name] = true
+	}
+	for i, p := range component.Properties {
+		if p == nil {
+			continue
+		}
+		if first, ok := paths[p.Name]; ok && first != p.Path {
+			errs = append(errs, field.Duplicate(path.Index(i).Child("name"), fmt.Sprintf("%s, first mounted by %s", p.Name, first)))
+		}
+		paths[p.Name] = p.Path
+		for j, c := range p.Containers {
+			if !containers[c] {
+				errs = append(errs, field.NotFound(path.Index(i).Child("containers").Index(j), c))
+			}
+		}
+	}
+	return errs
+}
+
+// validateReload the HTTP policy of the files requires the reload endpoint of the component,
+// and the container which receives the signal must be defined by the pod template.
+func validateReload(path *field.Path, conf []*NamedProperties, component *CategoryClusterComponent) field.ErrorList {
+	var errs field.ErrorList
+	reloadPath := path.Child("reload")
+	if reload := component.Reload; reload != nil {
+		if len(reload.Container) > 0 {
+			found := false
+			for _, c := range component.Template.Spec.Containers {
+				found = found || c.Name == reload.Container
+			}
+			if !found {
+				errs = append(errs, field.NotFound(reloadPath.Child("container"), reload.Container))
+			}
+		}
+		if endpoint := reload.HTTP; endpoint != nil {
+			if endpoint.Port < 1 || endpoint.Port > 65535 {
+				errs = append(errs, field.Invalid(reloadPath.Child("http", "port"), endpoint.Port, "must be between 1 and 65535"))
+			}
+			if len(endpoint.Path) > 0 && !strings.HasPrefix(endpoint.Path, "/") {
+				errs = append(errs, field.Invalid(reloadPath.Child("http", "path"), endpoint.Path, "must be an absolute path"))
+			}
+			return errs
+		}
+	}
+
+	// the policy of the component properties takes precedence over the global conf with the same name.
+	policies := map[string]ReloadPolicy{}
+	for _, p := range conf {
+		if p != nil {
+			policies[p.PropertiesName()] = component.GetReloadPolicy(p)
+		}
+	}
+	for _, p := range component.Properties {
+		if p != nil && (len(p.ReloadPolicy) > 0 || len(policies[p.PropertiesName()]) == 0) {
+			policies[p.PropertiesName()] = component.GetReloadPolicy(p)
+		}
+	}
+	var names []string
+	for name, policy := range policies {
+		if policy == ReloadHTTP {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		errs = append(errs, field.Required(reloadPath.Child("http"),
+			fmt.Sprintf("the reload endpoint is required by the HTTP policy of %s", strings.Join(names, ","))))
+	}
+	return errs
+}
+
+func equalStorageClass(desired, observed *string) bool {
+	if desired == nil || observed == nil {
+		return desired == observed
+	}
+	return *desired == *observed
+}
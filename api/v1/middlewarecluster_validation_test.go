@@ -0,0 +1,354 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newValidationCluster() *MiddlewareCluster {
+	storageClass := "standard"
+	return &MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+		Spec: MiddlewareClusterSpec{
+			Version: "1.0",
+			Service: []*CategoryClusterService{{
+				CommonCategoryComponent: CommonCategoryComponent{Name: "headless", Category: "svc"},
+			}},
+			Components: []*CategoryClusterComponent{{
+				CommonCategoryComponent: CommonCategoryComponent{Name: "server", Category: "server"},
+				Selector:                &metav1.LabelSelector{MatchLabels: map[string]string{"app": "server"}},
+				ServiceName:             "svc",
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "server"}},
+				},
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimSpec{
+					StorageClassName: &storageClass,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+				Properties: []*NamedProperties{{Path: "/etc", Name: "server.conf"}},
+			}},
+		},
+	}
+}
+
+func assertErrors(t *testing.T, errs field.ErrorList, expected ...string) {
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, path := range expected {
+		if errs[i].Field != path {
+			t.Errorf("expected error on %s, got %v", path, errs[i])
+		}
+	}
+}
+
+func TestValidateMiddlewareCluster(t *testing.T) {
+	assertErrors(t, ValidateMiddlewareCluster(newValidationCluster()))
+
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Selector.MatchLabels["app"] = "other"
+	cluster.Spec.Components[0].ServiceName = "missing"
+	cluster.Spec.Components[0].Category = "svc"
+	cluster.Spec.Components[0].Properties = append(cluster.Spec.Components[0].Properties,
+		&NamedProperties{Path: "/etc", Name: "server.conf"},
+		&NamedProperties{Path: "/etc", Name: "peers.conf", Data: "{{ .Name", PropertiesOptions: PropertiesOptions{Template: true}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].category",
+		"spec.components[0].selector",
+		"spec.components[0].serviceName",
+		"spec.components[0].properties[1]",
+		"spec.components[0].properties[2].data")
+}
+
+func TestValidateObjectName(t *testing.T) {
+	// the service named by its category and the component named by the cluster prefix resolve to demo-server.
+	cluster := newValidationCluster()
+	cluster.Spec.Service[0].Name = ""
+	cluster.Spec.Service[0].Category = "server"
+	cluster.Spec.Components[0].Name = "demo-server"
+	cluster.Spec.Components[0].Category = "redis-server"
+	cluster.Spec.Components[0].ServiceName = ""
+	assertErrors(t, ValidateMiddlewareCluster(cluster), "spec.components[0].name")
+
+	cluster.Spec.Components[0].Name = "redis"
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+}
+
+func TestValidateMiddlewareClusterUpdate(t *testing.T) {
+	old := newValidationCluster()
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].PersistentVolumeClaim.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	assertErrors(t, ValidateMiddlewareClusterUpdate(cluster, old))
+
+	storageClass := "fast"
+	cluster.Spec.Components[0].Category = "renamed"
+	cluster.Spec.Components[0].PersistentVolumeClaim.StorageClassName = &storageClass
+	cluster.Spec.Components[0].PersistentVolumeClaim.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("5Gi")
+	assertErrors(t, ValidateMiddlewareClusterUpdate(cluster, old),
+		"spec.components[0].category",
+		"spec.components[0].persistentVolumeClaim.storageClassName",
+		"spec.components[0].persistentVolumeClaim.resources.requests.storage")
+}
+
+func TestSetDefaultsMiddlewareCluster(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Category = ""
+	cluster.Spec.Components[0].Auth = &BasicAuth{}
+	SetDefaultsMiddlewareCluster(cluster)
+
+	component := cluster.Spec.Components[0]
+	if component.Kind != DefaultComponentKind || component.Category != "StatefulSet-server" {
+		t.Errorf("unexpected kind %s or category %s", component.Kind, component.Category)
+	}
+	if component.UpdateStrategy.Type != "OnDelete" || component.PodManagementPolicy != "Parallel" {
+		t.Errorf("unexpected update strategy %s or pod management policy %s", component.UpdateStrategy.Type, component.PodManagementPolicy)
+	}
+	if component.RevisionHistoryLimit == nil || *component.RevisionHistoryLimit != DefaultRevisionHistoryLimit {
+		t.Errorf("unexpected revision history limit %v", component.RevisionHistoryLimit)
+	}
+	if component.Auth.Role != DefaultAuthRole || component.Auth.Username != DefaultAuthUsername {
+		t.Errorf("unexpected auth %v", component.Auth)
+	}
+	if cluster.Spec.Service[0].Kind != "Service" {
+		t.Errorf("unexpected service kind %s", cluster.Spec.Service[0].Kind)
+	}
+}
+
+func TestValidateDeployment(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Kind = DeploymentComponentKind
+	cluster.Spec.Components[0].PersistentVolumeClaim = nil
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster = newValidationCluster()
+	cluster.Spec.Components[0].Kind = DeploymentComponentKind
+	cluster.Spec.Components[0].UpdateStrategy.Type = "OnDelete"
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].persistentVolumeClaim",
+		"spec.components[0].updateStrategy.type")
+}
+
+func TestValidateDaemonSet(t *testing.T) {
+	maxReplicas := int32(3)
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Kind = DaemonSetComponentKind
+	cluster.Spec.Components[0].PersistentVolumeClaim = nil
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].MaxReplicas = &maxReplicas
+	cluster.Spec.Components[0].UpdateStrategy.Type = "OnDelete"
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].maxReplicas",
+		"spec.components[0].updateStrategy.type")
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].NetworkPolicy = &ComponentNetworkPolicy{
+		Clients: []networkingv1.NetworkPolicyPeer{
+			{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}}},
+			{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}},
+		},
+	}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].NetworkPolicy.Clients = append(cluster.Spec.Components[0].NetworkPolicy.Clients,
+		networkingv1.NetworkPolicyPeer{}, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0"}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].networkPolicy.clients[2]",
+		"spec.components[0].networkPolicy.clients[3].ipBlock.cidr")
+}
+
+func TestValidateMonitoring(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Monitoring = &ComponentMonitoring{
+		Endpoints:  []MonitorEndpoint{{Port: "metrics"}},
+		RuleGroups: []MonitorRuleGroup{{Name: "server", Rules: []MonitorRule{{Alert: "ServerDown", Expr: "up == 0"}}}},
+	}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Monitoring.Endpoints = append(cluster.Spec.Components[0].Monitoring.Endpoints, MonitorEndpoint{})
+	cluster.Spec.Components[0].Monitoring.RuleGroups = append(cluster.Spec.Components[0].Monitoring.RuleGroups,
+		MonitorRuleGroup{Name: "server", Rules: []MonitorRule{{Alert: "ServerSlow"}}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].monitoring.endpoints[1].port",
+		"spec.components[0].monitoring.ruleGroups[1].name",
+		"spec.components[0].monitoring.ruleGroups[1].rules[0].expr")
+}
+
+func TestValidateAuth(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Auth = &BasicAuth{AuthSource: AuthSource{
+		PasswordSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis-auth"}, Key: "password"},
+	}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Auth.ExistingSecret = "redis-credentials"
+	cluster.Spec.Components[0].Auth.PasswordSecretRef.Key = ""
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].auth.passwordSecretRef",
+		"spec.components[0].auth.passwordSecretRef.key")
+}
+
+func TestValidateUsers(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Users = []ComponentUser{{Name: "admin", Role: "admin"}, {Name: "app", Env: true}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Users = append(cluster.Spec.Components[0].Users, ComponentUser{Name: "app",
+		AuthSource: AuthSource{ExistingSecret: "app", PasswordSecretRef: &corev1.SecretKeySelector{Key: "password"}}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].users[2].name",
+		"spec.components[0].users[2].passwordSecretRef",
+		"spec.components[0].users[2].passwordSecretRef.name")
+}
+
+func TestValidateTLS(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.TLS = &ClusterTLS{}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.TLS = &ClusterTLS{MountPath: "tls", RenewBefore: &metav1.Duration{Duration: DefaultTLSDuration}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.tls.renewBefore",
+		"spec.tls.mountPath")
+}
+
+func TestValidateCertificate(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Certificate = &ComponentCertificate{IssuerRef: CertificateIssuerRef{Name: "corp", Kind: "ClusterIssuer"}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Certificate = &ComponentCertificate{Duration: &metav1.Duration{Duration: -DefaultTLSDuration}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].certificate.issuerRef.name",
+		"spec.components[0].certificate.duration",
+		"spec.components[0].certificate.renewBefore")
+}
+
+func TestValidateConfMounts(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Template.Spec.Containers = []corev1.Container{{Name: "server"}}
+	cluster.Spec.Conf = []*NamedProperties{{Path: "/etc", Name: "log.conf"}, {Path: "/opt/bin", Name: "start.sh"}}
+	cluster.Spec.Components[0].Properties = append(cluster.Spec.Components[0].Properties, &NamedProperties{
+		Path: "/opt/bin", Name: "init.sh", PropertiesOptions: PropertiesOptions{SubPath: true, Containers: []string{"server"}},
+	})
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Conf = append(cluster.Spec.Conf, &NamedProperties{Path: "bin", Name: "stop.sh"})
+	cluster.Spec.Components[0].Properties = append(cluster.Spec.Components[0].Properties,
+		&NamedProperties{Path: "/opt/conf", Name: "log.conf", PropertiesOptions: PropertiesOptions{Containers: []string{"missing"}}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.conf[2].path",
+		"spec.components[0].properties[2].name",
+		"spec.components[0].properties[2].containers[0]")
+}
+
+func TestValidateReload(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components[0].Template.Spec.Containers = []corev1.Container{{Name: "server"}}
+	cluster.Spec.Conf = []*NamedProperties{{Path: "/etc", Name: "server.conf", PropertiesOptions: PropertiesOptions{ReloadPolicy: ReloadHTTP}}}
+	cluster.Spec.Components[0].Properties[0].ReloadPolicy = ReloadSignal
+	cluster.Spec.Components[0].Reload = &ComponentReload{Policy: ReloadNone, Container: "server"}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Properties[0].ReloadPolicy = ""
+	cluster.Spec.Components[0].Reload.Container = "missing"
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].reload.container",
+		"spec.components[0].reload.http")
+
+	// the file mounted by the sub path is rolled, so no endpoint is required.
+	cluster.Spec.Components[0].Reload.Container = "server"
+	cluster.Spec.Conf[0].SubPath = true
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Components[0].Reload = &ComponentReload{Policy: ReloadHTTP, HTTP: &ReloadEndpoint{Port: 8080, Path: "reload"}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].reload.http.path")
+}
+
+func TestValidateConfSchema(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Conf = []*NamedProperties{
+		{Path: "/etc", Name: "app.yaml", Type: Yaml, PropertiesOptions: PropertiesOptions{Schema: `{"type": "object"}`}},
+	}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Conf = append(cluster.Spec.Conf,
+		&NamedProperties{Path: "/etc", Name: "app.json", Type: Json, PropertiesOptions: PropertiesOptions{Schema: `{"$ref": "file:///etc/passwd"}`}},
+		&NamedProperties{Path: "/etc", Name: "app.txt", Type: Text, PropertiesOptions: PropertiesOptions{Schema: `{"type": "object"}`}})
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.conf[1].schema",
+		"spec.conf[2].schema")
+}
+
+func TestValidateImmutableConf(t *testing.T) {
+	cluster := newValidationCluster()
+	limit := int32(0)
+	cluster.Spec.Components[0].ImmutableConf = &ImmutableConf{RevisionHistoryLimit: &limit}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	limit = -1
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.components[0].immutableConf.revisionHistoryLimit")
+}
+
+func TestValidateUpgradePlan(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Upgrade = &UpgradePlan{
+		Paths: []UpgradePath{{From: "1.*", To: []string{"1.*", "2.0"}}},
+		Order: []Category{"server"},
+	}
+	assertErrors(t, ValidateMiddlewareCluster(cluster))
+
+	cluster.Spec.Upgrade.Paths = append(cluster.Spec.Upgrade.Paths, UpgradePath{From: "[", To: nil})
+	cluster.Spec.Upgrade.Order = append(cluster.Spec.Upgrade.Order, "missing", "server")
+	cluster.Spec.Upgrade.HealthGate = &UpgradeHealthGate{Timeout: &metav1.Duration{Duration: time.Second}}
+	assertErrors(t, ValidateMiddlewareCluster(cluster),
+		"spec.upgrade.paths[1].from",
+		"spec.upgrade.paths[1].to",
+		"spec.upgrade.order[1]",
+		"spec.upgrade.order[2]",
+		"spec.upgrade.healthGate.timeout")
+}
+
+func TestValidateVersionUpdate(t *testing.T) {
+	old := newValidationCluster()
+	cluster := newValidationCluster()
+	cluster.Spec.Version = "3.0"
+	assertErrors(t, ValidateMiddlewareClusterUpdate(cluster, old))
+
+	cluster.Spec.Upgrade = &UpgradePlan{Paths: []UpgradePath{{From: "1.*", To: []string{"2.*"}}}}
+	assertErrors(t, ValidateMiddlewareClusterUpdate(cluster, old),
+		"spec.version")
+	cluster.Spec.Version = "2.1"
+	assertErrors(t, ValidateMiddlewareClusterUpdate(cluster, old))
+}
+
+func TestUpgradeSteps(t *testing.T) {
+	cluster := newValidationCluster()
+	cluster.Spec.Components = append(cluster.Spec.Components,
+		&CategoryClusterComponent{CommonCategoryComponent: CommonCategoryComponent{Category: "leader"}},
+		&CategoryClusterComponent{CommonCategoryComponent: CommonCategoryComponent{Category: "follower"}})
+	if steps := cluster.Spec.GetUpgradeSteps(); steps != nil {
+		t.Errorf("expected no steps without the plan, got %v", steps)
+	}
+	cluster.Spec.Upgrade = &UpgradePlan{
+		Order:       []Category{"follower", "missing", "leader"},
+		PreUpgrade:  &UpgradeHook{},
+		PostUpgrade: &UpgradeHook{},
+	}
+	expected := []string{UpgradePreHookStep, "follower", "leader", "server", UpgradePostHookStep}
+	if steps := cluster.Spec.GetUpgradeSteps(); !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected the steps %v, got %v", expected, steps)
+	}
+}
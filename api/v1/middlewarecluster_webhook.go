@@ -0,0 +1,78 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var middlewareclusterlog = logf.Log.WithName("middlewarecluster-resource")
+
+func (r *MiddlewareCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apps-devless-toplogy-com-v1-middlewarecluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.devless.toplogy.com,resources=middlewareclusters,verbs=create;update,versions=v1,name=vmiddlewarecluster.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &MiddlewareCluster{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *MiddlewareCluster) ValidateCreate() error {
+	middlewareclusterlog.Info("validate create", "name", r.Name)
+	return r.toInvalid(ValidateMiddlewareCluster(r))
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *MiddlewareCluster) ValidateUpdate(old runtime.Object) error {
+	middlewareclusterlog.Info("validate update", "name", r.Name)
+	observed, ok := old.(*MiddlewareCluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a MiddlewareCluster but got a %T", old))
+	}
+	return r.toInvalid(ValidateMiddlewareClusterUpdate(r, observed))
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *MiddlewareCluster) ValidateDelete() error {
+	return nil
+}
+
+func (r *MiddlewareCluster) toInvalid(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("MiddlewareCluster").GroupKind(), r.Name, errs)
+}
+
+//+kubebuilder:webhook:path=/mutate-apps-devless-toplogy-com-v1-middlewarecluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=apps.devless.toplogy.com,resources=middlewareclusters,verbs=create;update,versions=v1,name=mmiddlewarecluster.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &MiddlewareCluster{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *MiddlewareCluster) Default() {
+	middlewareclusterlog.Info("default", "name", r.Name)
+	SetDefaultsMiddlewareCluster(r)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MiddlewareVersionCatalogSpec defines the versions of the middleware which the platform supports
+type MiddlewareVersionCatalogSpec struct {
+	// Versions the listed versions, the cluster running the version which is not listed is reported by its status.
+	// +optional
+	// +listType=map
+	// +listMapKey=version
+	Versions []CatalogVersion `json:"versions,omitempty"`
+}
+
+// CatalogVersion the images and the default properties of the components by the version.
+type CatalogVersion struct {
+	// Version the version of the cluster spec.
+	Version string `json:"version"`
+	// Deprecated the version is still served, the cluster running it is reported by its status.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Message why the version is deprecated, e.g. the version which it should be upgraded to.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Components the images and the default properties by the category of the component.
+	// +optional
+	// +listType=map
+	// +listMapKey=category
+	Components []CatalogComponent `json:"components,omitempty"`
+}
+
+// CatalogComponent the image and the default properties of the component category.
+type CatalogComponent struct {
+	// Category the category of the component.
+	Category Category `json:"category"`
+	// Image the image of the containers and init containers which leave their image empty.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Images the images by the container name, they take precedence over the image.
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+	// Properties the default properties of the component, the properties of the component are merged on them by the conf type.
+	// +optional
+	Properties []*NamedProperties `json:"properties,omitempty"`
+}
+
+// GetVersion the listed version, it is nil when the version is not listed.
+func (this *MiddlewareVersionCatalog) GetVersion(version string) *CatalogVersion {
+	for i := range this.Spec.Versions {
+		if this.Spec.Versions[i].Version == version {
+			return &this.Spec.Versions[i]
+		}
+	}
+	return nil
+}
+
+// GetComponent the image and the default properties of the category, it is nil when the category is not listed.
+func (this *CatalogVersion) GetComponent(category Category) *CatalogComponent {
+	for i := range this.Components {
+		if this.Components[i].Category == category {
+			return &this.Components[i]
+		}
+	}
+	return nil
+}
+
+// GetImage the image of the container, it is empty when neither the container nor the category has the image.
+func (this *CatalogComponent) GetImage(container string) string {
+	if image, ok := this.Images[container]; ok && len(image) > 0 {
+		return image
+	}
+	return this.Image
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MiddlewareVersionCatalog is the Schema for the middlewareversioncatalogs API
+type MiddlewareVersionCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MiddlewareVersionCatalogSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MiddlewareVersionCatalogList contains a list of MiddlewareVersionCatalog
+type MiddlewareVersionCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MiddlewareVersionCatalog `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MiddlewareVersionCatalog{}, &MiddlewareVersionCatalogList{})
+}
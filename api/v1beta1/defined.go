@@ -45,6 +45,24 @@ const (
 	FailOver      Action = "FailOver"
 )
 
+const (
+	// ConditionReady all the components are ready.
+	ConditionReady = "Ready"
+	// ConditionProgressing the reconcile action is in progress.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded the reconcile action failed.
+	ConditionDegraded = "Degraded"
+	// ConditionPaused the reconcile is paused by the instance pause label.
+	ConditionPaused = "Paused"
+)
+
+const (
+	// PolicyPrune delete the resource when it is removed from the spec.
+	PolicyPrune PrunePolicy = "Prune"
+	// PolicyRetain keep the resource when it is removed from the spec.
+	PolicyRetain PrunePolicy = "Retain"
+)
+
 const (
 	Yaml ConfType = "yaml"
 	Json ConfType = "json"
@@ -78,6 +96,10 @@ type (
 	// ConfType conf file type (support: yaml,json,ini,text)
 	ConfType string
 
+	// PrunePolicy how to handle the resource which is removed from the spec (support: Prune,Retain)
+	// +kubebuilder:validation:Enum=Prune;Retain
+	PrunePolicy string
+
 	BasicAuth struct {
 		Role     string `json:"role"`
 		Username string `json:"username"`
@@ -0,0 +1,328 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &MiddlewareCluster{}
+
+// ConvertTo converts this MiddlewareCluster to the Hub version (v1).
+// The plaintext auth fields are dropped, the hub never keeps the secret material. The clusters stored before keep their
+// passwords in the managed secrets, and the plaintext of the new writes is rejected by the webhook.
+func (src *MiddlewareCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1.MiddlewareCluster)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", dstRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	delete(dst.Annotations, v1.HubFieldsAnnotation)
+	delete(dst.Annotations, v1.HubSpecAnnotation)
+	spec := &dst.Spec
+	spec.Version = src.Spec.Version
+	spec.Components = nil
+	for _, c := range src.Spec.Components {
+		if c == nil {
+			spec.Components = append(spec.Components, nil)
+			continue
+		}
+		component, err := convertComponentTo(c)
+		if err != nil {
+			return err
+		}
+		v1.GetAnnotatedHubFields(src.Annotations, v1.ComponentName(c.Name)).Restore(component)
+		spec.Components = append(spec.Components, component)
+	}
+	spec.Conf = convertPropertiesTo(src.Spec.Conf)
+	spec.Service = nil
+	for _, s := range src.Spec.Service {
+		if s == nil {
+			spec.Service = append(spec.Service, nil)
+			continue
+		}
+		spec.Service = append(spec.Service, &v1.CategoryClusterService{
+			CommonCategoryComponent: convertCommonTo(s.CommonCategoryComponent),
+			ServiceSpec:             *s.ServiceSpec.DeepCopy(),
+		})
+	}
+	spec.Ingress = nil
+	for _, i := range src.Spec.Ingress {
+		if i == nil {
+			spec.Ingress = append(spec.Ingress, nil)
+			continue
+		}
+		spec.Ingress = append(spec.Ingress, &v1.CategoryClusterIngress{
+			CommonCategoryComponent: convertCommonTo(i.CommonCategoryComponent),
+			IngressSpec:             v1.FromIngressSpecV1beta1(i.IngressSpec),
+		})
+	}
+	spec.MixJob = nil
+	for _, j := range src.Spec.MixJob {
+		if j == nil {
+			spec.MixJob = append(spec.MixJob, nil)
+			continue
+		}
+		spec.MixJob = append(spec.MixJob, &v1.CategoryClusterMixJob{
+			CommonCategoryComponent:    convertCommonTo(j.CommonCategoryComponent),
+			Schedule:                   j.Schedule,
+			StartingDeadlineSeconds:    j.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batchv1.ConcurrencyPolicy(j.ConcurrencyPolicy),
+			Suspend:                    j.Suspend,
+			JobTemplate:                *j.JobTemplate.DeepCopy(),
+			SuccessfulJobsHistoryLimit: j.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     j.FailedJobsHistoryLimit,
+		})
+	}
+	spec.PrunePolicy = nil
+	if src.Spec.PrunePolicy != nil {
+		spec.PrunePolicy = map[v1.ComponentKind]v1.PrunePolicy{}
+		for k, p := range src.Spec.PrunePolicy {
+			spec.PrunePolicy[v1.ComponentKind(k)] = v1.PrunePolicy(p)
+		}
+	}
+	// the conf is converted before, so that its options can be restored.
+	v1.GetAnnotatedHubSpec(src.Annotations).Restore(spec)
+
+	dst.Status = v1.MiddlewareClusterStatus{}
+	if err := v1.ConvertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+// The v1 only fields are kept in the annotation, so that they are not lost when the object is updated by v1beta1.
+func (dst *MiddlewareCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1.MiddlewareCluster)
+	if !ok {
+		return fmt.Errorf("unsupported conversion hub %T", srcRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	delete(dst.Annotations, v1.LegacyAuthAnnotation)
+
+	hub := map[v1.ComponentName]v1.HubFields{}
+	spec := &dst.Spec
+	spec.Version = src.Spec.Version
+	spec.Components = nil
+	for _, c := range src.Spec.Components {
+		if c == nil {
+			spec.Components = append(spec.Components, nil)
+			continue
+		}
+		component, err := convertComponentFrom(c)
+		if err != nil {
+			return err
+		}
+		if fields := v1.GetHubFields(c); !fields.IsEmpty() {
+			hub[c.Name] = fields
+		}
+		spec.Components = append(spec.Components, component)
+	}
+	spec.Conf = convertPropertiesFrom(src.Spec.Conf)
+	spec.Service = nil
+	for _, s := range src.Spec.Service {
+		if s == nil {
+			spec.Service = append(spec.Service, nil)
+			continue
+		}
+		spec.Service = append(spec.Service, &CategoryClusterService{
+			CommonCategoryComponent: convertCommonFrom(s.CommonCategoryComponent),
+			ServiceSpec:             *s.ServiceSpec.DeepCopy(),
+		})
+	}
+	spec.Ingress = nil
+	for _, i := range src.Spec.Ingress {
+		if i == nil {
+			spec.Ingress = append(spec.Ingress, nil)
+			continue
+		}
+		spec.Ingress = append(spec.Ingress, &CategoryClusterIngress{
+			CommonCategoryComponent: convertCommonFrom(i.CommonCategoryComponent),
+			IngressSpec:             v1.ToIngressSpecV1beta1(i.IngressSpec),
+		})
+	}
+	spec.MixJob = nil
+	for _, j := range src.Spec.MixJob {
+		if j == nil {
+			spec.MixJob = append(spec.MixJob, nil)
+			continue
+		}
+		spec.MixJob = append(spec.MixJob, &CategoryClusterMixJob{
+			CommonCategoryComponent:    convertCommonFrom(j.CommonCategoryComponent),
+			Schedule:                   j.Schedule,
+			StartingDeadlineSeconds:    j.StartingDeadlineSeconds,
+			ConcurrencyPolicy:          batchv1beta1.ConcurrencyPolicy(j.ConcurrencyPolicy),
+			Suspend:                    j.Suspend,
+			JobTemplate:                *j.JobTemplate.DeepCopy(),
+			SuccessfulJobsHistoryLimit: j.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     j.FailedJobsHistoryLimit,
+		})
+	}
+	spec.PrunePolicy = nil
+	if src.Spec.PrunePolicy != nil {
+		spec.PrunePolicy = map[ComponentKind]PrunePolicy{}
+		for k, p := range src.Spec.PrunePolicy {
+			spec.PrunePolicy[ComponentKind(k)] = PrunePolicy(p)
+		}
+	}
+
+	dst.Status = MiddlewareClusterStatus{}
+	if err := v1.ConvertJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	if len(hub) > 0 {
+		data, err := json.Marshal(hub)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[v1.HubFieldsAnnotation] = string(data)
+	}
+	if hubSpec := v1.GetHubSpec(src.Spec); !hubSpec.IsEmpty() {
+		data, err := json.Marshal(hubSpec)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[v1.HubSpecAnnotation] = string(data)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	return nil
+}
+
+func convertCommonTo(src CommonCategoryComponent) v1.CommonCategoryComponent {
+	return v1.CommonCategoryComponent{
+		Component:   v1.Component{Kind: v1.ComponentKind(src.Kind)},
+		Name:        v1.ComponentName(src.Name),
+		Category:    v1.Category(src.Category),
+		Labels:      copyMap(src.Labels),
+		Annotations: copyMap(src.Annotations),
+	}
+}
+
+func convertCommonFrom(src v1.CommonCategoryComponent) CommonCategoryComponent {
+	return CommonCategoryComponent{
+		Component:   Component{Kind: ComponentKind(src.Kind)},
+		Name:        ComponentName(src.Name),
+		Category:    Category(src.Category),
+		Labels:      copyMap(src.Labels),
+		Annotations: copyMap(src.Annotations),
+	}
+}
+
+func convertComponentTo(src *CategoryClusterComponent) (*v1.CategoryClusterComponent, error) {
+	src = src.DeepCopy()
+	dst := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: convertCommonTo(src.CommonCategoryComponent),
+		Properties:              convertPropertiesTo(src.Properties),
+		Replicas:                src.Replicas,
+		PodManagementPolicy:     src.PodManagementPolicy,
+		UpdateStrategy:          src.UpdateStrategy,
+		RevisionHistoryLimit:    src.RevisionHistoryLimit,
+		Selector:                src.Selector,
+		ServiceName:             src.ServiceName,
+		Template:                src.Template,
+		PersistentVolumeClaim:   src.PersistentVolumeClaim,
+		MaxUnavailable:          src.MaxUnavailable,
+		MaxReplicas:             src.MaxReplicas,
+	}
+	var err error
+	if dst.Metrics, dst.Behavior, err = v1.FromAutoscalingV2beta2(src.Metrics, src.Behavior); err != nil {
+		return nil, err
+	}
+	if src.Auth != nil {
+		dst.Auth = &v1.BasicAuth{Role: src.Auth.Role, Username: src.Auth.Username, Salt: src.Auth.Salt}
+	}
+	return dst, nil
+}
+
+func convertComponentFrom(src *v1.CategoryClusterComponent) (*CategoryClusterComponent, error) {
+	src = src.DeepCopy()
+	dst := &CategoryClusterComponent{
+		CommonCategoryComponent: convertCommonFrom(src.CommonCategoryComponent),
+		Properties:              convertPropertiesFrom(src.Properties),
+		Replicas:                src.Replicas,
+		PodManagementPolicy:     src.PodManagementPolicy,
+		UpdateStrategy:          src.UpdateStrategy,
+		RevisionHistoryLimit:    src.RevisionHistoryLimit,
+		Selector:                src.Selector,
+		ServiceName:             src.ServiceName,
+		Template:                src.Template,
+		PersistentVolumeClaim:   src.PersistentVolumeClaim,
+		MaxUnavailable:          src.MaxUnavailable,
+		MaxReplicas:             src.MaxReplicas,
+	}
+	var err error
+	if dst.Metrics, dst.Behavior, err = v1.ToAutoscalingV2beta2(src.Metrics, src.Behavior); err != nil {
+		return nil, err
+	}
+	if src.Auth != nil {
+		dst.Auth = &BasicAuth{Role: src.Auth.Role, Username: src.Auth.Username, Salt: src.Auth.Salt}
+	}
+	return dst, nil
+}
+
+func convertPropertiesTo(src []*NamedProperties) []*v1.NamedProperties {
+	if src == nil {
+		return nil
+	}
+	dst := make([]*v1.NamedProperties, len(src))
+	for i, p := range src {
+		if p != nil {
+			dst[i] = &v1.NamedProperties{Path: p.Path, Name: p.Name, Data: p.Data, Type: v1.ConfType(p.Type)}
+		}
+	}
+	return dst
+}
+
+func convertPropertiesFrom(src []*v1.NamedProperties) []*NamedProperties {
+	if src == nil {
+		return nil
+	}
+	dst := make([]*NamedProperties, len(src))
+	for i, p := range src {
+		if p != nil {
+			dst[i] = &NamedProperties{Path: p.Path, Name: p.Name, Data: p.Data, Type: ConfType(p.Type)}
+		}
+	}
+	return dst
+}
+
+func copyMap(src map[string]string) map[string]string {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,177 @@
+package v1beta1
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+	"time"
+)
+
+func newConversionCluster() *MiddlewareCluster {
+	replicas, maxReplicas := int32(3), int32(5)
+	pathType := v1beta1.PathTypePrefix
+	utilization := int32(80)
+	now := metav1.Unix(metav1.Now().Unix(), 0)
+	return &MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "demo",
+			Namespace:   "default",
+			Annotations: map[string]string{"owner": "ops"},
+		},
+		Spec: MiddlewareClusterSpec{
+			Version: "6.2",
+			Conf:    []*NamedProperties{{Path: "/etc", Name: "redis.conf", Data: "port 6379", Type: Text}},
+			Components: []*CategoryClusterComponent{{
+				CommonCategoryComponent: CommonCategoryComponent{
+					Component: Component{Kind: "StatefulSet"},
+					Name:      "redis",
+					Category:  "server",
+					Labels:    map[string]string{"app": "redis"},
+				},
+				Replicas:    &replicas,
+				MaxReplicas: &maxReplicas,
+				Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "redis"}},
+				ServiceName: "headless",
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "redis"}},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "redis", Image: "redis:6.2"}}},
+				},
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+				Metrics: []v2beta2.MetricSpec{{
+					Type: v2beta2.ResourceMetricSourceType,
+					Resource: &v2beta2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: v2beta2.MetricTarget{Type: v2beta2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				}},
+				Auth: &BasicAuth{Role: "root", Username: "admin", Password: "secret", Salt: "salt", Auth: "token"},
+			}},
+			Service: []*CategoryClusterService{{
+				CommonCategoryComponent: CommonCategoryComponent{Name: "headless", Category: "headless"},
+				ServiceSpec:             corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+			}},
+			Ingress: []*CategoryClusterIngress{{
+				CommonCategoryComponent: CommonCategoryComponent{Name: "web", Category: "web"},
+				IngressSpec: v1beta1.IngressSpec{
+					Backend: &v1beta1.IngressBackend{ServiceName: "admin", ServicePort: intstr.FromString("http")},
+					Rules: []v1beta1.IngressRule{{
+						Host: "redis.example.com",
+						IngressRuleValue: v1beta1.IngressRuleValue{HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{{
+								Path:     "/",
+								PathType: &pathType,
+								Backend:  v1beta1.IngressBackend{ServiceName: "admin", ServicePort: intstr.FromInt(8080)},
+							}},
+						}},
+					}},
+				},
+			}},
+			MixJob: []*CategoryClusterMixJob{{
+				CommonCategoryComponent: CommonCategoryComponent{Name: "backup", Category: "backup"},
+				Schedule:                "0 0 * * *",
+				ConcurrencyPolicy:       "Forbid",
+			}},
+			PrunePolicy: map[ComponentKind]PrunePolicy{"PersistentVolumeClaim": PolicyPrune},
+		},
+		Status: MiddlewareClusterStatus{
+			Guid:               "guid",
+			UpdateTimestamp:    &now,
+			ObservedGeneration: 2,
+			Phase:              Running,
+			ComponentStatus: map[ComponentName]*ComponentState{
+				"redis": {
+					Uid:             "uid",
+					Kind:            "StatefulSet",
+					Category:        "server",
+					State:           Ready,
+					UpdateTimestamp: &now,
+					ActionState: map[Action]ActionState{
+						Restart: {State: InProgress, Steps: map[string]ActionStep{"redis-0": {State: Stopping, Uid: "pod"}}},
+					},
+				},
+			},
+			Conditions: []metav1.Condition{{Type: ConditionReady, Status: metav1.ConditionTrue, Reason: "ComponentsReady", LastTransitionTime: now}},
+		},
+	}
+}
+
+func TestMiddlewareClusterRoundTrip(t *testing.T) {
+	src := newConversionCluster()
+	hub := &v1.MiddlewareCluster{}
+	if err := src.DeepCopy().ConvertTo(hub); err != nil {
+		t.Fatalf("convert to v1: %v", err)
+	}
+
+	ingress := hub.Spec.Ingress[0].IngressSpec
+	if ingress.DefaultBackend == nil || ingress.DefaultBackend.Service.Port.Name != "http" {
+		t.Errorf("unexpected default backend %v", ingress.DefaultBackend)
+	}
+	if port := ingress.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number; port != 8080 {
+		t.Errorf("unexpected rule backend port %d", port)
+	}
+	if _, ok := hub.Annotations[v1.LegacyAuthAnnotation]; ok || hub.Spec.Components[0].Auth.Role != "root" {
+		t.Errorf("expected the plaintext auth is dropped, got %v %v", hub.Annotations, hub.Spec.Components[0].Auth)
+	}
+	if *hub.Spec.Components[0].Metrics[0].Resource.Target.AverageUtilization != 80 {
+		t.Errorf("unexpected metrics %v", hub.Spec.Components[0].Metrics)
+	}
+
+	dst := &MiddlewareCluster{}
+	if err := dst.ConvertFrom(hub); err != nil {
+		t.Fatalf("convert from v1: %v", err)
+	}
+	// the hub never keeps the secret material.
+	src.Spec.Components[0].Auth.Password = ""
+	src.Spec.Components[0].Auth.Auth = ""
+	if !equality.Semantic.DeepEqual(src, dst) {
+		t.Errorf("round trip v1beta1 -> v1 -> v1beta1 changed the object:\n%s", diff.ObjectReflectDiff(src, dst))
+	}
+}
+
+func TestMiddlewareClusterHubRoundTrip(t *testing.T) {
+	hub := &v1.MiddlewareCluster{}
+	if err := newConversionCluster().ConvertTo(hub); err != nil {
+		t.Fatalf("convert to v1: %v", err)
+	}
+	hub.Spec.Components[0].NetworkPolicy = &v1.ComponentNetworkPolicy{
+		Clients: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}}}},
+	}
+	hub.Spec.Components[0].Auth.ExistingSecret = "redis-credentials"
+	hub.Spec.TLS = &v1.ClusterTLS{MountPath: "/etc/tls", Duration: &metav1.Duration{Duration: 2160 * time.Hour}}
+	hub.Spec.Catalog = "redis"
+	hub.Spec.Upgrade = &v1.UpgradePlan{Paths: []v1.UpgradePath{{From: "6.*", To: []string{"7.*"}}}, Order: []v1.Category{"sentinel"}}
+	hub.Spec.Components[0].Users = []v1.ComponentUser{{Name: "replication", Role: "replica", Env: true}}
+	hub.Spec.Conf[0].Template = true
+	hub.Spec.Components[0].Properties = []*v1.NamedProperties{{Path: "/etc", Name: "sentinel.conf", Data: "port {{ .Version }}",
+		PropertiesOptions: v1.PropertiesOptions{Template: true, ReloadPolicy: v1.ReloadSignal}}}
+	hub.Spec.Components[0].Reload = &v1.ComponentReload{Policy: v1.ReloadHTTP, HTTP: &v1.ReloadEndpoint{Port: 8080, Path: "/reload"}}
+	hub.Spec.Components[0].Certificate = &v1.ComponentCertificate{IssuerRef: v1.CertificateIssuerRef{Name: "corp", Kind: "ClusterIssuer"}}
+	hub.Spec.Components[0].Monitoring = &v1.ComponentMonitoring{
+		Kind:      v1.DefaultMonitorKind,
+		Endpoints: []v1.MonitorEndpoint{{Port: "metrics", Interval: "30s"}},
+	}
+
+	spoke := &MiddlewareCluster{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("convert from v1: %v", err)
+	}
+	dst := &v1.MiddlewareCluster{}
+	if err := spoke.ConvertTo(dst); err != nil {
+		t.Fatalf("convert to v1: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub, dst) {
+		t.Errorf("round trip v1 -> v1beta1 -> v1 changed the object:\n%s", diff.ObjectReflectDiff(hub, dst))
+	}
+}
@@ -53,6 +53,10 @@ type MiddlewareClusterSpec struct {
 	Ingress []*CategoryClusterIngress `json:"ingress,omitempty"`
 	// +optional
 	MixJob []*CategoryClusterMixJob `json:"mixJob,omitempty"`
+	// the prune policy of the resource kind which is removed from the spec.
+	// If not set, the PersistentVolumeClaim will be retained and the others will be pruned.
+	// +optional
+	PrunePolicy map[ComponentKind]PrunePolicy `json:"prunePolicy,omitempty"`
 }
 
 func (this MiddlewareClusterSpec) GetVersion() string {
@@ -75,6 +79,17 @@ func (this MiddlewareClusterSpec) GetIngress() []*CategoryClusterIngress {
 	return this.Ingress
 }
 
+// GetPrunePolicy the prune policy of the kind.
+func (this MiddlewareClusterSpec) GetPrunePolicy(kind ComponentKind) PrunePolicy {
+	if policy, ok := this.PrunePolicy[kind]; ok && len(policy) > 0 {
+		return policy
+	}
+	if kind == "PersistentVolumeClaim" {
+		return PolicyRetain
+	}
+	return PolicyPrune
+}
+
 func (this MiddlewareClusterSpec) GetCategoryResource(category Category) interface{} {
 	if this.Service != nil {
 		for _, svc := range this.Service {
@@ -120,6 +135,19 @@ type MiddlewareClusterStatus struct {
 	// For example, update time about data.
 	// +kubebuilder:validation:Required
 	UpdateTimestamp *metav1.Time `json:"updateTimestamp,omitempty" protobuf:"bytes,9,opt,name=updateTimestamp"`
+	// ObservedGeneration the generation of the spec which is reconciled successfully.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase the cluster level state.
+	// +optional
+	Phase State `json:"phase,omitempty"`
+	// Conditions the standard conditions of the cluster: Ready, Progressing, Degraded and Paused.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 func (this *MiddlewareClusterStatus) Init() *MiddlewareClusterStatus {
@@ -144,11 +172,25 @@ func (this *MiddlewareClusterStatus) Gen() *MiddlewareClusterStatus {
 		}
 	}
 	data := ToString(uidMap, "=")
-	this.Guid = fmt.Sprintf("%x", md5.Sum([]byte(data)))
-	this.UpdateTimestamp = &metav1.Time{Time: time.Now()}
+	guid := fmt.Sprintf("%x", md5.Sum([]byte(data)))
+	// only bump the update time when the component changed.
+	if guid != this.Guid || this.UpdateTimestamp == nil {
+		this.Guid = guid
+		this.UpdateTimestamp = &metav1.Time{Time: time.Now()}
+	}
 	return this
 }
 
+// GetCondition get the condition by type.
+func (this *MiddlewareClusterStatus) GetCondition(conditionType string) *metav1.Condition {
+	for i := range this.Conditions {
+		if this.Conditions[i].Type == conditionType {
+			return &this.Conditions[i]
+		}
+	}
+	return nil
+}
+
 // ActionState defines the observed state of ClusterComponent
 type ActionState struct {
 	// Message about the condition for a component.
@@ -163,18 +205,46 @@ type ActionState struct {
 	// For example, information about a health check.
 	// +optional
 	Message string `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+	// Steps the progress of the action on each target (.e.g. pod), so that the action can be resumed.
+	// +optional
+	Steps map[string]ActionStep `json:"steps,omitempty"`
 	// UpdateTime about the condition for a component.
 	// For example, update time about data.
 	// +kubebuilder:validation:Required
 	UpdateTimestamp *metav1.Time `json:"updateTimestamp,omitempty" protobuf:"bytes,9,opt,name=updateTimestamp"`
 }
 
+// ActionStep defines the progress of the action on one target
+type ActionStep struct {
+	// State of the step.
+	// +kubebuilder:validation:Required
+	State State `json:"status"`
+	// Uid the uid of the target when the step begin.
+	// +optional
+	Uid string `json:"uid,omitempty"`
+	// UpdateTime about the step.
+	// +optional
+	UpdateTimestamp *metav1.Time `json:"updateTimestamp,omitempty"`
+}
+
+// IsTimeout is the step not finish in the timeout.
+func (this ActionStep) IsTimeout(timeout time.Duration) bool {
+	return this.UpdateTimestamp != nil && time.Now().After(this.UpdateTimestamp.Add(timeout))
+}
+
 // ComponentState defines the observed state of ClusterComponent
 type ComponentState struct {
 	// Uid about the condition for a component.
 	// For example, update version about data.
 	Uid     string `json:"uid" protobuf:"bytes,2,opt,name=uid,casttype=uid"`
 	NextUid string `json:"-"`
+	// Kind the build-in resource kind of the component.
+	// It is the inventory used to prune the resource removed from the spec.
+	// +optional
+	Kind ComponentKind `json:"kind,omitempty"`
+	// Category the category of the component.
+	// +optional
+	Category Category `json:"category,omitempty"`
 	// Details for state
 	// +optional
 	Details map[string]string `json:"details,omitempty"`
@@ -230,13 +300,19 @@ func (this *ComponentState) GetActionState(act Action) ActionState {
 }
 
 // UpdateActionState generator the unique uid.
+// The steps progress is kept until the action is success.
 func (this *ComponentState) UpdateActionState(act Action, state State, message string) {
 	if this.ActionState == nil {
 		this.ActionState = map[Action]ActionState{}
 	}
+	var steps map[string]ActionStep
+	if state != Success {
+		steps = this.ActionState[act].Steps
+	}
 	this.ActionState[act] = ActionState{
 		State:           state,
 		Message:         message,
+		Steps:           steps,
 		UpdateTimestamp: &metav1.Time{Time: time.Now()},
 	}
 }
@@ -249,10 +325,45 @@ func (this *ComponentState) RecordActionState(act Action, state State, cause str
 	this.ActionState[act] = ActionState{
 		State:           state,
 		Cause:           cause,
+		Steps:           this.ActionState[act].Steps,
 		UpdateTimestamp: &metav1.Time{Time: time.Now()},
 	}
 }
 
+// GetActionStep the step progress of the action target.
+func (this *ComponentState) GetActionStep(act Action, key string) ActionStep {
+	return this.GetActionState(act).Steps[key]
+}
+
+// UpdateActionStep record the step progress of the action target.
+func (this *ComponentState) UpdateActionStep(act Action, key string, state State, uid string) {
+	if this.ActionState == nil {
+		this.ActionState = map[Action]ActionState{}
+	}
+	actionState := this.ActionState[act]
+	if actionState.Steps == nil {
+		actionState.Steps = map[string]ActionStep{}
+	}
+	actionState.Steps[key] = ActionStep{
+		State:           state,
+		Uid:             uid,
+		UpdateTimestamp: &metav1.Time{Time: time.Now()},
+	}
+	this.ActionState[act] = actionState
+}
+
+// ResetActionSteps clean the step progress, the action will be begin again.
+func (this *ComponentState) ResetActionSteps(act Action) {
+	if this.ActionState == nil {
+		return
+	}
+	actionState, ok := this.ActionState[act]
+	if ok {
+		actionState.Steps = nil
+		this.ActionState[act] = actionState
+	}
+}
+
 // Gen generator the unique uid.
 func (this *ComponentState) Gen(stateFiled map[string]string) *ComponentState {
 	if stateFiled == nil {
@@ -292,6 +403,10 @@ func ToString(properties map[string]string, separator string) string {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // MiddlewareCluster is the Schema for the middlewareclusters API
 type MiddlewareCluster struct {
@@ -439,6 +554,10 @@ type CategoryClusterComponent struct {
 	// employed to update Pods in the StatefulSet when a revision is made to
 	// Template.
 	UpdateStrategy appsv1.StatefulSetUpdateStrategy `json:"updateStrategy,omitempty" protobuf:"bytes,7,opt,name=updateStrategy"`
+	// revisionHistoryLimit is the maximum number of revisions that will
+	// be maintained in the StatefulSet's revision history, defaults to 10.
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty" protobuf:"varint,8,opt,name=revisionHistoryLimit"`
 	// selector is a label query over pods that should match the replica count.
 	// It must match the pod template's labels.
 	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
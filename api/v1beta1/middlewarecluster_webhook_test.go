@@ -0,0 +1,19 @@
+package v1beta1
+
+import (
+	"testing"
+)
+
+func TestValidateMiddlewareCluster(t *testing.T) {
+	cluster := newConversionCluster()
+	errs := ValidateMiddlewareCluster(cluster)
+	if len(errs) != 2 || errs[0].Field != "spec.components[0].auth.password" || errs[1].Field != "spec.components[0].auth.auth" {
+		t.Fatalf("expected the plaintext auth is rejected, got %v", errs)
+	}
+
+	cluster.Spec.Components[0].Auth.Password = ""
+	cluster.Spec.Components[0].Auth.Auth = ""
+	if errs = ValidateMiddlewareCluster(cluster); len(errs) != 0 {
+		t.Errorf("expected the auth without the plaintext is valid, got %v", errs)
+	}
+}
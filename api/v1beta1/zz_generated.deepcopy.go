@@ -32,6 +32,13 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActionState) DeepCopyInto(out *ActionState) {
 	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make(map[string]ActionStep, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.UpdateTimestamp != nil {
 		in, out := &in.UpdateTimestamp, &out.UpdateTimestamp
 		*out = (*in).DeepCopy()
@@ -48,6 +55,25 @@ func (in *ActionState) DeepCopy() *ActionState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionStep) DeepCopyInto(out *ActionStep) {
+	*out = *in
+	if in.UpdateTimestamp != nil {
+		in, out := &in.UpdateTimestamp, &out.UpdateTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionStep.
+func (in *ActionStep) DeepCopy() *ActionStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
 	*out = *in
@@ -84,6 +110,11 @@ func (in *CategoryClusterComponent) DeepCopyInto(out *CategoryClusterComponent)
 		**out = **in
 	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Selector != nil {
 		in, out := &in.Selector, &out.Selector
 		*out = new(v1.LabelSelector)
@@ -400,6 +431,13 @@ func (in *MiddlewareClusterSpec) DeepCopyInto(out *MiddlewareClusterSpec) {
 			}
 		}
 	}
+	if in.PrunePolicy != nil {
+		in, out := &in.PrunePolicy, &out.PrunePolicy
+		*out = make(map[ComponentKind]PrunePolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MiddlewareClusterSpec.
@@ -434,6 +472,13 @@ func (in *MiddlewareClusterStatus) DeepCopyInto(out *MiddlewareClusterStatus) {
 		in, out := &in.UpdateTimestamp, &out.UpdateTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MiddlewareClusterStatus.
@@ -20,15 +20,26 @@ import (
 	"context"
 	"github.com/go-logr/logr"
 	"github.com/kuberator/kernel"
+	"github.com/kuberator/kernel/common"
+	_ "github.com/kuberator/kernel/handler"
 	"github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
 
-	appsv1beta1 "github.com/kuberator/api/v1beta1"
+	appsv1 "github.com/kuberator/api/v1"
 )
 
 // MiddlewareClusterReconciler reconciles a MiddlewareCluster object
@@ -37,11 +48,15 @@ type MiddlewareClusterReconciler struct {
 	Scheme   *runtime.Scheme
 	Log      logr.Logger
 	Recorder record.EventRecorder
+	// Config the rest config which the commands are executed in the pods by, e.g. the signal of the conf reload.
+	Config *rest.Config
 }
 
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
@@ -52,10 +67,18 @@ type MiddlewareClusterReconciler struct {
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=extensions,resources=ingresses,verbs=create;delete;get;list;patch;update;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=create;delete;get;list;patch;update;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=create;delete;get;list;patch;update;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps.devless.toplogy.com,resources=middlewareclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps.devless.toplogy.com,resources=middlewareclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps.devless.toplogy.com,resources=middlewareclusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=apps.devless.toplogy.com,resources=middlewareversioncatalogs,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,16 +95,17 @@ func (reconciler *MiddlewareClusterReconciler) Reconcile(ctx context.Context, re
 		ReconcileClient: util.ReconcileClient{
 			Client: reconciler.Client,
 			Log:    reconciler.Log,
+			Config: reconciler.Config,
 		},
 		Context:  ctx,
 		Request:  request,
 		Recorder: reconciler.Recorder,
-		Crd: &appsv1beta1.MiddlewareCluster{
+		Crd: &appsv1.MiddlewareCluster{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: request.Namespace,
 				Name:      request.Name,
 			},
-			Status: *appsv1beta1.NewClusterComponentStatus(),
+			Status: *appsv1.NewClusterComponentStatus(),
 		},
 	}
 
@@ -97,8 +121,55 @@ func (reconciler *MiddlewareClusterReconciler) Reconcile(ctx context.Context, re
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// All the injected build-in resources are watched, and the pods are mapped to the cluster by the instance label.
+// The version catalog is mapped to the clusters which reference it.
 func (r *MiddlewareClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1beta1.MiddlewareCluster{}).
+	managed := ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.MiddlewareCluster{}, builder.WithPredicates(clusterChangedPredicate()))
+	for _, obj := range common.BuildInResources() {
+		managed = managed.Owns(obj, builder.WithPredicates(ownedChangedPredicate()))
+	}
+	return managed.
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(r.podToCluster),
+			builder.WithPredicates(podChangedPredicate())).
+		Watches(&source.Kind{Type: &appsv1.MiddlewareVersionCatalog{}},
+			handler.EnqueueRequestsFromMapFunc(r.catalogToClusters),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
+
+// mapTimeout the map funcs read the cache without the context of the event, they should not block the watch forever.
+const mapTimeout = 10 * time.Second
+
+// catalogToClusters map the version catalog to all the clusters which reference it.
+func (r *MiddlewareClusterReconciler) catalogToClusters(obj client.Object) []reconcile.Request {
+	ctx, cancel := context.WithTimeout(context.Background(), mapTimeout)
+	defer cancel()
+	var clusters appsv1.MiddlewareClusterList
+	if err := r.List(ctx, &clusters); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cluster := range clusters.Items {
+		if cluster.Spec.Catalog == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}})
+		}
+	}
+	return requests
+}
+
+// podToCluster map the pod to the cluster which it belongs to.
+func (r *MiddlewareClusterReconciler) podToCluster(obj client.Object) []reconcile.Request {
+	name := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetLabels()[common.InstanceLabel]}
+	if len(name.Name) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mapTimeout)
+	defer cancel()
+	var cluster appsv1.MiddlewareCluster
+	if err := r.Get(ctx, name, &cluster); err != nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: name}}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 wangwei.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// clusterChangedPredicate the status update of the cluster made by the operator will be ignored.
+func clusterChangedPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.LabelChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+	)
+}
+
+// ownedChangedPredicate the update of owned resource only be accepted when the desired part changed.
+// The status and the bookkeeping metadata are ignored.
+func ownedChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(desiredPart(e.ObjectOld), desiredPart(e.ObjectNew))
+		},
+	}
+}
+
+// podChangedPredicate the pod of the cluster is created, deleted or the running state changed.
+func podChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isClusterPod(e.Object)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isClusterPod(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !isClusterPod(e.ObjectNew) {
+				return false
+			}
+			old, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			pod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return old.Status.Phase != pod.Status.Phase ||
+				util.IsPodReady(*old) != util.IsPodReady(*pod) ||
+				restartCount(*old) != restartCount(*pod) ||
+				old.DeletionTimestamp.IsZero() != pod.DeletionTimestamp.IsZero()
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isClusterPod(e.Object)
+		},
+	}
+}
+
+func isClusterPod(obj client.Object) bool {
+	return obj != nil && len(obj.GetLabels()[common.InstanceLabel]) > 0 && len(obj.GetLabels()[common.CategoryLabel]) > 0
+}
+
+func restartCount(pod corev1.Pod) int32 {
+	var count int32
+	for _, c := range pod.Status.ContainerStatuses {
+		count += c.RestartCount
+	}
+	return count
+}
+
+func desiredPart(obj client.Object) map[string]interface{} {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+		delete(metadata, "managedFields")
+		delete(metadata, "generation")
+	}
+	return content
+}
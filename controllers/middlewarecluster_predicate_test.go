@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"testing"
+
+	appsv1 "github.com/kuberator/api/v1"
+)
+
+// assertUpdates the update events of the changed copies of the object, the accepted ones are expected by the name.
+func assertUpdates(t *testing.T, p predicate.Predicate, old client.Object, changes map[string]func(obj client.Object), accepted ...string) {
+	expected := map[string]bool{}
+	for _, name := range accepted {
+		expected[name] = true
+	}
+	for name, change := range changes {
+		obj := old.DeepCopyObject().(client.Object)
+		change(obj)
+		if got := p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: obj}); got != expected[name] {
+			t.Errorf("expected the %s update is accepted %t, got %t", name, expected[name], got)
+		}
+	}
+}
+
+// bookkeeping the metadata written by the api server on every update.
+func bookkeeping(obj client.Object) {
+	obj.SetResourceVersion("2")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "operator", Operation: metav1.ManagedFieldsOperationUpdate}})
+}
+
+func TestClusterChangedPredicate(t *testing.T) {
+	old := &appsv1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{
+		Name: "demo", Namespace: "ns", Generation: 1, ResourceVersion: "1",
+		Labels: map[string]string{"team": "storage"}, Annotations: map[string]string{"owner": "ops"},
+	}}
+	assertUpdates(t, clusterChangedPredicate(), old, map[string]func(obj client.Object){
+		"status": func(obj client.Object) {
+			bookkeeping(obj)
+			obj.(*appsv1.MiddlewareCluster).Status.Phase = appsv1.Running
+		},
+		"bookkeeping": bookkeeping,
+		"spec": func(obj client.Object) {
+			obj.(*appsv1.MiddlewareCluster).Spec.Version = "2.0"
+			obj.SetGeneration(2)
+		},
+		"label": func(obj client.Object) { obj.SetLabels(map[string]string{"team": "cache"}) },
+		"annotation": func(obj client.Object) {
+			obj.SetAnnotations(map[string]string{appsv1.RotateCredentialsAnnotation: "1"})
+		},
+	}, "spec", "label", "annotation")
+}
+
+func TestOwnedChangedPredicate(t *testing.T) {
+	old := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-redis", Namespace: "ns", ResourceVersion: "1", Labels: map[string]string{"team": "storage"}},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 6379}}},
+	}
+	assertUpdates(t, ownedChangedPredicate(), old, map[string]func(obj client.Object){
+		"status": func(obj client.Object) {
+			bookkeeping(obj)
+			obj.(*corev1.Service).Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		},
+		"bookkeeping": bookkeeping,
+		"generation":  func(obj client.Object) { obj.SetGeneration(2) },
+		"spec":        func(obj client.Object) { obj.(*corev1.Service).Spec.Ports[0].Port = 6380 },
+		"label":       func(obj client.Object) { obj.SetLabels(map[string]string{"team": "cache"}) },
+		"annotation":  func(obj client.Object) { obj.SetAnnotations(map[string]string{"owner": "ops"}) },
+	}, "spec", "label", "annotation")
+}
+
+func TestPodChangedPredicate(t *testing.T) {
+	old := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-redis-0", Namespace: "ns", ResourceVersion: "1",
+			Labels: map[string]string{common.InstanceLabel: "demo", common.CategoryLabel: "redis"}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{Name: "redis"}}},
+	}
+	assertUpdates(t, podChangedPredicate(), old, map[string]func(obj client.Object){
+		"bookkeeping": bookkeeping,
+		"condition": func(obj client.Object) {
+			obj.(*corev1.Pod).Status.Conditions = []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}}
+		},
+		"phase":   func(obj client.Object) { obj.(*corev1.Pod).Status.Phase = corev1.PodFailed },
+		"ready":   func(obj client.Object) { obj.(*corev1.Pod).Status.ContainerStatuses[0].Ready = true },
+		"restart": func(obj client.Object) { obj.(*corev1.Pod).Status.ContainerStatuses[0].RestartCount = 1 },
+		"deleting": func(obj client.Object) {
+			now := metav1.Now()
+			obj.SetDeletionTimestamp(&now)
+		},
+		"other": func(obj client.Object) {
+			obj.SetLabels(nil)
+			obj.(*corev1.Pod).Status.Phase = corev1.PodFailed
+		},
+	}, "phase", "ready", "restart", "deleting")
+
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns", Labels: map[string]string{"app": "web"}}}
+	if podChangedPredicate().Create(event.CreateEvent{Object: other}) || podChangedPredicate().Delete(event.DeleteEvent{Object: other}) {
+		t.Errorf("expected the pod out of the cluster is ignored")
+	}
+	if !podChangedPredicate().Create(event.CreateEvent{Object: old}) {
+		t.Errorf("expected the created pod of the cluster is accepted")
+	}
+}
+
+func newMapReconciler(objs ...client.Object) *MiddlewareClusterReconciler {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	return &MiddlewareClusterReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Scheme: scheme,
+	}
+}
+
+func TestPodToCluster(t *testing.T) {
+	r := newMapReconciler(&appsv1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"}})
+	pod := func(labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "demo-redis-0", Namespace: "ns", Labels: labels}}
+	}
+	if requests := r.podToCluster(pod(map[string]string{common.CategoryLabel: "redis"})); len(requests) != 0 {
+		t.Errorf("expected the pod without the instance label maps to nothing, got %v", requests)
+	}
+	if requests := r.podToCluster(pod(map[string]string{common.InstanceLabel: "other"})); len(requests) != 0 {
+		t.Errorf("expected the pod of the missing cluster maps to nothing, got %v", requests)
+	}
+	requests := r.podToCluster(pod(map[string]string{common.InstanceLabel: "demo"}))
+	if len(requests) != 1 || requests[0].NamespacedName != (types.NamespacedName{Namespace: "ns", Name: "demo"}) {
+		t.Errorf("expected the pod maps to its cluster, got %v", requests)
+	}
+}
+
+func TestCatalogToClusters(t *testing.T) {
+	r := newMapReconciler(
+		&appsv1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"}, Spec: appsv1.MiddlewareClusterSpec{Catalog: "redis"}},
+		&appsv1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "other"}, Spec: appsv1.MiddlewareClusterSpec{Catalog: "redis"}},
+		&appsv1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: "ns"}, Spec: appsv1.MiddlewareClusterSpec{Catalog: "kafka"}},
+	)
+	requests := r.catalogToClusters(&appsv1.MiddlewareVersionCatalog{ObjectMeta: metav1.ObjectMeta{Name: "redis"}})
+	names := map[types.NamespacedName]bool{}
+	for _, request := range requests {
+		names[request.NamespacedName] = true
+	}
+	if len(requests) != 2 || !names[types.NamespacedName{Namespace: "ns", Name: "demo"}] || !names[types.NamespacedName{Namespace: "other", Name: "cache"}] {
+		t.Errorf("expected the clusters referencing the catalog, got %v", requests)
+	}
+}
@@ -1,65 +1,393 @@
 package kernel
 
 import (
+	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	"github.com/kuberator/api/extends"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
 	"github.com/kuberator/kernel/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
 )
 
 func Apply(reconcile *ReconcileContext, cmd *core.ActionCommand) core.CommandResult {
 	var aerr error
+	var done = true
 	switch cmd.Action {
 	case v1.Create:
 		aerr = reconcile.Create(reconcile.Context, cmd.TargetResource.Target)
 	case v1.Delete:
-		aerr = reconcile.DeleteAllOf(reconcile.Context, cmd.TargetResource)
+		aerr = reconcile.DeleteObject(reconcile.Context, cmd.TargetResource.Target)
 	case v1.Update:
 		aerr = reconcile.Update(reconcile.Context, cmd.TargetResource.Target)
 	case v1.Restart:
-		aerr = restart(reconcile, cmd)
+		done, aerr = restart(reconcile, cmd, v1.Restart)
+	case v1.Rotate:
+		done, aerr = rotate(reconcile, cmd)
+	case v1.Provision:
+		aerr = provision(reconcile, cmd)
+	case v1.Issue:
+		aerr = issue(reconcile, cmd)
+	case v1.Reload:
+		done, aerr = reload(reconcile, cmd)
 	case v1.ReCreate:
-		aerr = reconcile.ReCreate(reconcile.Context, cmd.TargetResource.Target)
+		done, aerr = reconcile.ReCreate(reconcile.Context, componentState(reconcile, cmd), cmd.TargetResource.Target)
 	case v1.FailOver:
-		aerr = failOver(reconcile, cmd)
+		done, aerr = failOver(reconcile, cmd)
 	case v1.RollingUpdate:
 	case v1.Recycle:
 	case v1.Non:
 	}
 
+	// the action is in progress, requeue to advance the next step.
+	if aerr == nil && !done {
+		return core.Result().WithRequeueAfter(util.GetRequeueInterval())
+	}
 	return core.Result().Error(aerr)
 }
 
-func restart(reconcile *ReconcileContext, cmd *core.ActionCommand) error {
+// componentState the state which record the action steps progress.
+func componentState(reconcile *ReconcileContext, cmd *core.ActionCommand) *v1.ComponentState {
+	status := reconcile.Crd.GetStatus()
+	state := status.ComponentStatus[cmd.ResourceMeta.GetName()]
+	if state == nil {
+		state = v1.NewComponentState(v1.Success, "unknown state", nil)
+		status.ComponentStatus[cmd.ResourceMeta.GetName()] = state
+	}
+	return state
+}
+
+// restart the pods of the category, the progress is recorded as the steps of the action.
+func restart(reconcile *ReconcileContext, cmd *core.ActionCommand, act v1.Action) (bool, error) {
+	state := componentState(reconcile, cmd)
 	var pods []corev1.Pod
 	if cmd.TargetResource.Extends != nil {
 		pods = cmd.TargetResource.Extends.([]corev1.Pod)
 	}
 	if pods != nil && len(pods) > 0 {
-		return reconcile.Restart(reconcile.Context, false, true, pods)
+		return reconcile.Restart(reconcile.Context, state, act, false, true, pods)
 	}
 
 	var podNum int32
+	name := types.NamespacedName{Namespace: reconcile.Namespace, Name: cmd.TargetResource.Target.GetName()}
 	c := reconcile.Crd.GetSpec().GetCategoryResource(cmd.TargetResource.Category)
 	if c != nil {
 		cc, ok := c.(*v1.CategoryClusterComponent)
 		if ok {
+			// the deployment and daemonSet pods are restarted by the rollout.
+			if cc.GetKind() == common.Deployment || cc.GetKind() == common.DaemonSet {
+				return reconcile.RolloutRestart(reconcile.Context, state, act, common.NewBuildInResource(cc.GetKind(), name))
+			}
 			podNum = *cc.Replicas
 		}
 	}
 
-	name := types.NamespacedName{Namespace: reconcile.Namespace, Name: cmd.TargetResource.Target.GetName()}
-	return reconcile.Restart(reconcile.Context, false, true, util.OrderedPod(name, podNum))
+	return reconcile.Restart(reconcile.Context, state, act, false, true, util.OrderedPod(name, podNum))
+}
+
+// rotateStep the step of the rotate action which records the phase of the credentials.
+const rotateStep = "credentials"
+
+// rotate the generated passwords of the secrets referenced by the same category in three phases,
+// the phase of every secret is recorded as the step of the action so that it can be resumed.
+// 1. the new password is written, the old one is kept as the previous password, so that both are accepted.
+// 2. the pods of the referenced category are restarted once to load all the new passwords.
+// 3. the previous passwords are retired, and the requests are recorded by the secrets.
+// The restart begins again when a secret is written after it begins, and a new request is rotated after the current one is retired.
+func rotate(reconcile *ReconcileContext, cmd *core.ActionCommand) (bool, error) {
+	group, ok := cmd.TargetResource.Extends.([]*core.ActionCommand)
+	if !ok || len(group) == 0 {
+		group = []*core.ActionCommand{cmd}
+	}
+	request := reconcile.Crd.GetAnnotations()[v1.RotateCredentialsAnnotation]
+	secrets := make([]*corev1.Secret, len(group))
+	written, rotating := false, false
+	for i, c := range group {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: c.TargetResource.Target.GetNamespace(),
+			Name:      c.TargetResource.Target.GetName(),
+		}}
+		if err := reconcile.Get(reconcile.Context, secret); err != nil {
+			return false, err
+		}
+		secrets[i] = secret
+
+		state := componentState(reconcile, c)
+		step := state.GetActionStep(v1.Rotate, rotateStep)
+		rotating = rotating || step.State == v1.Restarting
+		if step.State == v1.Restarting || (step.State == v1.Success && step.Uid == request) {
+			continue
+		}
+		password, err := util.GeneratePassword()
+		if err != nil {
+			return false, err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[common.PreviousPasswordKey] = secret.Data[common.PasswordKey]
+		secret.Data[common.PasswordKey] = []byte(password)
+		if err = reconcile.Update(reconcile.Context, secret); err != nil {
+			return false, err
+		}
+		state.UpdateActionStep(v1.Rotate, rotateStep, v1.Restarting, request)
+		reconcile.Log.Info("write the new password ok", "name", secret.Name, "request", request)
+		written = true
+	}
+
+	// the restart progress is recorded by the first secret of the group.
+	leader := componentState(reconcile, group[0])
+	if written {
+		step := leader.GetActionStep(v1.Rotate, rotateStep)
+		leader.ResetActionSteps(v1.Rotate)
+		leader.UpdateActionStep(v1.Rotate, rotateStep, step.State, step.Uid)
+		return false, nil
+	}
+	if !rotating {
+		return true, nil
+	}
+
+	category := secrets[0].Labels[common.ReferenceLabel]
+	command := util.GetRestartCommand(secrets[0], category, 0, cmd.Message)
+	command.ResourceMeta = group[0].ResourceMeta
+	done, err := restart(reconcile, command, v1.Rotate)
+	if err != nil || !done {
+		return done, err
+	}
+
+	for i, c := range group {
+		state := componentState(reconcile, c)
+		step := state.GetActionStep(v1.Rotate, rotateStep)
+		if step.State != v1.Restarting {
+			continue
+		}
+		secret := secrets[i]
+		delete(secret.Data, common.PreviousPasswordKey)
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[common.RotatedCredentialsAnnotation] = step.Uid
+		if err = reconcile.Update(reconcile.Context, secret); err != nil {
+			return false, err
+		}
+		state.UpdateActionStep(v1.Rotate, rotateStep, v1.Success, step.Uid)
+		if i > 0 {
+			state.UpdateActionState(v1.Rotate, v1.Success, "")
+		}
+		reconcile.Log.Info("retire the previous password ok", "name", secret.Name, "request", step.Uid)
+	}
+	return true, nil
+}
+
+const (
+	// reloadStep the step of the reload action which records the version of the configMap to load.
+	reloadStep = "conf"
+	// reloadPolicyStep the step of the reload action which records the policy, so that it can be resumed.
+	reloadPolicyStep = "policy"
+)
+
+// reload the pods of the category referenced by the configMap load the changed conf by the policy.
+// The RollingRestart is rolled by the workload with the conf checksum, and the None is loaded by the pods themselves.
+// The Signal and HTTP wait the kubelet refreshes the configMap volume, then reload all the running pods.
+// The outcome is recorded as the message of the action.
+func reload(reconcile *ReconcileContext, cmd *core.ActionCommand) (bool, error) {
+	state := componentState(reconcile, cmd)
+	policy, _ := cmd.TargetResource.Extends.(v1.ReloadPolicy)
+	configMap := cmd.TargetResource.Target
+	category := configMap.GetLabels()[common.ReferenceLabel]
+	outcome := func(message string) {
+		cmd.Callback = func(result *core.CommandResult, cli client.Client, i ...interface{}) error {
+			if !result.IsError() && !result.NotEmpty() {
+				state.UpdateActionState(v1.Reload, v1.Success, message)
+			}
+			return nil
+		}
+	}
+
+	switch policy {
+	case v1.ReloadSignal, v1.ReloadHTTP:
+	case v1.ReloadRollingRestart:
+		outcome(fmt.Sprintf("the %s pod are rolled by the conf checksum", category))
+		return true, nil
+	default:
+		outcome(fmt.Sprintf("the %s pod load the conf by themselves", category))
+		return true, nil
+	}
+
+	version := configMap.GetResourceVersion()
+	step := state.GetActionStep(v1.Reload, reloadStep)
+	// the configMap is changed again, wait the new version.
+	if step.Uid != version || step.UpdateTimestamp == nil {
+		state.UpdateActionStep(v1.Reload, reloadStep, v1.Waiting, version)
+		state.UpdateActionStep(v1.Reload, reloadPolicyStep, v1.Waiting, string(policy))
+		return false, nil
+	}
+	if time.Since(step.UpdateTimestamp.Time) < util.GetReloadDelay() {
+		reconcile.Log.Info("waiting the conf refreshed in the pods", "name", configMap.GetName(), "policy", policy)
+		return false, nil
+	}
+
+	var component *v1.CategoryClusterComponent
+	if c, ok := reconcile.Crd.GetSpec().GetCategoryResource(v1.Category(category)).(*v1.CategoryClusterComponent); ok {
+		component = c
+	}
+	if component == nil {
+		return true, nil
+	}
+	pods, err := reconcile.ListPods(reconcile.Context, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: configMap.GetNamespace(),
+		Labels: map[string]string{
+			common.InstanceLabel: reconcile.Crd.GetName(),
+			common.CategoryLabel: category,
+		},
+	}})
+	if err != nil {
+		return false, err
+	}
+	reloaded := 0
+	for _, pod := range pods {
+		// the pod which is not running loads the conf when it starts.
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err = reconcile.Reload(reconcile.Context, pod, policy, component.Reload); err != nil {
+			return false, err
+		}
+		reloaded++
+	}
+	reconcile.Log.Info("reload the pods ok", "category", category, "policy", policy, "pods", reloaded)
+	outcome(fmt.Sprintf("reload %d %s pod by the %s policy ok", reloaded, category, policy))
+	return true, nil
 }
 
-func failOver(reconcile *ReconcileContext, cmd *core.ActionCommand) error {
+func failOver(reconcile *ReconcileContext, cmd *core.ActionCommand) (bool, error) {
 	var pods []corev1.Pod
 	if cmd.TargetResource.Extends != nil {
 		pods = cmd.TargetResource.Extends.([]corev1.Pod)
 	}
 	if pods != nil && len(pods) > 0 {
-		return reconcile.FailOver(reconcile.Context, cmd.TargetResource.Target, pods...)
+		return reconcile.FailOver(reconcile.Context, componentState(reconcile, cmd), cmd.TargetResource.Target, pods...)
+	}
+	return true, nil
+}
+
+// provision the named user inside the middleware by the extension of the component category.
+// The password digest is recorded by the secret, so that the user is provisioned again when the password is changed.
+func provision(reconcile *ReconcileContext, cmd *core.ActionCommand) error {
+	meta, ok := cmd.ResourceMeta.(*core.CategoryComponentObject)
+	if !ok {
+		return nil
+	}
+	user, ok := meta.Object.(*v1.ComponentUser)
+	_, sh := extend.GetHandler(meta.Reference.GetCategory())
+	provisioner, provisionable := sh.(extends.UserProvisioner)
+	if !ok || !provisionable {
+		return nil
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: cmd.TargetResource.Target.GetNamespace(),
+		Name:      cmd.TargetResource.Target.GetName(),
+	}}
+	if err := reconcile.Get(reconcile.Context, secret); err != nil {
+		return err
+	}
+	err := provisioner.ProvisionUser(core.ComponentArgs{
+		Context: reconcile.Context,
+		NamespacedName: types.NamespacedName{
+			Namespace: reconcile.Namespace,
+			Name:      reconcile.Name,
+		},
+		CustomResource: core.CustomResource{
+			Crd:          reconcile.Crd,
+			ResourceMeta: cmd.ResourceMeta,
+		},
+		Observed: secret,
+		Desired:  secret,
+		Logger:   reconcile.Log,
+	}, *user)
+	if err != nil {
+		return err
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[common.ProvisionedAnnotation] = util.PasswordDigest(string(secret.Data[common.PasswordKey]))
+	if err = reconcile.Update(reconcile.Context, secret); err != nil {
+		return err
+	}
+	reconcile.Log.Info("provision the user ok", "name", secret.Name, "user", user.Name)
+	return nil
+}
+
+// issue the certificate of the secret, the CA is self-signed, and the certificate of the component is signed by the CA with the dns names recorded by the secret.
+// The secret is created when it does not exist, otherwise only its certificate is replaced.
+func issue(reconcile *ReconcileContext, cmd *core.ActionCommand) error {
+	desired := cmd.TargetResource.Target.(*corev1.Secret)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: desired.Namespace,
+		Name:      desired.Name,
+	}}
+	exists := true
+	if err := reconcile.Get(reconcile.Context, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		exists = false
+		secret = desired.DeepCopy()
+	} else {
+		secret.Labels = util.Merge(secret.Labels, desired.Labels)
+		secret.Annotations = util.Merge(secret.Annotations, desired.Annotations)
+	}
+
+	var cert, key, ca []byte
+	var err error
+	meta, ok := cmd.ResourceMeta.(*core.CategoryComponentObject)
+	if !ok || meta.Reference == nil {
+		cert, key, err = util.GenerateCA(fmt.Sprintf("%s-ca", reconcile.Crd.GetName()))
+	} else {
+		caSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: desired.Namespace,
+			Name:      util.GetTLSCAName(reconcile.Crd.GetName()),
+		}}
+		// the CA is issued before the component, retry until it exists.
+		if err = reconcile.Get(reconcile.Context, caSecret); err != nil {
+			return err
+		}
+		ca = caSecret.Data[corev1.TLSCertKey]
+		duration, _ := util.GetTLSDurations(reconcile.Crd.GetSpec().TLS)
+		var dnsNames []string
+		if names := secret.Annotations[common.TLSDNSNamesAnnotation]; len(names) > 0 {
+			dnsNames = strings.Split(names, ",")
+		}
+		cert, key, err = util.IssueCertificate(ca, caSecret.Data[corev1.TLSPrivateKeyKey], secret.Name, dnsNames, duration)
+	}
+	if err != nil {
+		return err
+	}
+
+	secret.Type = corev1.SecretTypeTLS
+	secret.StringData = nil
+	secret.Data = map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key}
+	if ca != nil {
+		secret.Data[common.TLSCAKey] = ca
+	}
+	if exists {
+		err = reconcile.Update(reconcile.Context, secret)
+	} else {
+		err = reconcile.Create(reconcile.Context, secret)
+	}
+	if err != nil {
+		return err
 	}
+	reconcile.Log.Info("issue the certificate ok", "name", secret.Name)
 	return nil
 }
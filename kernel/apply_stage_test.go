@@ -0,0 +1,233 @@
+package kernel
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+)
+
+// newRotateReconcile the cluster with one redis pod, the component secret and a user secret reference the redis category.
+func newRotateReconcile(request string) *ReconcileContext {
+	replicas := int32(1)
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo",
+			Annotations: map[string]string{v1.RotateCredentialsAnnotation: request}},
+		Spec: v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{{
+			CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis", Component: v1.Component{Kind: common.StatefulSet}},
+			Replicas:                &replicas,
+		}}},
+	}
+	labels := map[string]string{common.InstanceLabel: "demo", common.ReferenceLabel: "redis"}
+	return newFakeReconcile(crd,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-redis-secret", Labels: labels},
+			Data: map[string][]byte{common.PasswordKey: []byte("redis")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-redis-user-app", Labels: labels},
+			Data: map[string][]byte{common.PasswordKey: []byte("app")}},
+		newRunningPod("a"))
+}
+
+func newRunningPod(uid string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-redis-0", UID: types.UID(uid),
+			Labels: map[string]string{common.InstanceLabel: "demo", common.CategoryLabel: "redis"}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// rotateCommand the rotate command grouped by the category, it is built again in every reconcile.
+func rotateCommand(names ...string) *core.ActionCommand {
+	var group []*core.ActionCommand
+	for _, name := range names {
+		group = append(group, &core.ActionCommand{
+			Action: v1.Rotate,
+			ResourceMeta: &core.CategoryComponentObject{CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name: v1.ComponentName(name), Category: v1.Category(name), Component: v1.Component{Kind: common.Secret},
+			}},
+			TargetResource: &core.ReferenceObject{
+				Category: "redis",
+				Target:   &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}},
+			},
+		})
+	}
+	group[0].TargetResource.Extends = group
+	return group[0]
+}
+
+func getSecret(t *testing.T, reconcile *ReconcileContext, name string) *corev1.Secret {
+	secret := &corev1.Secret{}
+	if err := reconcile.Client.Get(reconcile.Context, types.NamespacedName{Namespace: "ns", Name: name}, secret); err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+// applyRotate apply the rotate command as the exec of the pipeline does, the status is kept as it is persisted.
+func applyRotate(t *testing.T, reconcile *ReconcileContext, cmd *core.ActionCommand) core.CommandResult {
+	state := componentState(reconcile, cmd)
+	state.UpdateActionState(v1.Rotate, v1.InProgress, "")
+	result := Apply(reconcile, cmd)
+	if result.IsError() {
+		t.Fatal(result.LastError())
+	}
+	if !result.NotEmpty() {
+		state.UpdateActionState(v1.Rotate, v1.Success, "")
+	}
+	status := reconcile.Crd.GetStatus().DeepCopy()
+	reconcile.Crd.SetStatus(*status)
+	return result
+}
+
+func TestRotate(t *testing.T) {
+	reconcile := newRotateReconcile("1")
+	names := []string{"demo-redis-secret", "demo-redis-user-app"}
+
+	// 1. write the new passwords of all the secrets, the old ones are kept as the previous.
+	if result := applyRotate(t, reconcile, rotateCommand(names...)); !result.NotEmpty() {
+		t.Fatalf("expected the rotation waits the restart")
+	}
+	written := map[string]string{}
+	for i, previous := range []string{"redis", "app"} {
+		secret := getSecret(t, reconcile, names[i])
+		if string(secret.Data[common.PreviousPasswordKey]) != previous || string(secret.Data[common.PasswordKey]) == previous {
+			t.Fatalf("expected the new password is written with the previous one, got %v", secret.Data)
+		}
+		written[names[i]] = string(secret.Data[common.PasswordKey])
+	}
+
+	// 2. restart the pods once for all the secrets, the written passwords are never written again.
+	if result := applyRotate(t, reconcile, rotateCommand(names...)); !result.NotEmpty() {
+		t.Fatalf("expected the rotation waits the pod restarted")
+	}
+	if err := reconcile.Client.Get(reconcile.Context, types.NamespacedName{Namespace: "ns", Name: "demo-redis-0"}, &corev1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the pod is restarted, got %v", err)
+	}
+	for _, name := range names {
+		if string(getSecret(t, reconcile, name).Data[common.PasswordKey]) != written[name] {
+			t.Fatalf("expected the resumed rotation keeps the written password of %s", name)
+		}
+	}
+
+	// 3. retire the previous passwords when the pod is ready again.
+	if err := reconcile.Client.Create(reconcile.Context, newRunningPod("b")); err != nil {
+		t.Fatal(err)
+	}
+	if result := applyRotate(t, reconcile, rotateCommand(names...)); result.NotEmpty() {
+		t.Fatalf("expected the rotation is finished, got %v", result)
+	}
+	pod := &corev1.Pod{}
+	if err := reconcile.Client.Get(reconcile.Context, types.NamespacedName{Namespace: "ns", Name: "demo-redis-0"}, pod); err != nil || pod.UID != "b" {
+		t.Fatalf("expected the pod is restarted only once, got %v", err)
+	}
+	for _, name := range names {
+		secret := getSecret(t, reconcile, name)
+		if _, ok := secret.Data[common.PreviousPasswordKey]; ok || string(secret.Data[common.PasswordKey]) != written[name] {
+			t.Errorf("expected the previous password of %s is retired, got %v", name, secret.Data)
+		}
+		if secret.Annotations[common.RotatedCredentialsAnnotation] != "1" {
+			t.Errorf("expected the request is recorded by %s", name)
+		}
+	}
+}
+
+func TestRotateNewRequest(t *testing.T) {
+	reconcile := newRotateReconcile("1")
+	name := "demo-redis-secret"
+	applyRotate(t, reconcile, rotateCommand(name))
+	first := string(getSecret(t, reconcile, name).Data[common.PasswordKey])
+
+	// the new request arrives in the rotation, the current one is finished first.
+	reconcile.Crd.SetAnnotations(map[string]string{v1.RotateCredentialsAnnotation: "2"})
+	applyRotate(t, reconcile, rotateCommand(name))
+	if err := reconcile.Client.Create(reconcile.Context, newRunningPod("b")); err != nil {
+		t.Fatal(err)
+	}
+	if result := applyRotate(t, reconcile, rotateCommand(name)); result.NotEmpty() {
+		t.Fatalf("expected the current rotation is finished, got %v", result)
+	}
+	secret := getSecret(t, reconcile, name)
+	if string(secret.Data[common.PasswordKey]) != first || secret.Annotations[common.RotatedCredentialsAnnotation] != "1" {
+		t.Fatalf("expected the current request is retired, got %v %v", secret.Data, secret.Annotations)
+	}
+
+	// then the new request is rotated from the beginning.
+	if result := applyRotate(t, reconcile, rotateCommand(name)); !result.NotEmpty() {
+		t.Fatalf("expected the new request waits the restart")
+	}
+	secret = getSecret(t, reconcile, name)
+	if string(secret.Data[common.PreviousPasswordKey]) != first || string(secret.Data[common.PasswordKey]) == first {
+		t.Errorf("expected the new password is written for the new request, got %v", secret.Data)
+	}
+}
+
+func TestRotateResumeRetired(t *testing.T) {
+	reconcile := newRotateReconcile("1")
+	cmd := rotateCommand("demo-redis-secret")
+	// the secret is retired but the request is not recorded by the status yet.
+	componentState(reconcile, cmd).UpdateActionStep(v1.Rotate, rotateStep, v1.Success, "1")
+	if result := applyRotate(t, reconcile, cmd); result.NotEmpty() {
+		t.Fatalf("expected the retired rotation is finished, got %v", result)
+	}
+	if string(getSecret(t, reconcile, "demo-redis-secret").Data[common.PasswordKey]) != "redis" {
+		t.Errorf("expected the retired secret is never written again")
+	}
+	if err := reconcile.Client.Get(reconcile.Context, client.ObjectKeyFromObject(newRunningPod("a")), &corev1.Pod{}); err != nil {
+		t.Errorf("expected the pod is not restarted, got %v", err)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	crd := newConfCluster("maxmemory: 1mb")
+	crd.Spec.TLS = &v1.ClusterTLS{}
+	crd.Spec.Service = []*v1.CategoryClusterService{{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis-peer"},
+		ServiceSpec:             corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, Ports: []corev1.ServicePort{{Name: "redis", Port: 6379}}},
+	}}
+	crd.Spec.Components[0].ServiceName = "redis-peer"
+	reconcile := newFakeReconcile(crd)
+	if result := compute(reconcile); result.IsError() {
+		t.Fatal(result.LastError())
+	}
+
+	// the CA is self-signed, it is not renewed by the operator.
+	caSecret := getSecret(t, reconcile, "demo-tls-ca")
+	ca, err := util.ParseCertificate(caSecret.Data[corev1.TLSCertKey])
+	if err != nil || !ca.IsCA || ca.Subject.CommonName != "demo-ca" {
+		t.Fatalf("expected the CA certificate, got %v %v", ca, err)
+	}
+	if caSecret.Type != corev1.SecretTypeTLS || len(caSecret.Data[corev1.TLSPrivateKeyKey]) == 0 || len(caSecret.Data[common.TLSCAKey]) != 0 {
+		t.Errorf("expected the CA secret holds the certificate and the key, got %v", caSecret.Data)
+	}
+
+	secret := getSecret(t, reconcile, "demo-redis-tlssecret")
+	if secret.Type != corev1.SecretTypeTLS || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 ||
+		string(secret.Data[common.TLSCAKey]) != string(caSecret.Data[corev1.TLSCertKey]) {
+		t.Errorf("expected the secret holds the certificate, the key and the CA, got %v", secret.Data)
+	}
+	cert, err := util.ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cert.CheckSignatureFrom(ca); err != nil {
+		t.Errorf("expected the certificate is signed by the CA, got %v", err)
+	}
+	if dnsNames := strings.Join(cert.DNSNames, ","); dnsNames != secret.Annotations[common.TLSDNSNamesAnnotation] ||
+		!strings.Contains(dnsNames, "demo-redis-0.demo-redis-peer.ns.svc") {
+		t.Errorf("expected the certificate covers the dns names of the secret, got %s", dnsNames)
+	}
+
+	// the certificate is kept until its dns names are changed.
+	if result := compute(reconcile); result.IsError() {
+		t.Fatal(result.LastError())
+	}
+	if issued := getSecret(t, reconcile, "demo-redis-tlssecret"); string(issued.Data[corev1.TLSCertKey]) != string(secret.Data[corev1.TLSCertKey]) {
+		t.Errorf("expected the certificate is not issued again")
+	}
+}
@@ -3,7 +3,7 @@ package kernel
 import (
 	"context"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/util"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,6 +21,10 @@ type ReconcileContext struct {
 	Context  context.Context
 	Recorder record.EventRecorder
 	Crd      core.BasicCrd
+	// Status the status observed at the beginning of the reconcile.
+	Status *v1.MiddlewareClusterStatus
+	// Catalog the version catalog referenced by the cluster, it is nil when it is not found.
+	Catalog *v1.MiddlewareVersionCatalog
 	util.ReconcileClient
 }
 
@@ -46,15 +50,23 @@ func Reconcile(reconcile *ReconcileContext) core.CommandResult {
 		return core.Result().Error(err)
 	}
 
-	if reconcile.Crd.GetLabels()[InstancePauseLabel] == "true" {
-		reconcile.Log.Info("instance reconcile status is pause, requeue the event after 60s")
-		return core.Result().WithRequeueAfter(60 * time.Second)
-	}
-
 	delete(reconcile.Crd.GetAnnotations(), LastAppliedAnnotation)
+	// the crd may be created before the defaulting webhook, so the defaults are applied in memory.
+	reconcile.Crd.Default()
 	if reconcile.Crd.GetStatus().ComponentStatus == nil {
 		reconcile.Crd.SetStatus(*v1.NewClusterComponentStatus())
 	}
+	reconcile.Status = reconcile.Crd.GetStatus().DeepCopy()
+
+	if err = ResolveCatalog(reconcile); err != nil {
+		reconcile.Log.Error(err, "resolve the version catalog failed")
+		return ReduceStage(reconcile, core.Result().Error(err))
+	}
+
+	if reconcile.Crd.GetLabels()[InstancePauseLabel] == "true" {
+		reconcile.Log.Info("instance reconcile status is pause, requeue the event after 60s")
+		return ReduceStage(reconcile, core.Result().WithRequeueAfter(60*time.Second))
+	}
 
 	if !reconcile.Crd.GetDeletionTimestamp().IsZero() {
 		reconcile.Log.Info("resource marked delete", "deletionTimestamp", reconcile.Crd.GetDeletionTimestamp())
@@ -86,6 +98,12 @@ func Reconcile(reconcile *ReconcileContext) core.CommandResult {
 		WithPostApplyFunc(func(reconcile *ReconcileContext, command core.ActionCommand, result core.CommandResult) core.CommandResult {
 			return PostApplyStage(reconcile, command, result)
 		}).
+		WithPruneFunc(func(reconcile *ReconcileContext, desired map[v1.ComponentName]v1.ComponentKind) *core.ActionCommand {
+			return PruneStage(reconcile, desired)
+		}).
+		WithUpgradeFunc(func(reconcile *ReconcileContext) (map[v1.Category]bool, error) {
+			return UpgradeStage(reconcile)
+		}).
 		WithReduceFunc(func(reconcile *ReconcileContext, result core.CommandResult) core.CommandResult {
 			return ReduceStage(reconcile, result)
 		}).
@@ -0,0 +1,40 @@
+package kernel
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	v1 "github.com/kuberator/api/v1"
+	_ "github.com/kuberator/kernel/handler"
+	"github.com/kuberator/kernel/util"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newFakeReconcile the reconcile context of the cluster with the fake client, the cluster is created with the objects.
+func newFakeReconcile(crd *v1.MiddlewareCluster, objs ...client.Object) *ReconcileContext {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, crd)...).Build()
+	_ = cli.Get(context.Background(), client.ObjectKeyFromObject(crd), crd)
+	if crd.Status.ComponentStatus == nil {
+		crd.Status.ComponentStatus = v1.NewClusterComponentStatus().ComponentStatus
+	}
+	return &ReconcileContext{
+		Scheme:   scheme,
+		Request:  ctrl.Request{NamespacedName: types.NamespacedName{Namespace: crd.Namespace, Name: crd.Name}},
+		Context:  context.Background(),
+		Recorder: record.NewFakeRecorder(100),
+		Crd:      crd,
+		Status:   crd.Status.DeepCopy(),
+		ReconcileClient: util.ReconcileClient{
+			Client: cli,
+			Log:    logr.Discard(),
+		},
+	}
+}
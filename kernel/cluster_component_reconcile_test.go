@@ -2,7 +2,7 @@ package kernel
 
 import (
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	"io/ioutil"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
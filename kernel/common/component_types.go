@@ -2,6 +2,8 @@ package common
 
 const (
 	StatefulSet             = "StatefulSet"
+	Deployment              = "Deployment"
+	DaemonSet               = "DaemonSet"
 	Ingress                 = "Ingress"
 	Service                 = "Service"
 	ConfigMap               = "ConfigMap"
@@ -9,10 +11,32 @@ const (
 	PersistentVolumeClaim   = "PersistentVolumeClaim"
 	Secret                  = "Secret"
 	HorizontalPodAutoscaler = "HorizontalPodAutoscaler"
+	NetworkPolicy           = "NetworkPolicy"
+	TLSSecret               = "TLSSecret"
+	ServiceMonitor          = "ServiceMonitor"
+	PodMonitor              = "PodMonitor"
+	PrometheusRule          = "PrometheusRule"
+	Certificate             = "Certificate"
 	CronJob                 = "CronJob"
 	Job                     = "Job"
 )
 
+const (
+	// PasswordKey the password of the managed secret.
+	PasswordKey = "password"
+	// PreviousPasswordKey the old password kept by the managed secret while the credentials are rotating.
+	PreviousPasswordKey = "previous-password"
+	// TLSCAKey the CA certificate of the component certificate secret.
+	TLSCAKey = "ca.crt"
+)
+
+const (
+	// PasswordSourceGenerated the password of the managed secret is generated once by the operator.
+	PasswordSourceGenerated = "generated"
+	// PasswordSourceLegacy the password of the managed secret is the v1beta1 plaintext password.
+	PasswordSourceLegacy = "legacy"
+)
+
 const (
 	Category           = "CATEGORY"
 	AppName            = "APP_NAME"
@@ -22,8 +46,13 @@ const (
 	PeerService        = "PEER_SVC"
 	InitialReplicas    = "INITIAL_CLUSTER_SIZE"
 	Namespace          = "NAMESPACE"
+	UpgradeFrom        = "UPGRADE_FROM"
+	UpgradeTo          = "UPGRADE_TO"
+	RecoveryMode       = "RECOVERY_MODE"
 	ConfVersion        = "ConfVersion"
 	AppConfigMapVolume = "app-config-volume"
+	TLSVolume          = "tls-volume"
+	TLSCACategory      = "tls-ca"
 	Normal             = "Normal"
 	Warning            = "Warning"
 )
@@ -34,9 +63,26 @@ const (
 	InstanceLabel         = "app.kubernetes.io/instance"
 	AppLabel              = "app.kubernetes.io/app"
 	ComponentLabel        = "app.kubernetes.io/component"
+	UserLabel             = "app.kubernetes.io/user"
+	ConfRevisionLabel     = "app.kubernetes.io/conf-revision"
 	InstancePauseLabel    = "app.kubernetes.io/jd-instance-pause"
 	LastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
-	ControlLabel          = "app.kubernetes.io/control"
+	// PasswordSourceAnnotation the managed secret records where the password of the component comes from.
+	PasswordSourceAnnotation = "apps.devless.toplogy.com/password-source"
+	// TLSDNSNamesAnnotation the certificate secret records the dns names of the certificate.
+	TLSDNSNamesAnnotation = "apps.devless.toplogy.com/tls-dns-names"
+	// CertificateRevisionAnnotation the Certificate records the revision of the cert-manager certificate which the pods are restarted with.
+	CertificateRevisionAnnotation = "apps.devless.toplogy.com/certificate-revision"
+	// ProvisionedAnnotation the secret records the password digest of the user provisioned inside the middleware.
+	ProvisionedAnnotation = "apps.devless.toplogy.com/provisioned"
+	// ReloadPolicyAnnotation the configMap records the reload policy of its files.
+	ReloadPolicyAnnotation = "apps.devless.toplogy.com/reload-policy"
+	// UpgradeVersionAnnotation the upgrade hook Job records the version which it runs for.
+	UpgradeVersionAnnotation = "apps.devless.toplogy.com/upgrade-version"
+	// RotatedCredentialsAnnotation the managed secret records the last finished rotation request.
+	RotatedCredentialsAnnotation = "apps.devless.toplogy.com/rotated-credentials"
+	RestartedAtAnnotation        = "kubectl.kubernetes.io/restartedAt"
+	ControlLabel                 = "app.kubernetes.io/control"
 )
 
 const (
@@ -1,20 +1,50 @@
 package common
 
 import (
-	v1 "github.com/kuberator/api/v1beta1"
+	"encoding/json"
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sort"
 )
 
+// LegacyConverter convert the build-in resource between the current and the legacy api version.
+// If not set, the resource is converted by json, it works when both versions have the same schema.
+type LegacyConverter struct {
+	ToLegacy   func(current client.Object) (client.Object, error)
+	FromLegacy func(legacy client.Object) (client.Object, error)
+}
+
 var (
 	typedMap, buildInMap, buildInList map[v1.ComponentKind]reflect.Type
+	legacyMap, legacyList             map[v1.ComponentKind]reflect.Type
+	legacyConverter                   map[v1.ComponentKind]LegacyConverter
+	// legacyKinds the kinds which the cluster does not serve the current api version.
+	legacyKinds map[v1.ComponentKind]bool
+	// unstructuredMap the kinds built as the unstructured object, their api types are not the compile dependency.
+	unstructuredMap map[v1.ComponentKind]schema.GroupVersionKind
+	// unservedKinds the unstructured kinds which the cluster does not serve, e.g. the CRD is not installed.
+	unservedKinds map[v1.ComponentKind]bool
 )
 
 func init() {
 	typedMap = map[v1.ComponentKind]reflect.Type{}
 	buildInMap = map[v1.ComponentKind]reflect.Type{}
 	buildInList = map[v1.ComponentKind]reflect.Type{}
+	legacyMap = map[v1.ComponentKind]reflect.Type{}
+	legacyList = map[v1.ComponentKind]reflect.Type{}
+	legacyConverter = map[v1.ComponentKind]LegacyConverter{}
+	legacyKinds = map[v1.ComponentKind]bool{}
+	unstructuredMap = map[v1.ComponentKind]schema.GroupVersionKind{}
+	unservedKinds = map[v1.ComponentKind]bool{}
 }
 
 func Inject(kind v1.ComponentKind, handler, buildIn, buildIns interface{}) {
@@ -23,12 +53,147 @@ func Inject(kind v1.ComponentKind, handler, buildIn, buildIns interface{}) {
 	buildInList[kind] = reflect.TypeOf(buildIns)
 }
 
+// InjectLegacy the legacy api version of the kind, it is used when the cluster does not serve the current one.
+func InjectLegacy(kind v1.ComponentKind, buildIn, buildIns interface{}, converter LegacyConverter) {
+	legacyMap[kind] = reflect.TypeOf(buildIn)
+	legacyList[kind] = reflect.TypeOf(buildIns)
+	legacyConverter[kind] = converter
+}
+
+// InjectUnstructured the kind built as the unstructured object of the gvk, it is skipped when the cluster does not serve it.
+func InjectUnstructured(kind v1.ComponentKind, handler interface{}, gvk schema.GroupVersionKind) {
+	Inject(kind, handler, unstructured.Unstructured{}, unstructured.UnstructuredList{})
+	unstructuredMap[kind] = gvk
+}
+
+// SelectBuildInVersions choose the api version of the kind by the discovery, it should be called at startup.
+func SelectBuildInVersions(cli discovery.ServerResourcesInterface, scheme *runtime.Scheme) (map[v1.ComponentKind]string, error) {
+	selected := map[v1.ComponentKind]string{}
+	for kind := range legacyMap {
+		gvk, err := apiutil.GVKForObject(reflect.New(buildInMap[kind]).Interface().(client.Object), scheme)
+		if err != nil {
+			return nil, err
+		}
+		served, err := isServed(cli, gvk.GroupVersion().String(), gvk.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if !served {
+			legacyKinds[kind] = true
+			if gvk, err = apiutil.GVKForObject(reflect.New(legacyMap[kind]).Interface().(client.Object), scheme); err != nil {
+				return nil, err
+			}
+		} else {
+			delete(legacyKinds, kind)
+		}
+		selected[kind] = gvk.GroupVersion().String()
+	}
+	for kind, gvk := range unstructuredMap {
+		served, err := isServed(cli, gvk.GroupVersion().String(), gvk.Kind)
+		if err != nil {
+			return nil, err
+		}
+		if !served {
+			unservedKinds[kind] = true
+			continue
+		}
+		delete(unservedKinds, kind)
+		selected[kind] = gvk.GroupVersion().String()
+	}
+	return selected, nil
+}
+
+func isServed(cli discovery.ServerResourcesInterface, groupVersion, kind string) (bool, error) {
+	resources, err := cli.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsLegacy is the kind built with the legacy api version.
+func IsLegacy(kind v1.ComponentKind) bool {
+	return legacyKinds[kind]
+}
+
+// IsServed is the kind served by the cluster, only the unstructured kind may be not served.
+func IsServed(kind v1.ComponentKind) bool {
+	return !unservedKinds[kind]
+}
+
+// ToBuildInVersion convert the current version resource to the version served by the cluster.
+func ToBuildInVersion(kind v1.ComponentKind, obj client.Object) (client.Object, error) {
+	if obj == nil || !IsLegacy(kind) || reflect.TypeOf(obj).Elem() == legacyMap[kind] {
+		return obj, nil
+	}
+	if converter := legacyConverter[kind].ToLegacy; converter != nil {
+		return converter(obj)
+	}
+	return convertByJSON(obj, legacyMap[kind])
+}
+
+// ToCurrentVersion convert the legacy version resource to the current version.
+func ToCurrentVersion(kind v1.ComponentKind, obj client.Object) (client.Object, error) {
+	if obj == nil || legacyMap[kind] == nil || reflect.TypeOf(obj).Elem() != legacyMap[kind] {
+		return obj, nil
+	}
+	if converter := legacyConverter[kind].FromLegacy; converter != nil {
+		return converter(obj)
+	}
+	return convertByJSON(obj, buildInMap[kind])
+}
+
+func convertByJSON(obj client.Object, target reflect.Type) (client.Object, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	converted, ok := reflect.New(target).Interface().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a client object", target)
+	}
+	if err = json.Unmarshal(data, converted); err != nil {
+		return nil, err
+	}
+	converted.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	return converted, nil
+}
+
+func buildInType(kind v1.ComponentKind) reflect.Type {
+	if IsLegacy(kind) {
+		return legacyMap[kind]
+	}
+	return buildInMap[kind]
+}
+
+func buildInListType(kind v1.ComponentKind) reflect.Type {
+	if IsLegacy(kind) {
+		return legacyList[kind]
+	}
+	return buildInList[kind]
+}
+
 func NewTypedObject(kind v1.ComponentKind) interface{} {
 	return reflect.New(typedMap[kind]).Interface()
 }
 
 func NewBuildInResource(kind v1.ComponentKind, namespaceName types.NamespacedName) client.Object {
-	obj := reflect.New(buildInMap[kind]).Interface().(client.Object)
+	if gvk, ok := unstructuredMap[kind]; ok {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespaceName.Namespace)
+		obj.SetName(namespaceName.Name)
+		return obj
+	}
+	obj := reflect.New(buildInType(kind)).Interface().(client.Object)
 	if obj != nil {
 		gvk := obj.GetObjectKind().GroupVersionKind()
 		gvk.Kind = string(kind)
@@ -40,5 +205,55 @@ func NewBuildInResource(kind v1.ComponentKind, namespaceName types.NamespacedNam
 }
 
 func NewBuildInListResource(kind v1.ComponentKind) client.ObjectList {
-	return reflect.New(buildInList[kind]).Interface().(client.ObjectList)
+	if gvk, ok := unstructuredMap[kind]; ok {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		return list
+	}
+	return reflect.New(buildInListType(kind)).Interface().(client.ObjectList)
+}
+
+// BuildInKinds the sorted kinds injected and served by the cluster.
+func BuildInKinds() []v1.ComponentKind {
+	kinds := make([]string, 0, len(buildInMap))
+	for kind := range buildInMap {
+		if IsServed(v1.ComponentKind(kind)) {
+			kinds = append(kinds, string(kind))
+		}
+	}
+	sort.Strings(kinds)
+	served := make([]v1.ComponentKind, 0, len(kinds))
+	for _, kind := range kinds {
+		served = append(served, v1.ComponentKind(kind))
+	}
+	return served
+}
+
+// BuildInResources the build-in resource template of all the injected kind.
+func BuildInResources() []client.Object {
+	kinds := make([]string, 0, len(buildInMap))
+	for kind := range buildInMap {
+		kinds = append(kinds, string(kind))
+	}
+	sort.Strings(kinds)
+
+	var objects []client.Object
+	// the kinds may share the same type, e.g. the Secret and the TLSSecret.
+	owned := map[reflect.Type]bool{}
+	for _, kind := range kinds {
+		if _, ok := unstructuredMap[v1.ComponentKind(kind)]; ok {
+			// the unserved kind can not be watched.
+			if IsServed(v1.ComponentKind(kind)) {
+				objects = append(objects, NewBuildInResource(v1.ComponentKind(kind), types.NamespacedName{}))
+			}
+			continue
+		}
+		t := buildInType(v1.ComponentKind(kind))
+		if owned[t] {
+			continue
+		}
+		owned[t] = true
+		objects = append(objects, reflect.New(t).Interface().(client.Object))
+	}
+	return objects
 }
@@ -0,0 +1,135 @@
+package common
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"reflect"
+	"testing"
+)
+
+// isolateRegister the test registers and selects the kinds on the copies of the registry, the registry is restored when it is done.
+func isolateRegister(t *testing.T) {
+	copyTypes := func(m map[v1.ComponentKind]reflect.Type) map[v1.ComponentKind]reflect.Type {
+		c := make(map[v1.ComponentKind]reflect.Type, len(m))
+		for k, v := range m {
+			c[k] = v
+		}
+		return c
+	}
+	copyKinds := func(m map[v1.ComponentKind]bool) map[v1.ComponentKind]bool {
+		c := make(map[v1.ComponentKind]bool, len(m))
+		for k, v := range m {
+			c[k] = v
+		}
+		return c
+	}
+	typed, buildIn, buildIns, legacy, legacies := typedMap, buildInMap, buildInList, legacyMap, legacyList
+	converter, legacyKind, unstructuredKind, unservedKind := legacyConverter, legacyKinds, unstructuredMap, unservedKinds
+	t.Cleanup(func() {
+		typedMap, buildInMap, buildInList, legacyMap, legacyList = typed, buildIn, buildIns, legacy, legacies
+		legacyConverter, legacyKinds, unstructuredMap, unservedKinds = converter, legacyKind, unstructuredKind, unservedKind
+	})
+
+	typedMap, buildInMap, buildInList = copyTypes(typed), copyTypes(buildIn), copyTypes(buildIns)
+	legacyMap, legacyList = copyTypes(legacy), copyTypes(legacies)
+	legacyKinds, unservedKinds = copyKinds(legacyKind), copyKinds(unservedKind)
+	legacyConverter = make(map[v1.ComponentKind]LegacyConverter, len(converter))
+	for k, v := range converter {
+		legacyConverter[k] = v
+	}
+	unstructuredMap = make(map[v1.ComponentKind]schema.GroupVersionKind, len(unstructuredKind))
+	for k, v := range unstructuredKind {
+		unstructuredMap[k] = v
+	}
+}
+
+func TestSelectBuildInVersions(t *testing.T) {
+	isolateRegister(t)
+	const kind v1.ComponentKind = "CronJob"
+	Inject(kind, struct{}{}, batchv1.CronJob{}, batchv1.CronJobList{})
+	InjectLegacy(kind, batchv1beta1.CronJob{}, batchv1beta1.CronJobList{}, LegacyConverter{})
+
+	cli := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	cli.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "batch/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob"}},
+	}}
+	versions, err := SelectBuildInVersions(cli, scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions[kind] != "batch/v1beta1" || !IsLegacy(kind) {
+		t.Fatalf("expected the legacy version, got %v", versions)
+	}
+	if _, ok := NewBuildInResource(kind, types.NamespacedName{Name: "job"}).(*batchv1beta1.CronJob); !ok {
+		t.Errorf("expected the legacy build-in resource")
+	}
+
+	desired := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "job"}, Spec: batchv1.CronJobSpec{Schedule: "* * * * *"}}
+	legacy, err := ToBuildInVersion(kind, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacy.(*batchv1beta1.CronJob).Spec.Schedule != "* * * * *" {
+		t.Errorf("unexpected legacy resource %v", legacy)
+	}
+	current, err := ToCurrentVersion(kind, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.(*batchv1.CronJob).Name != "job" {
+		t.Errorf("unexpected current resource %v", current)
+	}
+
+	cli.Resources = append(cli.Resources, &metav1.APIResourceList{
+		GroupVersion: "batch/v1",
+		APIResources: []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob"}},
+	})
+	if versions, err = SelectBuildInVersions(cli, scheme.Scheme); err != nil || versions[kind] != "batch/v1" || IsLegacy(kind) {
+		t.Fatalf("expected the current version, got %v %v", versions, err)
+	}
+}
+
+func TestSelectUnstructuredVersions(t *testing.T) {
+	isolateRegister(t)
+	const kind v1.ComponentKind = "ServiceMonitor"
+	gvk := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	InjectUnstructured(kind, struct{}{}, gvk)
+
+	cli := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	if _, err := SelectBuildInVersions(cli, scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	if IsServed(kind) {
+		t.Fatalf("expected the kind is not served without the CRD")
+	}
+	for _, obj := range BuildInResources() {
+		if obj.GetObjectKind().GroupVersionKind() == gvk {
+			t.Errorf("the unserved kind should not be watched")
+		}
+	}
+
+	cli.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "monitoring.coreos.com/v1",
+		APIResources: []metav1.APIResource{{Name: "servicemonitors", Kind: "ServiceMonitor"}},
+	}}
+	versions, err := SelectBuildInVersions(cli, scheme.Scheme)
+	if err != nil || versions[kind] != "monitoring.coreos.com/v1" || !IsServed(kind) {
+		t.Fatalf("expected the kind is served, got %v %v", versions, err)
+	}
+	obj, ok := NewBuildInResource(kind, types.NamespacedName{Name: "monitor"}).(*unstructured.Unstructured)
+	if !ok || obj.GroupVersionKind() != gvk || obj.GetName() != "monitor" {
+		t.Errorf("unexpected unstructured resource %v", obj)
+	}
+	if list := NewBuildInListResource(kind).(*unstructured.UnstructuredList); list.GetKind() != "ServiceMonitorList" {
+		t.Errorf("unexpected unstructured list %v", list.GetKind())
+	}
+}
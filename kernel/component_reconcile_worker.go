@@ -1,9 +1,10 @@
 package kernel
 
 import (
+	"errors"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	"github.com/kuberator/kernel/util"
@@ -21,6 +22,8 @@ type (
 	ApplyFunc       = func(reconcile *ReconcileContext, command *core.ActionCommand) core.CommandResult
 	PostApplyFunc   = func(reconcile *ReconcileContext, command core.ActionCommand, result core.CommandResult) core.CommandResult
 	ReduceFunc      = func(reconcile *ReconcileContext, result core.CommandResult) core.CommandResult
+	PruneFunc       = func(reconcile *ReconcileContext, desired map[v1.ComponentName]v1.ComponentKind) *core.ActionCommand
+	UpgradeFunc     = func(reconcile *ReconcileContext) (map[v1.Category]bool, error)
 
 	Pipeline struct {
 		reconcile     *ReconcileContext
@@ -33,13 +36,20 @@ type (
 		apply         ApplyFunc
 		postApply     PostApplyFunc
 		reduce        ReduceFunc
+		prune         PruneFunc
+		upgrade       UpgradeFunc
 		ResourcesLine *core.ResourcesLine
 		ActionCommand *core.ActionCommand
+		// held the categories of the components which wait their turn of the upgrade or their conf is fixed.
+		held map[v1.Category]bool
+		// invalid the conf of the configMaps which can not be validated, the observed configMaps are kept.
+		invalid map[v1.ComponentName]error
 	}
 )
 
 func (this *Pipeline) add(task core.TypedCategoryComponent) *Pipeline {
-	if task == nil {
+	// the kind is not served when its CRD is not installed, e.g. the prometheus operator.
+	if task == nil || !IsServed(task.GetKind()) {
 		return this
 	}
 	this.chain = append(this.chain, task)
@@ -47,6 +57,14 @@ func (this *Pipeline) add(task core.TypedCategoryComponent) *Pipeline {
 	return this
 }
 
+// hold the components of the category are not applied in this reconcile.
+func (this *Pipeline) hold(category v1.Category) {
+	if this.held == nil {
+		this.held = map[v1.Category]bool{}
+	}
+	this.held[category] = true
+}
+
 func (this *Pipeline) WithMakeFunc(fun MakeFunc) *Pipeline {
 	this.make = fun
 	return this
@@ -87,9 +105,31 @@ func (this *Pipeline) WithReduceFunc(fun ReduceFunc) *Pipeline {
 	return this
 }
 
+func (this *Pipeline) WithPruneFunc(fun PruneFunc) *Pipeline {
+	this.prune = fun
+	return this
+}
+
+func (this *Pipeline) WithUpgradeFunc(fun UpgradeFunc) *Pipeline {
+	this.upgrade = fun
+	return this
+}
+
 func (this *Pipeline) resourcePipeline() error {
 	for _, task := range this.chain {
 		command, err := this.make(this.reconcile, task)
+		// the invalid conf only skips its configMap, the workload of the category waits it fixed.
+		var syntaxError *util.ConfSyntaxError
+		if errors.As(err, &syntaxError) {
+			if this.invalid == nil {
+				this.invalid = map[v1.ComponentName]error{}
+			}
+			this.invalid[task.GetName()] = err
+			if meta, ok := task.(*core.CategoryComponentObject); ok && meta.Reference != nil {
+				this.hold(meta.Reference.GetCategory())
+			}
+			command, err = &core.ResourcesLine{ResourceMeta: task}, nil
+		}
 		if err != nil {
 			return err
 		}
@@ -108,14 +148,50 @@ func (this *Pipeline) resourcePipeline() error {
 func (this *Pipeline) actionPipeline() core.CommandResult {
 	restartMap := map[v1.Category]*core.ActionCommand{}
 	skipRestartMap := map[v1.Category]bool{}
+	restartStates := map[v1.Category][]*v1.ComponentState{}
+	rotateMap := map[v1.Category]*core.ActionCommand{}
+	var reloads []*core.ActionCommand
 	for cmd := this.ResourcesLine; cmd != nil; cmd = cmd.Next {
 		var action *core.ActionCommand
 		result := core.Result()
 
 		this.merge(this.reconcile, cmd)
+		invalid, isInvalid := this.invalid[cmd.ResourceMeta.GetName()]
+		if isInvalid {
+			cmd.Desired = nil
+			if cmd.Observed != nil {
+				cmd.Desired = cmd.Observed.DeepCopyObject().(client.Object)
+			}
+		}
 		isChanged, state := this.stateFinger(this.reconcile, cmd.ResourceMeta, cmd.Observed, cmd.Desired)
+		// record the inventory of the component.
+		state.Kind = cmd.ResourceMeta.GetKind()
+		state.Category = cmd.ResourceMeta.GetCategory()
+		// record the workload running state.
+		if ready, ok := util.IsWorkloadReady(cmd.Observed); ok {
+			state.State = v1.NotReady
+			if ready {
+				state.State = v1.Ready
+			}
+		}
+		state.Workload = util.GetWorkloadStatus(cmd.Observed)
+		if isInvalid {
+			state.UpdateActionState(v1.Update, v1.Failed, invalid.Error())
+		} else if !isChanged && state.GetActionState(v1.Update).State == v1.Failed {
+			// the failed update is useless when the object is as desired, e.g. the invalid conf is reverted.
+			state.UpdateActionState(v1.Update, v1.Success, "")
+		}
 
-		if isChanged {
+		_, isComponent := cmd.ResourceMeta.(*v1.CategoryClusterComponent)
+		// the workload of the upgrade step carries the template of the target version, the health gate waits its generation.
+		upgrade := this.reconcile.Crd.GetStatus().Upgrade
+		if isComponent && !isChanged && cmd.Observed != nil && upgrade != nil && upgrade.Step == string(cmd.ResourceMeta.GetCategory()) {
+			upgrade.Generation = cmd.Observed.GetGeneration()
+		}
+		if isChanged && isComponent && this.held[cmd.ResourceMeta.GetCategory()] {
+			// the workload is applied when its turn of the upgrade comes or its conf is fixed.
+			this.reconcile.Log.Info("the component is held by the upgrade or the invalid conf", "category", cmd.ResourceMeta.GetCategory(), "name", cmd.ResourceMeta.GetName())
+		} else if isChanged {
 			action, result = this.preApply(this.reconcile, cmd.ResourceMeta, cmd.Observed, cmd.Desired)
 		} else {
 			action = this.visitation(this.reconcile, cmd.ResourceMeta, cmd.Observed, cmd.Desired)
@@ -135,6 +211,16 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 						action.Append(act)
 					}
 				}
+				// wait the conf refreshed in the pods, then reload them.
+				if reload := state.ActionState[v1.Reload]; cmd.Desired != nil && len(reload.State) > 0 && reload.State != v1.Success && reload.State != v1.Failed {
+					policy := v1.ReloadPolicy(state.GetActionStep(v1.Reload, reloadPolicyStep).Uid)
+					act := util.GetReloadCommand(cmd.Desired, policy, reload.Message)
+					if action == nil {
+						action = act
+					} else {
+						action.Append(act)
+					}
+				}
 			}
 		}
 
@@ -142,6 +228,8 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 			return result
 		}
 
+		// the running state is recorded even if nothing need to do.
+		this.reconcile.Crd.GetStatus().ComponentStatus[cmd.ResourceMeta.GetName()] = state
 		if action == nil {
 			continue
 		}
@@ -157,6 +245,10 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 			node := *a
 			node.Next = nil
 
+			// the changed resource begin a new restart, the old progress is useless.
+			if isChanged && a.Action == v1.Restart {
+				state.ResetActionSteps(a.Action)
+			}
 			state.RecordActionState(a.Action, v1.Preparing, a.Message)
 			// skip restart.
 			if a.Action == v1.Create || a.Action == v1.Delete {
@@ -165,6 +257,21 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 			if a.Action == v1.Restart {
 				state.RecordActionState(a.Action, v1.WaitRestart, a.Message)
 				restartMap[node.TargetResource.Category] = &node
+				restartStates[node.TargetResource.Category] = append(restartStates[node.TargetResource.Category], state)
+				continue
+			}
+			// the secrets referenced by the same category are rotated together, so that the pods are restarted once.
+			if a.Action == v1.Rotate {
+				if group, ok := rotateMap[node.TargetResource.Category]; ok {
+					group.TargetResource.Extends = append(group.TargetResource.Extends.([]*core.ActionCommand), &node)
+					continue
+				}
+				node.TargetResource.Extends = []*core.ActionCommand{&node}
+				rotateMap[node.TargetResource.Category] = &node
+			}
+			// the reload waits the conf refreshed in the pods, it should not block the other actions.
+			if a.Action == v1.Reload {
+				reloads = append(reloads, &node)
 				continue
 			}
 
@@ -175,7 +282,17 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 				this.ActionCommand.MoveIfAbsent(&node)
 			}
 		}
-		this.reconcile.Crd.GetStatus().ComponentStatus[cmd.ResourceMeta.GetName()] = state
+	}
+
+	// prune the resource removed from the spec.
+	if this.prune != nil {
+		if prune := this.prune(this.reconcile, this.desired()); prune != nil {
+			if this.ActionCommand == nil {
+				this.ActionCommand = prune
+			} else {
+				this.ActionCommand.Append(prune)
+			}
+		}
 	}
 
 	// append restart command.
@@ -186,6 +303,10 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 		}
 		if skipRestartMap[k] {
 			this.reconcile.Log.Info("restart", "category", v.TargetResource.Category, "name", v.ResourceMeta.GetName(), "cause", cause, "result", "skip")
+			// the pods of the category are created or deleted, the waiting restarts are done by them.
+			for _, state := range restartStates[k] {
+				state.UpdateActionState(v1.Restart, v1.Success, "")
+			}
 			continue
 		}
 		// add action line.
@@ -196,9 +317,30 @@ func (this *Pipeline) actionPipeline() core.CommandResult {
 		}
 	}
 
+	// append reload command.
+	for _, v := range reloads {
+		if this.ActionCommand == nil {
+			this.ActionCommand = v
+		} else {
+			this.ActionCommand.Append(v)
+		}
+	}
+
 	return core.Result()
 }
 
+// desired the kinds of the component names which are desired by the current spec.
+func (this *Pipeline) desired() map[v1.ComponentName]v1.ComponentKind {
+	names := map[v1.ComponentName]v1.ComponentKind{}
+	for _, task := range this.chain {
+		names[task.GetName()] = task.GetKind()
+	}
+	for cmd := this.ResourcesLine; cmd != nil; cmd = cmd.Next {
+		names[cmd.ResourceMeta.GetName()] = cmd.ResourceMeta.GetKind()
+	}
+	return names
+}
+
 func (this *Pipeline) exec() core.CommandResult {
 	result := core.Result()
 	for cmd := this.ActionCommand; !result.NotEmpty() && cmd != nil; cmd = cmd.Next {
@@ -206,6 +348,7 @@ func (this *Pipeline) exec() core.CommandResult {
 		if state == nil {
 			this.reconcile.Log.Info("state not found", "category", cmd.ResourceMeta.GetCategory(), "resource name", cmd.ResourceMeta.GetName())
 			state = v1.NewComponentState(v1.Success, "unknown state", nil)
+			this.reconcile.Crd.GetStatus().ComponentStatus[cmd.ResourceMeta.GetName()] = state
 		}
 
 		// not update the status.
@@ -220,6 +363,10 @@ func (this *Pipeline) exec() core.CommandResult {
 		if result.IsError() {
 			state.UpdateActionState(cmd.Action, v1.Failed, result.LastError().Error())
 			this.reconcile.Recorder.Eventf(cmd.TargetResource.Target, Normal, string(cmd.Action), result.LastError().Error())
+		} else if result.NotEmpty() {
+			// the action is not finished, it will be resumed in the next reconcile.
+			this.reconcile.Log.Info("apply in progress", "action", cmd.Action, "category", cmd.TargetResource.Category, "name", cmd.ResourceMeta.GetName())
+			state.UpdateActionState(cmd.Action, v1.InProgress, cmd.Message)
 		} else {
 			this.reconcile.Log.Info("apply success", "action", cmd.Action, "category", cmd.TargetResource.Category, "name", cmd.ResourceMeta.GetName())
 			// update state
@@ -247,8 +394,21 @@ func (this *Pipeline) exec() core.CommandResult {
 func (this *Pipeline) Compute() core.CommandResult {
 	this.reconcile.Log.Info("begin build in resources make stage")
 	if err := this.resourcePipeline(); err != nil {
-		this.reconcile.Log.Info("build in resources make stage failed.")
-		return core.Result().Error(err)
+		this.reconcile.Log.Info("build in resources make stage failed, begin reduce stage.")
+		// the error is reported by the conditions, e.g. the properties can not be merged.
+		return this.reduce(this.reconcile, core.Result().Error(err))
+	}
+	if this.upgrade != nil {
+		held, err := this.upgrade(this.reconcile)
+		if err != nil {
+			this.reconcile.Log.Info("upgrade stage failed, begin reduce stage.")
+			return this.reduce(this.reconcile, core.Result().Error(err))
+		}
+		for category := range held {
+			if held[category] {
+				this.hold(category)
+			}
+		}
 	}
 	this.reconcile.Log.Info("build in resources make ok, begin command construct stage.")
 	if r := this.actionPipeline(); r.NotEmpty() {
@@ -257,7 +417,7 @@ func (this *Pipeline) Compute() core.CommandResult {
 	}
 	if this.ActionCommand == nil {
 		this.reconcile.Log.Info("action stage exit with no command.")
-		return core.Result()
+		return this.reduce(this.reconcile, core.Result())
 	}
 	this.reconcile.Log.Info("command construct stage ok, begin exec action stage.")
 	result := this.exec()
@@ -275,9 +435,21 @@ func Compile(reconcile *ReconcileContext) *Pipeline {
 	}
 	cms := util.BuildConfResource(crd.GetSpec().Conf, crd.GetSpec().Components)
 	for _, task := range cms {
+		// the immutable configMap is named by the revision of its content.
+		task.SetName(v1.ComponentName(util.GetComponentConfigMapName(crd, task.Reference.(*v1.CategoryClusterComponent))))
 		pipeline.add(Format(task, crd))
 	}
+	//TLS CA, it signs the certificates of the components.
+	pipeline.add(Format(&core.CategoryComponentObject{
+		CommonCategoryComponent: v1.CommonCategoryComponent{
+			Category:  TLSCACategory,
+			Component: v1.Component{Kind: TLSSecret},
+		},
+	}, crd))
 	for _, task := range crd.GetSpec().Components {
+		//TLS certificate, it is issued before the workload mounts it.
+		pipeline.add(Format(InferResource(task, TLSSecret), crd))
+		pipeline.add(Format(InferResource(task, Certificate), crd))
 		pipeline.add(Format(task, crd))
 		//PVC
 		pipeline.add(Format(InferResource(task, PersistentVolumeClaim), crd))
@@ -287,6 +459,15 @@ func Compile(reconcile *ReconcileContext) *Pipeline {
 		pipeline.add(Format(InferResource(task, PodDisruptionBudget), crd))
 		//Secret
 		pipeline.add(Format(InferResource(task, Secret), crd))
+		for i := range task.Users {
+			pipeline.add(Format(InferUserResource(task, &task.Users[i]), crd))
+		}
+		//NetworkPolicy
+		pipeline.add(Format(InferResource(task, NetworkPolicy), crd))
+		//Monitoring
+		pipeline.add(Format(InferResource(task, ServiceMonitor), crd))
+		pipeline.add(Format(InferResource(task, PodMonitor), crd))
+		pipeline.add(Format(InferResource(task, PrometheusRule), crd))
 	}
 	for _, task := range crd.GetSpec().Ingress {
 		pipeline.add(Format(task, crd))
@@ -310,6 +491,20 @@ func InferResource(component *v1.CategoryClusterComponent, kind v1.ComponentKind
 	}
 }
 
+// InferUserResource the credentials secret of the named user.
+func InferUserResource(component *v1.CategoryClusterComponent, user *v1.ComponentUser) *core.CategoryComponentObject {
+	return &core.CategoryComponentObject{
+		CommonCategoryComponent: v1.CommonCategoryComponent{
+			Category: util.GetUserCategory(component, user.Name),
+			Component: v1.Component{
+				Kind: Secret,
+			},
+		},
+		Object:    user,
+		Reference: component,
+	}
+}
+
 func Format(task core.TypedCategoryComponent, com core.BasicCrd) core.TypedCategoryComponent {
 	if task == nil {
 		return nil
@@ -0,0 +1,207 @@
+package kernel
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+)
+
+// compute run the pipeline of the reconcile, the conditions are not reduced.
+func compute(reconcile *ReconcileContext) core.CommandResult {
+	return Compile(reconcile).
+		WithMakeFunc(MakeStage).
+		WithMergeFunc(MergeStage).
+		WithStateFingerFunc(StateFingerStage).
+		WithVisitationFunc(VisitationStage).
+		WithPreApplyFunc(PreApplyStage).
+		WithApplyFunc(Apply).
+		WithPostApplyFunc(PostApplyStage).
+		WithReduceFunc(func(reconcile *ReconcileContext, result core.CommandResult) core.CommandResult {
+			return result
+		}).
+		Compute()
+}
+
+// newConfCluster the redis cluster with the conf file.
+func newConfCluster(conf string) *v1.MiddlewareCluster {
+	replicas := int32(1)
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"},
+		Spec: v1.MiddlewareClusterSpec{
+			Components: []*v1.CategoryClusterComponent{{
+				CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis", Component: v1.Component{Kind: common.StatefulSet}},
+				Replicas:                &replicas,
+				PersistentVolumeClaim:   &corev1.PersistentVolumeClaimSpec{},
+				Properties:              []*v1.NamedProperties{{Path: "/etc", Name: "redis.yaml", Type: v1.Yaml, Data: conf}},
+				Template:                corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "redis", Image: "redis:7"}}}},
+			}},
+		},
+	}
+	crd.Default()
+	return crd
+}
+
+func TestSkipRestart(t *testing.T) {
+	crd := newConfCluster("maxmemory: 1mb")
+	reconcile := newFakeReconcile(crd)
+	if result := compute(reconcile); result.IsError() {
+		t.Fatal(result.LastError())
+	}
+	statefulSet := &appsv1.StatefulSet{}
+	if err := reconcile.Client.Get(reconcile.Context, types.NamespacedName{Namespace: "ns", Name: "demo-redis"}, statefulSet); err != nil {
+		t.Fatal(err)
+	}
+
+	// the conf is changed when the workload is recreated, the new pods load the new conf.
+	crd.Spec.Components[0].Properties[0].Data = "maxmemory: 2mb"
+	if err := reconcile.Client.Delete(reconcile.Context, statefulSet); err != nil {
+		t.Fatal(err)
+	}
+	if result := compute(reconcile); result.IsError() {
+		t.Fatal(result.LastError())
+	}
+	for name, state := range crd.Status.ComponentStatus {
+		if restart := state.GetActionState(v1.Restart); len(restart.State) > 0 && restart.State != v1.Success {
+			t.Errorf("expected the skipped restart of %s is finished, got %s", name, restart.State)
+		}
+	}
+	ReduceConditions(reconcile, core.Result())
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == v1.ConditionProgressing && condition.Status == metav1.ConditionTrue {
+			t.Errorf("expected the cluster is not progressing, got %s", condition.Message)
+		}
+	}
+}
+
+func TestInvalidConf(t *testing.T) {
+	replicas := int32(1)
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"},
+		Spec: v1.MiddlewareClusterSpec{
+			Service: []*v1.CategoryClusterService{{
+				CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis-svc"},
+				ServiceSpec:             corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "redis", Port: 6379}}},
+			}},
+			Components: []*v1.CategoryClusterComponent{{
+				CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis", Component: v1.Component{Kind: common.StatefulSet}},
+				Replicas:                &replicas,
+				PersistentVolumeClaim:   &corev1.PersistentVolumeClaimSpec{},
+				Properties:              []*v1.NamedProperties{{Path: "/etc", Name: "redis.yaml", Type: v1.Yaml, Data: "maxmemory: ["}},
+				Template:                corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "redis", Image: "redis:7"}}}},
+			}},
+		},
+	}
+	crd.Default()
+	reconcile := newFakeReconcile(crd)
+	result := compute(reconcile)
+	if result.IsError() {
+		t.Fatalf("expected the invalid conf never aborts the other components, got %v", result.LastError())
+	}
+
+	exists := func(obj client.Object, name string) bool {
+		err := reconcile.Client.Get(reconcile.Context, types.NamespacedName{Namespace: "ns", Name: name}, obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	if !exists(&corev1.Service{}, "demo-redis-svc") {
+		t.Errorf("expected the service is applied")
+	}
+	if exists(&corev1.ConfigMap{}, "demo-redis-configmap") {
+		t.Errorf("expected the invalid conf is not applied")
+	}
+	if exists(&appsv1.StatefulSet{}, "demo-redis") {
+		t.Errorf("expected the workload waits the conf fixed")
+	}
+
+	state := crd.Status.ComponentStatus["demo-redis-configmap"]
+	if state == nil || state.GetActionState(v1.Update).State != v1.Failed ||
+		!strings.Contains(state.GetActionState(v1.Update).Message, "redis.yaml") {
+		t.Errorf("expected the invalid conf is reported by the component status, got %v", state)
+	}
+	select {
+	case event := <-reconcile.Recorder.(*record.FakeRecorder).Events:
+		if !strings.Contains(event, "redis.yaml") {
+			t.Errorf("expected the invalid conf is reported by the event, got %s", event)
+		}
+	default:
+		t.Errorf("expected the invalid conf is reported by the event")
+	}
+}
+
+// selectServed select the build-in versions served by the discovery, all the kinds are served again after the test.
+func selectServed(t *testing.T, unstructured ...metav1.APIResourceList) {
+	current := []metav1.APIResourceList{
+		{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses", Kind: "Ingress"}}},
+		{GroupVersion: "policy/v1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets", Kind: "PodDisruptionBudget"}}},
+		{GroupVersion: "autoscaling/v2", APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler"}}},
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "cronjobs", Kind: "CronJob"}}},
+	}
+	all := append(current,
+		metav1.APIResourceList{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{{Name: "certificates", Kind: "Certificate"}}},
+		metav1.APIResourceList{GroupVersion: "monitoring.coreos.com/v1", APIResources: []metav1.APIResource{
+			{Name: "servicemonitors", Kind: "ServiceMonitor"}, {Name: "podmonitors", Kind: "PodMonitor"}, {Name: "prometheusrules", Kind: "PrometheusRule"},
+		}},
+	)
+	selectBy := func(resources []metav1.APIResourceList) error {
+		cli := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		for i := range resources {
+			cli.Resources = append(cli.Resources, &resources[i])
+		}
+		_, err := common.SelectBuildInVersions(cli, clientgoscheme.Scheme)
+		return err
+	}
+	t.Cleanup(func() {
+		if err := selectBy(all); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := selectBy(append(current, unstructured...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompileUnservedMonitoring(t *testing.T) {
+	crd := newConfCluster("maxmemory: 1mb")
+	crd.Spec.Components[0].Monitoring = &v1.ComponentMonitoring{
+		Kind:       common.PodMonitor,
+		Endpoints:  []v1.MonitorEndpoint{{Port: "metrics"}},
+		RuleGroups: []v1.MonitorRuleGroup{{Name: "redis", Rules: []v1.MonitorRule{{Alert: "RedisDown", Expr: "redis_up == 0"}}}},
+	}
+	kinds := func() map[v1.ComponentKind]bool {
+		compiled := map[v1.ComponentKind]bool{}
+		for _, task := range Compile(newFakeReconcile(crd)).chain {
+			compiled[task.GetKind()] = true
+		}
+		return compiled
+	}
+	if compiled := kinds(); !compiled[common.PodMonitor] || !compiled[common.PrometheusRule] {
+		t.Fatalf("expected the monitor and the rule are compiled, got %v", compiled)
+	}
+
+	// the prometheus operator is not installed, its kinds are skipped and the others are kept.
+	selectServed(t)
+	compiled := kinds()
+	if compiled[common.ServiceMonitor] || compiled[common.PodMonitor] || compiled[common.PrometheusRule] {
+		t.Errorf("expected the unserved kinds are skipped, got %v", compiled)
+	}
+	if !compiled[common.StatefulSet] || !compiled[common.ConfigMap] {
+		t.Errorf("expected the served kinds are compiled, got %v", compiled)
+	}
+	if result := compute(newFakeReconcile(crd)); result.IsError() {
+		t.Errorf("expected the cluster is reconciled without the prometheus operator, got %v", result.LastError())
+	}
+}
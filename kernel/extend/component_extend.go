@@ -3,7 +3,7 @@ package extend
 import (
 	"github.com/go-logr/logr"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
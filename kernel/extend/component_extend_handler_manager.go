@@ -3,7 +3,7 @@ package extend
 import (
 	"github.com/kuberator/api/core"
 	"github.com/kuberator/api/extends"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	"github.com/kuberator/kernel/common"
 )
 
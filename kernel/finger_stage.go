@@ -3,7 +3,8 @@ package kernel
 import (
 	"github.com/kuberator/api/core"
 	"github.com/kuberator/api/extends"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	"github.com/kuberator/kernel/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,14 +16,15 @@ func StateFingerStage(reconcile *ReconcileContext, source core.TypedCategoryComp
 	var isChanged = false
 
 	// use user define resource finger
-	desiredState = finger(ch, sh, desired)
-	observedState = finger(ch, sh, observed)
+	desiredState = finger(ch, sh, currentVersion(reconcile, source, desired))
+	observedState = finger(ch, sh, currentVersion(reconcile, source, observed))
 
 	// 1. resource need create or delete
 	// 2. resource update
 	// 3. reconcile action not success(.e.g. restart)
+	// 4. the operator annotations of the cluster are inherited by the previous version, they are removed by the update.
 	isChanged = ((observed == nil || desired == nil) && observed != desired) ||
-		observedState.Uid != desiredState.Uid
+		observedState.Uid != desiredState.Uid || (desired != nil && util.HasClusterAnnotations(observed))
 
 	state := reconcile.Crd.GetStatus().ComponentStatus[source.GetName()]
 	if state != nil {
@@ -44,6 +46,16 @@ func StateFingerStage(reconcile *ReconcileContext, source core.TypedCategoryComp
 	return isChanged, observedState
 }
 
+// currentVersion the handler finger the current api version of the resource.
+func currentVersion(reconcile *ReconcileContext, source core.TypedCategoryComponent, target client.Object) client.Object {
+	current, err := common.ToCurrentVersion(source.GetKind(), target)
+	if err != nil {
+		reconcile.Log.Error(err, "convert build in resource to current version failed", "category", source.GetCategory(), "name", source.GetName())
+		return target
+	}
+	return current
+}
+
 func finger(ch extend.TypedCategoryComponentHandler, sh extends.TypedComponentExtendStageLifeCycle, target client.Object) *v1.ComponentState {
 	var targetState *v1.ComponentState
 	if sh != nil {
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certManagerGroupVersion the api version of the cert-manager, its types are built as the unstructured object.
+var certManagerGroupVersion = schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
+
+// Make make the cert-manager Certificate of the component, the dns names are the same as the certificate issued by the cluster CA.
+func (component *CertificateHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	meta := source.ResourceMeta.(*core.CategoryComponentObject)
+	ref := meta.Reference.(*v1.CategoryClusterComponent)
+	if ref == nil || ref.Certificate == nil {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+
+	var dnsNames []interface{}
+	for _, name := range tlsDNSNames(source.Crd, ref) {
+		dnsNames = append(dnsNames, name)
+	}
+	cert := ref.Certificate
+	spec := map[string]interface{}{
+		"secretName": GetCertificateSecretName(source.Crd.GetName(), ref),
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  cert.IssuerRef.Name,
+			"kind":  cert.IssuerRef.Kind,
+			"group": cert.IssuerRef.Group,
+		},
+		"usages": []interface{}{"digital signature", "key encipherment", "server auth", "client auth"},
+	}
+	if cert.Duration != nil {
+		spec["duration"] = cert.Duration.Duration.String()
+	}
+	if cert.RenewBefore != nil {
+		spec["renewBefore"] = cert.RenewBefore.Duration.String()
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(certManagerGroupVersion.WithKind(Certificate))
+	obj.SetName(string(meta.GetName()))
+	obj.SetNamespace(source.Crd.GetNamespace())
+	obj.SetLabels(Merge(source.Crd.GetLabels(), GetReferenceLabels(ref, Certificate)))
+	obj.SetAnnotations(InheritedAnnotations(source.Crd.GetAnnotations()))
+	obj.SetOwnerReferences([]metav1.OwnerReference{ToOwnerReference(source)})
+
+	return &core.ResourcesLine{
+		Desired:      obj,
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger convert category state to component state
+func (component *CertificateHandler) StateFinger(obj client.Object) *v1.ComponentState {
+	return unstructuredStateFinger(obj)
+}
+
+// Visitation restart the pods of the referenced category when the secret is renewed by the cert-manager.
+// The cert-manager increases the revision of the Certificate when it writes the secret, the revision which the pods
+// are restarted with is recorded by the annotation of the Certificate. The first revision is recorded without restart,
+// the pods are waiting for the secret to be mounted.
+func (component *CertificateHandler) Visitation(args core.ComponentArgs) *core.ActionCommand {
+	observed, ok := args.Observed.(*unstructured.Unstructured)
+	if !ok || observed == nil || args.Desired == nil {
+		return nil
+	}
+	revision, found, _ := unstructured.NestedFieldNoCopy(observed.Object, "status", "revision")
+	if !found || revision == nil {
+		return nil
+	}
+	current := fmt.Sprintf("%v", revision)
+	recorded := observed.GetAnnotations()[CertificateRevisionAnnotation]
+	if recorded == current {
+		return nil
+	}
+
+	desired := args.Desired.(*unstructured.Unstructured).DeepCopy()
+	desired.SetAnnotations(Merge(desired.GetAnnotations(), map[string]string{CertificateRevisionAnnotation: current}))
+	command := &core.ActionCommand{
+		Action:  v1.Update,
+		Message: fmt.Sprintf("record the revision %s of the certificate %s", current, desired.GetName()),
+		TargetResource: &core.ReferenceObject{
+			Target: desired,
+		},
+	}
+	if len(recorded) == 0 {
+		return command
+	}
+	category := desired.GetLabels()[ReferenceLabel]
+	message := fmt.Sprintf("the certificate %s is renewed to the revision %s, need restart all the %s pod", desired.GetName(), current, category)
+	component.Logger().Info(message)
+	return command.Append(GetRestartCommand(desired, category, 0, message))
+}
+
+// OnEvent make and apply will call it.
+func (component *CertificateHandler) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
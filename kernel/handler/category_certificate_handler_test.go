@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	. "github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newCertificateSource(crd *v1.MiddlewareCluster, ref *v1.CategoryClusterComponent) core.CustomResource {
+	return core.CustomResource{
+		ResourceMeta: &core.CategoryComponentObject{
+			CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name: v1.ComponentName(GetCertificateSecretName(crd.Name, ref)), Category: "redis-certificate", Component: v1.Component{Kind: Certificate},
+			},
+			Reference: ref,
+		},
+		Crd: crd,
+	}
+}
+
+func newComponentCertificate() *v1.ComponentCertificate {
+	return &v1.ComponentCertificate{
+		IssuerRef:   v1.CertificateIssuerRef{Name: "corp", Kind: "ClusterIssuer", Group: "cert-manager.io"},
+		MountPath:   "/etc/certs",
+		Duration:    &metav1.Duration{Duration: 48 * time.Hour},
+		RenewBefore: &metav1.Duration{Duration: 12 * time.Hour},
+	}
+}
+
+func TestCertificateMake(t *testing.T) {
+	component, crd := newTLSComponent()
+	crd.Spec.TLS = nil
+	component.Certificate = newComponentCertificate()
+	handler := &CertificateHandler{}
+	line, err := handler.Make(newCertificateSource(crd, component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, ok := line.Desired.(*unstructured.Unstructured)
+	if !ok {
+		t.Fatalf("expected the unstructured certificate, got %T", line.Desired)
+	}
+	gvk := schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: Certificate}
+	if obj.GroupVersionKind() != gvk || obj.GetName() != "demo-redis-certificate" || obj.GetNamespace() != "ns" {
+		t.Errorf("unexpected certificate %v %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+	}
+	if obj.GetLabels()[ReferenceLabel] != "redis" || obj.GetLabels()["team"] != "storage" {
+		t.Errorf("expected the cluster and reference labels, got %v", obj.GetLabels())
+	}
+	if refs := obj.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != "demo" {
+		t.Errorf("expected the certificate is owned by the cluster, got %v", refs)
+	}
+
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if spec["secretName"] != GetCertificateSecretName("demo", component) {
+		t.Errorf("expected the secret mounted by the workload, got %v", spec["secretName"])
+	}
+	issuerRef := map[string]interface{}{"name": "corp", "kind": "ClusterIssuer", "group": "cert-manager.io"}
+	if !reflect.DeepEqual(spec["issuerRef"], issuerRef) {
+		t.Errorf("expected the issuer %v, got %v", issuerRef, spec["issuerRef"])
+	}
+	// the dns names are the same as the certificate issued by the cluster CA, the tls of the cluster is not required.
+	dnsNames, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "dnsNames")
+	if expected := tlsDNSNames(crd, component); len(dnsNames) == 0 || !reflect.DeepEqual(dnsNames, expected) {
+		t.Errorf("expected the dns names %v, got %v", expected, dnsNames)
+	}
+	if spec["duration"] != "48h0m0s" || spec["renewBefore"] != "12h0m0s" {
+		t.Errorf("expected the durations of the certificate, got %v %v", spec["duration"], spec["renewBefore"])
+	}
+	obj.DeepCopy()
+
+	component.Certificate = nil
+	if line, err = handler.Make(newCertificateSource(crd, component)); err != nil || line.Desired != nil {
+		t.Errorf("expected no certificate without the cert-manager, got %v %v", line.Desired, err)
+	}
+}
+
+func TestCertificateSource(t *testing.T) {
+	component, crd := newTLSComponent()
+	crd.Spec.TLS = &v1.ClusterTLS{MountPath: "/etc/tls"}
+	component.Template.Spec.Containers = []corev1.Container{{Name: "redis", Image: "redis:7"}}
+	mounted := func() (string, string) {
+		template := makePodTemplate(core.CustomResource{ResourceMeta: component, Crd: crd}, "demo-redis-peer")
+		for _, volume := range template.Spec.Volumes {
+			if volume.Name != TLSVolume {
+				continue
+			}
+			for _, mount := range template.Spec.Containers[0].VolumeMounts {
+				if mount.Name == TLSVolume {
+					return volume.Secret.SecretName, mount.MountPath
+				}
+			}
+		}
+		return "", ""
+	}
+
+	// the certificate is issued by the cluster CA.
+	if secret, path := mounted(); secret != GetTLSSecretName("demo", component) || path != "/etc/tls" {
+		t.Errorf("expected the certificate of the cluster CA is mounted, got %s %s", secret, path)
+	}
+	line, err := (&TLSSecretHandler{}).Make(newTLSSource(crd, component))
+	if err != nil || line.Desired == nil {
+		t.Errorf("expected the certificate of the cluster CA, got %v %v", line.Desired, err)
+	}
+
+	// the certificate of the cert-manager takes precedence over the cluster CA, the CA is still made.
+	component.Certificate = newComponentCertificate()
+	if secret, path := mounted(); secret != GetCertificateSecretName("demo", component) || path != "/etc/certs" {
+		t.Errorf("expected the certificate of the cert-manager is mounted, got %s %s", secret, path)
+	}
+	if line, err = (&TLSSecretHandler{}).Make(newTLSSource(crd, component)); err != nil || line.Desired != nil {
+		t.Errorf("expected no certificate of the cluster CA, got %v %v", line.Desired, err)
+	}
+	if line, err = (&TLSSecretHandler{}).Make(newTLSSource(crd, nil)); err != nil || line.Desired == nil {
+		t.Errorf("expected the cluster CA, got %v %v", line.Desired, err)
+	}
+	if line, err = (&CertificateHandler{}).Make(newCertificateSource(crd, component)); err != nil || line.Desired == nil {
+		t.Errorf("expected the certificate of the cert-manager, got %v %v", line.Desired, err)
+	}
+}
+
+func TestCertificateVisitation(t *testing.T) {
+	component, crd := newTLSComponent()
+	component.Certificate = newComponentCertificate()
+	handler := &CertificateHandler{}
+	line, err := handler.Make(newCertificateSource(crd, component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	observe := func(revision interface{}, recorded string) *unstructured.Unstructured {
+		observed := line.Desired.(*unstructured.Unstructured).DeepCopy()
+		if revision != nil {
+			_ = unstructured.SetNestedField(observed.Object, revision, "status", "revision")
+		}
+		if len(recorded) > 0 {
+			observed.SetAnnotations(map[string]string{CertificateRevisionAnnotation: recorded})
+		}
+		return observed
+	}
+	visit := func(observed *unstructured.Unstructured) *core.ActionCommand {
+		return handler.Visitation(core.ComponentArgs{Observed: observed, Desired: line.Desired})
+	}
+
+	if cmd := visit(observe(nil, "")); cmd != nil {
+		t.Errorf("expected nothing before the certificate is issued, got %v", cmd)
+	}
+	// the first revision is recorded without restart, the pods are waiting for the secret.
+	cmd := visit(observe(int64(1), ""))
+	if cmd == nil || cmd.Action != v1.Update || cmd.Next != nil ||
+		cmd.TargetResource.Target.GetAnnotations()[CertificateRevisionAnnotation] != "1" {
+		t.Errorf("expected the first revision is recorded, got %v", cmd)
+	}
+	if cmd = visit(observe(int64(1), "1")); cmd != nil {
+		t.Errorf("expected nothing when the revision is recorded, got %v", cmd)
+	}
+	// the renewed certificate restarts the pods of the referenced category.
+	cmd = visit(observe(int64(2), "1"))
+	if cmd == nil || cmd.Action != v1.Update || cmd.Next == nil || cmd.Next.Action != v1.Restart || cmd.Next.TargetResource.Category != "redis" {
+		t.Errorf("expected the renewed revision restarts the pods, got %v", cmd)
+	}
+}
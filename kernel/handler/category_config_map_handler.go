@@ -1,25 +1,20 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
 )
 
-func GetConfigMapData(obj core.CategoryComponentObject) map[string]string {
-	data := map[string]string{}
-	for _, c := range obj.Object.([]*v1.NamedProperties) {
-		data[c.Name] = c.Data
-	}
-	return data
-}
-
 // Make make the build-in k8s resource from current component crd
 func (component *ConfigMapComponentHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
 	// Properties which should be provided from real deployed environment.
@@ -30,6 +25,19 @@ func (component *ConfigMapComponentHandler) Make(source core.CustomResource) (*c
 			ResourceMeta: source.ResourceMeta,
 		}, nil
 	}
+	// the templated properties are rendered with the topology of the cluster, then merged on the global conf and validated.
+	merged, err := BuildConfData(source.Crd, meta.Object.(*ConfSource))
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for _, c := range merged {
+		// the invalid file blocks the apply, so that the running conf is kept.
+		if err = ValidateConf(c); err != nil {
+			return nil, err
+		}
+		data[c.Name] = c.Data
+	}
 	configMap := &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
 			Kind: ConfigMap,
@@ -40,11 +48,23 @@ func (component *ConfigMapComponentHandler) Make(source core.CustomResource) (*c
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 			Labels:      Merge(nil, source.Crd.GetLabels()),
-			Annotations: Merge(nil, source.Crd.GetAnnotations()),
+			Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
 		},
-		Data: GetConfigMapData(*meta),
+		Data: data,
 	}
 
+	// the pods load the changed files by the reload policy.
+	policies, err := json.Marshal(GetReloadPolicies(ref, merged))
+	if err != nil {
+		return nil, err
+	}
+	configMap.Annotations[ReloadPolicyAnnotation] = string(policies)
+	// the immutable configMap is replaced by the new revision, the previous revisions are pruned by the history limit.
+	if ref.ImmutableConf != nil {
+		immutable := true
+		configMap.Immutable = &immutable
+		configMap.Labels[ConfRevisionLabel] = GetConfRevision(merged)
+	}
 	configMap.Labels[CategoryLabel] = string(meta.GetCategory())
 	// reference to the category component.
 	configMap.Labels[ReferenceLabel] = string(meta.Reference.GetCategory())
@@ -64,15 +84,64 @@ func (component *ConfigMapComponentHandler) StateFinger(obj client.Object) *v1.C
 }
 
 // PreApply how to action when apply.
+// The pods load the changed files by the strongest reload policy of them, the Restart restarts all the pods of the
+// referenced category, the others are applied by the reload action.
 func (component *ConfigMapComponentHandler) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
 	act, _ := component.CategoryComponentHandler.PreApply(observed, desired)
-	if act.Action == v1.Update {
-		labels := desired.GetLabels()
-		act.Next = GetRestartCommand(desired, labels[ReferenceLabel], 0, fmt.Sprintf("config changed, need restart all the %s pod", labels[ReferenceLabel]))
+	if act.Action != v1.Update {
+		return act, core.Result()
 	}
+	category := desired.GetLabels()[ReferenceLabel]
+	files := changedFiles(observed.(*corev1.ConfigMap).Data, desired.(*corev1.ConfigMap).Data)
+	if len(files) == 0 {
+		return act, core.Result()
+	}
+	policies := parseReloadPolicies(desired)
+	var changed []v1.ReloadPolicy
+	for _, file := range files {
+		policy, ok := policies[file]
+		if !ok {
+			policy = v1.ReloadRestart
+		}
+		changed = append(changed, policy)
+	}
+	policy := StrongestReloadPolicy(changed...)
+	if policy == v1.ReloadRestart {
+		act.Next = GetRestartCommand(desired, category, 0, fmt.Sprintf("config changed, need restart all the %s pod", category))
+		return act, core.Result()
+	}
+	message := fmt.Sprintf("config %s changed, reload the %s pod by the %s policy", strings.Join(files, ","), category, policy)
+	component.Logger().Info(message)
+	act.Next = GetReloadCommand(desired, policy, message)
 	return act, core.Result()
 }
 
+// changedFiles the sorted keys of the configMap which are added, changed or removed.
+func changedFiles(observed, desired map[string]string) []string {
+	var files []string
+	for k, v := range desired {
+		if o, ok := observed[k]; !ok || o != v {
+			files = append(files, k)
+		}
+	}
+	for k := range observed {
+		if _, ok := desired[k]; !ok {
+			files = append(files, k)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// parseReloadPolicies the reload policies of the files recorded by the configMap, the unknown file is restarted.
+func parseReloadPolicies(configMap client.Object) map[string]v1.ReloadPolicy {
+	policies := map[string]v1.ReloadPolicy{}
+	if data, ok := configMap.GetAnnotations()[ReloadPolicyAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &policies)
+	}
+	return policies
+}
+
 // OnEvent make and apply will call it
 func (component *ConfigMapComponentHandler) OnEvent(event extend.Event) error {
 	component.Logger().Info("configMap accept reconcile event", "event", event)
@@ -3,11 +3,11 @@ package handler
 import (
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
-	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -16,7 +16,7 @@ import (
 func (component *CronJobHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
 	// Properties which should be provided from real deployed environment.
 	meta := source.ResourceMeta.(*v1.CategoryClusterMixJob)
-	job := &batchv1beta1.CronJob{
+	job := &batchv1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: source.Crd.GetNamespace(),
 			Name:      string(meta.GetName()),
@@ -28,12 +28,12 @@ func (component *CronJobHandler) Make(source core.CustomResource) (*core.Resourc
 		TypeMeta: metav1.TypeMeta{
 			Kind: CronJob,
 		},
-		Spec: batchv1beta1.CronJobSpec{
+		Spec: batchv1.CronJobSpec{
 			Schedule:                meta.Schedule,
 			StartingDeadlineSeconds: meta.StartingDeadlineSeconds,
 			ConcurrencyPolicy:       meta.ConcurrencyPolicy,
 			Suspend:                 meta.Suspend,
-			JobTemplate: batchv1beta1.JobTemplateSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Namespace: source.Crd.GetNamespace(),
 					Name:      string(meta.GetName()),
@@ -62,7 +62,7 @@ func (component *CronJobHandler) StateFinger(obj client.Object) *v1.ComponentSta
 	if obj == nil {
 		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
 	}
-	job := obj.(*batchv1beta1.CronJob)
+	job := obj.(*batchv1.CronJob)
 	data := map[string]string{}
 	data["Labels"] = fmt.Sprintf("%v", job.Labels)
 	data["Annotation"] = fmt.Sprintf("%v", job.Annotations)
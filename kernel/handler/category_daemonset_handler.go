@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Make make the build-in k8s resource from current component crd
+func (component *DaemonSetClusterComponent) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	// the daemonSet runs one pod per node, the zero replicas means the component is stopped.
+	replicas := getCrd(source).Replicas
+	if replicas == nil || *replicas == 0 {
+		return &core.ResourcesLine{ResourceMeta: source.ResourceMeta}, nil
+	}
+	serviceName := GetComponentShotName(source.Crd.GetName(), v1.Category(getCrd(source).ServiceName))
+	// the rollout is tracked by the operator, so it is always rolling update.
+	strategy := appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType}
+	// build-in daemonSet
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind: string(source.ResourceMeta.GetKind()),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        string(source.ResourceMeta.GetName()),
+			Namespace:   source.Crd.GetNamespace(),
+			Labels:      Merge(source.Crd.GetLabels(), getCrd(source).Labels),
+			Annotations: Merge(InheritedAnnotations(source.Crd.GetAnnotations()), getCrd(source).Annotations),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(source)},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Template:             makePodTemplate(source, serviceName),
+			Selector:             getCrd(source).Selector,
+			UpdateStrategy:       strategy,
+			RevisionHistoryLimit: getCrd(source).RevisionHistoryLimit,
+		},
+	}
+
+	//com label
+	daemonSet.Labels[InstanceLabel] = source.Crd.GetName()
+	daemonSet.Labels[CategoryLabel] = string(getCrd(source).GetCategory())
+
+	return &core.ResourcesLine{
+		Desired:      daemonSet,
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger the crd state
+func (component *DaemonSetClusterComponent) StateFinger(obj client.Object) *v1.ComponentState {
+	data := map[string]string{}
+
+	if obj == nil {
+		return v1.NewComponentState(v1.Deleted, "Deleted", data)
+	}
+	ds := obj.(*appsv1.DaemonSet)
+
+	if ds.Spec.Selector != nil {
+		data["Selector"] = fmt.Sprintf("%v", *ds.Spec.Selector)
+	}
+	data["Annotations"] = fmt.Sprintf("%v", ds.Spec.Template.Annotations)
+	data["Labels"] = fmt.Sprintf("%v", ds.Spec.Template.Labels)
+	data["Template"] = fmt.Sprintf("%v", PodSpecFinger(ds.Spec.Template.Spec))
+	data["UpdateStrategy"] = fmt.Sprintf("%v", ds.Spec.UpdateStrategy.Type)
+	if ds.Spec.RevisionHistoryLimit != nil {
+		data["RevisionHistoryLimit"] = fmt.Sprintf("%v", *ds.Spec.RevisionHistoryLimit)
+	}
+	data["MinReadySeconds"] = fmt.Sprintf("%v", ds.Spec.MinReadySeconds)
+
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
+
+// RecreateCheck the selector of the daemonSet is immutable, it need recreate when changed.
+func (component *DaemonSetClusterComponent) RecreateCheck(observed client.Object, desired client.Object) *core.ActionCommand {
+	if observed == nil || desired == nil {
+		return nil
+	}
+	return selectorRecreateCheck(component.Logger(), observed.(*appsv1.DaemonSet).Spec.Selector, desired.(*appsv1.DaemonSet).Spec.Selector, desired)
+}
+
+// PreApply how to action when apply.
+// The pod template change is rolled out node by node by the daemonSet controller.
+func (component *DaemonSetClusterComponent) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
+	act, _ := component.CategoryComponentHandler.PreApply(observed, desired)
+	if act.Action == v1.Update {
+		// if the DaemonSet need recreate, ignore the update action.
+		if recreate := component.RecreateCheck(observed, desired); recreate != nil {
+			act = recreate
+		}
+	}
+	return act, core.Result()
+}
+
+// OnEvent make and apply will call it.
+func (component *DaemonSetClusterComponent) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	. "github.com/kuberator/kernel/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newDaemonSetSource() core.CustomResource {
+	replicas := int32(1)
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{
+			Name: "demo-agent", Category: "agent", Component: v1.Component{Kind: DaemonSet},
+		},
+		Replicas:    &replicas,
+		ServiceName: "agent-svc",
+		// the daemonSet is always rolled by the controller.
+		UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+		Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{CategoryLabel: "agent"}},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "agent"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent", Image: "agent:1.0"}}},
+		},
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"},
+		Spec:       v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}},
+	}
+	return core.CustomResource{ResourceMeta: component, Crd: crd}
+}
+
+func TestDaemonSetMake(t *testing.T) {
+	handler := &DaemonSetClusterComponent{}
+	line, err := handler.Make(newDaemonSetSource())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, ok := line.Desired.(*appsv1.DaemonSet)
+	if !ok {
+		t.Fatalf("expected the daemonSet, got %T", line.Desired)
+	}
+	if ds.Name != "demo-agent" || ds.Namespace != "ns" || ds.Labels[InstanceLabel] != "demo" || ds.Labels[CategoryLabel] != "agent" {
+		t.Errorf("unexpected daemonSet %s/%s %v", ds.Namespace, ds.Name, ds.Labels)
+	}
+	if len(ds.OwnerReferences) != 1 || ds.OwnerReferences[0].UID != "demo" {
+		t.Errorf("expected the daemonSet is owned by the cluster, got %v", ds.OwnerReferences)
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		t.Errorf("expected the rolling update strategy, got %s", ds.Spec.UpdateStrategy.Type)
+	}
+
+	// the pod template is the same as the other workloads.
+	template := ds.Spec.Template
+	if template.Labels[InstanceLabel] != "demo" || template.Labels[CategoryLabel] != "agent" || template.Labels["app"] != "agent" {
+		t.Errorf("expected the pod labels, got %v", template.Labels)
+	}
+	if len(template.Spec.Containers) != 1 || template.Spec.Containers[0].Image != "agent:1.0" {
+		t.Fatalf("expected the containers of the component, got %v", template.Spec.Containers)
+	}
+	envs := map[string]bool{}
+	for _, env := range template.Spec.Containers[0].Env {
+		envs[env.Name] = true
+	}
+	if !envs[Namespace] || !envs[NodeName] {
+		t.Errorf("expected the default envs are injected, got %v", template.Spec.Containers[0].Env)
+	}
+
+	source := newDaemonSetSource()
+	zero := int32(0)
+	source.ResourceMeta.(*v1.CategoryClusterComponent).Replicas = &zero
+	if line, err = handler.Make(source); err != nil || line.Desired != nil {
+		t.Errorf("expected nothing is desired when the component is stopped, got %v %v", line.Desired, err)
+	}
+}
+
+func TestDaemonSetStateFinger(t *testing.T) {
+	handler := &DaemonSetClusterComponent{}
+	line, err := handler.Make(newDaemonSetSource())
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := line.Desired.(*appsv1.DaemonSet)
+	state := handler.StateFinger(desired)
+	if state.State != v1.Success {
+		t.Errorf("expected the success state, got %s", state.State)
+	}
+
+	// the status is not fingered, only the template changes the finger.
+	observed := desired.DeepCopy()
+	observed.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 1}
+	if handler.StateFinger(observed).Meta != state.Meta {
+		t.Errorf("expected the status is not fingered")
+	}
+	observed.Spec.Template.Spec.Containers[0].Image = "agent:2.0"
+	if handler.StateFinger(observed).Meta == state.Meta {
+		t.Errorf("expected the template change is fingered")
+	}
+	if handler.StateFinger(nil).State != v1.Deleted {
+		t.Errorf("expected the missing daemonSet is deleted")
+	}
+}
+
+func TestDaemonSetReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		status appsv1.DaemonSetStatus
+		ready  bool
+	}{
+		{name: "ready", ready: true, status: appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3}},
+		{name: "unavailable", status: appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3, NumberAvailable: 2, NumberUnavailable: 1}},
+		{name: "rolling", status: appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3, UpdatedNumberScheduled: 1, NumberReady: 3, NumberAvailable: 3}},
+		{name: "not observed", status: appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3, NumberAvailable: 3}},
+	}
+	for _, tt := range tests {
+		ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: tt.status}
+		if ready, ok := IsWorkloadReady(ds); !ok || ready != tt.ready {
+			t.Errorf("expected the %s daemonSet is ready %t, got %t", tt.name, tt.ready, ready)
+		}
+		workload := GetWorkloadStatus(ds)
+		if workload == nil || workload.Desired != 3 || workload.Available != tt.status.NumberAvailable || workload.Updated != tt.status.UpdatedNumberScheduled {
+			t.Errorf("expected the pod counts of the %s daemonSet, got %v", tt.name, workload)
+		}
+	}
+}
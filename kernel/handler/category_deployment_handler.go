@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Make make the build-in k8s resource from current component crd
+func (component *DeploymentClusterComponent) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	replicas := getCrd(source).Replicas
+	if replicas == nil || *replicas == 0 {
+		return &core.ResourcesLine{ResourceMeta: source.ResourceMeta}, nil
+	}
+	serviceName := GetComponentShotName(source.Crd.GetName(), v1.Category(getCrd(source).ServiceName))
+	// the pods are rolled by the deployment controller, OnDelete is not supported.
+	strategy := appsv1.DeploymentStrategy{Type: appsv1.DeploymentStrategyType(getCrd(source).UpdateStrategy.Type)}
+	if strategy.Type != appsv1.RecreateDeploymentStrategyType {
+		strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	}
+	// build-in deployment
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind: string(source.ResourceMeta.GetKind()),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        string(source.ResourceMeta.GetName()),
+			Namespace:   source.Crd.GetNamespace(),
+			Labels:      Merge(source.Crd.GetLabels(), getCrd(source).Labels),
+			Annotations: Merge(InheritedAnnotations(source.Crd.GetAnnotations()), getCrd(source).Annotations),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(source)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas:             replicas,
+			Template:             makePodTemplate(source, serviceName),
+			Selector:             getCrd(source).Selector,
+			Strategy:             strategy,
+			RevisionHistoryLimit: getCrd(source).RevisionHistoryLimit,
+		},
+	}
+
+	//com label
+	deployment.Labels[InstanceLabel] = source.Crd.GetName()
+	deployment.Labels[CategoryLabel] = string(getCrd(source).GetCategory())
+
+	return &core.ResourcesLine{
+		Desired:      deployment,
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger the crd state
+func (component *DeploymentClusterComponent) StateFinger(obj client.Object) *v1.ComponentState {
+	data := map[string]string{}
+
+	if obj == nil {
+		return v1.NewComponentState(v1.Deleted, "Deleted", data)
+	}
+	deploy := obj.(*appsv1.Deployment)
+
+	if deploy.Spec.Replicas != nil {
+		data["Replicas"] = fmt.Sprintf("%v", *deploy.Spec.Replicas)
+	}
+	if deploy.Spec.Selector != nil {
+		data["Selector"] = fmt.Sprintf("%v", *deploy.Spec.Selector)
+	}
+	data["Annotations"] = fmt.Sprintf("%v", deploy.Spec.Template.Annotations)
+	data["Labels"] = fmt.Sprintf("%v", deploy.Spec.Template.Labels)
+	data["Template"] = fmt.Sprintf("%v", PodSpecFinger(deploy.Spec.Template.Spec))
+	data["Strategy"] = fmt.Sprintf("%v", deploy.Spec.Strategy.Type)
+	if deploy.Spec.RevisionHistoryLimit != nil {
+		data["RevisionHistoryLimit"] = fmt.Sprintf("%v", *deploy.Spec.RevisionHistoryLimit)
+	}
+	data["MinReadySeconds"] = fmt.Sprintf("%v", deploy.Spec.MinReadySeconds)
+
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
+
+// RecreateCheck the selector of the deployment is immutable, it need recreate when changed.
+func (component *DeploymentClusterComponent) RecreateCheck(observed client.Object, desired client.Object) *core.ActionCommand {
+	if observed == nil || desired == nil {
+		return nil
+	}
+	return selectorRecreateCheck(component.Logger(), observed.(*appsv1.Deployment).Spec.Selector, desired.(*appsv1.Deployment).Spec.Selector, desired)
+}
+
+// PreApply how to action when apply.
+// The pod template change is rolled out by the deployment controller, so no restart is needed.
+func (component *DeploymentClusterComponent) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
+	act, _ := component.CategoryComponentHandler.PreApply(observed, desired)
+	if act.Action == v1.Update {
+		// if the Deployment need recreate, ignore the update action.
+		if recreate := component.RecreateCheck(observed, desired); recreate != nil {
+			act = recreate
+		}
+	}
+	return act, core.Result()
+}
+
+// OnEvent make and apply will call it.
+func (component *DeploymentClusterComponent) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newDeploymentSource(strategy appsv1.StatefulSetUpdateStrategyType) core.CustomResource {
+	replicas := int32(2)
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{
+			Name: "demo-proxy", Category: "proxy", Component: v1.Component{Kind: Deployment},
+		},
+		Replicas:       &replicas,
+		UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: strategy},
+		Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{CategoryLabel: "proxy"}},
+		Template:       corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "proxy", Image: "proxy:1.0"}}}},
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo",
+			Annotations: map[string]string{v1.RotateCredentialsAnnotation: "1", "team": "storage"}},
+		Spec: v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}},
+	}
+	return core.CustomResource{ResourceMeta: component, Crd: crd}
+}
+
+func TestDeploymentMake(t *testing.T) {
+	handler := &DeploymentClusterComponent{}
+	line, err := handler.Make(newDeploymentSource(appsv1.RollingUpdateStatefulSetStrategyType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deploy, ok := line.Desired.(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected the deployment, got %T", line.Desired)
+	}
+	if deploy.Name != "demo-proxy" || deploy.Namespace != "ns" || *deploy.Spec.Replicas != 2 {
+		t.Errorf("unexpected deployment %s/%s of %d replicas", deploy.Namespace, deploy.Name, *deploy.Spec.Replicas)
+	}
+	if deploy.Labels[InstanceLabel] != "demo" || deploy.Labels[CategoryLabel] != "proxy" {
+		t.Errorf("expected the instance and category labels, got %v", deploy.Labels)
+	}
+	if deploy.Spec.Template.Labels[InstanceLabel] != "demo" || deploy.Spec.Template.Labels[CategoryLabel] != "proxy" {
+		t.Errorf("expected the pod labels, got %v", deploy.Spec.Template.Labels)
+	}
+	if len(deploy.OwnerReferences) != 1 || deploy.OwnerReferences[0].UID != "demo" {
+		t.Errorf("expected the deployment is owned by the cluster, got %v", deploy.OwnerReferences)
+	}
+	if _, ok := deploy.Annotations[v1.RotateCredentialsAnnotation]; ok || deploy.Annotations["team"] != "storage" {
+		t.Errorf("expected only the user annotations are inherited, got %v", deploy.Annotations)
+	}
+	if deploy.Spec.Selector.MatchLabels[CategoryLabel] != "proxy" || len(deploy.Spec.Template.Spec.Containers) != 1 {
+		t.Errorf("expected the selector and the containers of the component, got %v", deploy.Spec)
+	}
+
+	source := newDeploymentSource("")
+	zero := int32(0)
+	source.ResourceMeta.(*v1.CategoryClusterComponent).Replicas = &zero
+	if line, err = handler.Make(source); err != nil || line.Desired != nil {
+		t.Errorf("expected nothing is desired without replicas, got %v %v", line.Desired, err)
+	}
+}
+
+func TestDeploymentStrategy(t *testing.T) {
+	cases := map[appsv1.StatefulSetUpdateStrategyType]appsv1.DeploymentStrategyType{
+		"": appsv1.RollingUpdateDeploymentStrategyType,
+		appsv1.RollingUpdateStatefulSetStrategyType: appsv1.RollingUpdateDeploymentStrategyType,
+		// the pods are rolled by the deployment controller, OnDelete is not supported.
+		appsv1.OnDeleteStatefulSetStrategyType:           appsv1.RollingUpdateDeploymentStrategyType,
+		appsv1.StatefulSetUpdateStrategyType("Recreate"): appsv1.RecreateDeploymentStrategyType,
+	}
+	for strategy, expected := range cases {
+		line, err := (&DeploymentClusterComponent{}).Make(newDeploymentSource(strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := line.Desired.(*appsv1.Deployment).Spec.Strategy.Type; got != expected {
+			t.Errorf("expected the %q strategy is mapped to %s, got %s", strategy, expected, got)
+		}
+	}
+}
+
+func TestDeploymentPreApply(t *testing.T) {
+	handler := &DeploymentClusterComponent{}
+	line, err := handler.Make(newDeploymentSource(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := line.Desired.(*appsv1.Deployment)
+	observed := desired.DeepCopy()
+	observed.ResourceVersion = "7"
+	observed.UID = "proxy"
+
+	if act, _ := handler.PreApply(nil, desired.DeepCopy()); act.Action != v1.Create {
+		t.Errorf("expected the missing deployment is created, got %s", act.Action)
+	}
+	act, result := handler.PreApply(observed, desired.DeepCopy())
+	if act.Action != v1.Update || result.IsError() || act.Next != nil {
+		t.Fatalf("expected only the update, the template change is rolled by the controller, got %s", act.Action)
+	}
+	if act.TargetResource.Target.GetResourceVersion() != "7" || act.TargetResource.Target.GetUID() != "proxy" {
+		t.Errorf("expected the update carries the observed version")
+	}
+
+	// the selector is immutable, the deployment is recreated.
+	changed := desired.DeepCopy()
+	changed.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{CategoryLabel: "gateway"}}
+	if act, _ = handler.PreApply(observed, changed); act.Action != v1.ReCreate || act.TargetResource.Category != "proxy" {
+		t.Errorf("expected the deployment of the changed selector is recreated, got %s", act.Action)
+	}
+	if act, _ = handler.PreApply(observed, nil); act.Action != v1.Delete {
+		t.Errorf("expected the deployment removed from the spec is deleted, got %s", act.Action)
+	}
+}
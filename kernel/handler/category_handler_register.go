@@ -1,25 +1,61 @@
 package handler
 
 import (
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/api/autoscaling/v2beta2"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
-	extensionsv1beat1 "k8s.io/api/extensions/v1beta1"
-	"k8s.io/api/policy/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func init() {
 	Inject(StatefulSet, StatefulSetClusterComponent{}, appsv1.StatefulSet{}, appsv1.StatefulSetList{})
+	Inject(Deployment, DeploymentClusterComponent{}, appsv1.Deployment{}, appsv1.DeploymentList{})
+	Inject(DaemonSet, DaemonSetClusterComponent{}, appsv1.DaemonSet{}, appsv1.DaemonSetList{})
 	Inject(Service, ServiceComponentHandler{}, corev1.Service{}, corev1.ServiceList{})
 	Inject(ConfigMap, ConfigMapComponentHandler{}, corev1.ConfigMap{}, corev1.ConfigMapList{})
-	Inject(Ingress, IngressComponentHandler{}, extensionsv1beat1.Ingress{}, extensionsv1beat1.IngressList{})
-	Inject(PodDisruptionBudget, PodDisruptionBudgetHandler{}, v1beta1.PodDisruptionBudget{}, v1beta1.PodDisruptionBudgetList{})
+	Inject(Ingress, IngressComponentHandler{}, networkingv1.Ingress{}, networkingv1.IngressList{})
+	Inject(PodDisruptionBudget, PodDisruptionBudgetHandler{}, policyv1.PodDisruptionBudget{}, policyv1.PodDisruptionBudgetList{})
 	Inject(PersistentVolumeClaim, PersistentVolumeClaimHandler{}, corev1.PersistentVolumeClaim{}, corev1.PersistentVolumeClaimList{})
 	Inject(Secret, SecretHandler{}, corev1.Secret{}, corev1.SecretList{})
-	Inject(HorizontalPodAutoscaler, HorizontalPodAutoscalerHandler{}, v2beta2.HorizontalPodAutoscaler{}, v2beta2.HorizontalPodAutoscalerList{})
-	Inject(CronJob, CronJobHandler{}, batchv1beta1.CronJob{}, batchv1beta1.CronJobList{})
+	Inject(TLSSecret, TLSSecretHandler{}, corev1.Secret{}, corev1.SecretList{})
+	Inject(HorizontalPodAutoscaler, HorizontalPodAutoscalerHandler{}, autoscalingv2.HorizontalPodAutoscaler{}, autoscalingv2.HorizontalPodAutoscalerList{})
+	Inject(NetworkPolicy, NetworkPolicyHandler{}, networkingv1.NetworkPolicy{}, networkingv1.NetworkPolicyList{})
+	InjectUnstructured(ServiceMonitor, MonitorHandler{}, monitoringGroupVersion.WithKind(ServiceMonitor))
+	InjectUnstructured(PodMonitor, MonitorHandler{}, monitoringGroupVersion.WithKind(PodMonitor))
+	InjectUnstructured(PrometheusRule, PrometheusRuleHandler{}, monitoringGroupVersion.WithKind(PrometheusRule))
+	InjectUnstructured(Certificate, CertificateHandler{}, certManagerGroupVersion.WithKind(Certificate))
+	Inject(CronJob, CronJobHandler{}, batchv1.CronJob{}, batchv1.CronJobList{})
 	Inject(Job, JobHandler{}, batchv1.Job{}, batchv1.JobList{})
+
+	// the legacy versions are used when the cluster does not serve the current one.
+	InjectLegacy(Ingress, networkingv1beta1.Ingress{}, networkingv1beta1.IngressList{}, LegacyConverter{
+		ToLegacy: func(current client.Object) (client.Object, error) {
+			ingress := current.(*networkingv1.Ingress)
+			return &networkingv1beta1.Ingress{
+				TypeMeta:   ingress.TypeMeta,
+				ObjectMeta: ingress.ObjectMeta,
+				Spec:       v1.ToIngressSpecV1beta1(ingress.Spec),
+			}, nil
+		},
+		FromLegacy: func(legacy client.Object) (client.Object, error) {
+			ingress := legacy.(*networkingv1beta1.Ingress)
+			return &networkingv1.Ingress{
+				TypeMeta:   ingress.TypeMeta,
+				ObjectMeta: ingress.ObjectMeta,
+				Spec:       v1.FromIngressSpecV1beta1(ingress.Spec),
+			}, nil
+		},
+	})
+	InjectLegacy(PodDisruptionBudget, policyv1beta1.PodDisruptionBudget{}, policyv1beta1.PodDisruptionBudgetList{}, LegacyConverter{})
+	InjectLegacy(HorizontalPodAutoscaler, v2beta2.HorizontalPodAutoscaler{}, v2beta2.HorizontalPodAutoscalerList{}, LegacyConverter{})
+	InjectLegacy(CronJob, batchv1beta1.CronJob{}, batchv1beta1.CronJobList{}, LegacyConverter{})
 }
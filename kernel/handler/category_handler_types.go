@@ -7,6 +7,14 @@ type (
 		CategoryComponentHandler
 	}
 
+	DeploymentClusterComponent struct {
+		CategoryComponentHandler
+	}
+
+	DaemonSetClusterComponent struct {
+		CategoryComponentHandler
+	}
+
 	ConfigMapComponentHandler struct {
 		CategoryComponentHandler
 	}
@@ -31,10 +39,30 @@ type (
 		CategoryComponentHandler
 	}
 
+	TLSSecretHandler struct {
+		CategoryComponentHandler
+	}
+
+	CertificateHandler struct {
+		CategoryComponentHandler
+	}
+
 	HorizontalPodAutoscalerHandler struct {
 		CategoryComponentHandler
 	}
 
+	NetworkPolicyHandler struct {
+		CategoryComponentHandler
+	}
+
+	MonitorHandler struct {
+		CategoryComponentHandler
+	}
+
+	PrometheusRuleHandler struct {
+		CategoryComponentHandler
+	}
+
 	CronJobHandler struct {
 		CategoryComponentHandler
 	}
@@ -2,11 +2,11 @@ package handler
 
 import (
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
-	"k8s.io/api/autoscaling/v2beta2"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -22,19 +22,19 @@ func (component *HorizontalPodAutoscalerHandler) Make(source core.CustomResource
 		}, nil
 	}
 
-	hpa := &v2beta2.HorizontalPodAutoscaler{
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: source.Crd.GetNamespace(),
 			Name:      string(meta.GetName()),
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 			Labels:      Merge(nil, source.Crd.GetLabels()),
-			Annotations: Merge(nil, source.Crd.GetAnnotations()),
+			Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
 		},
 		TypeMeta: metav1.TypeMeta{
 			Kind: HorizontalPodAutoscaler,
 		},
-		Spec: v2beta2.HorizontalPodAutoscalerSpec{
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
 			MaxReplicas: *ref.MaxReplicas,
 			MinReplicas: ref.Replicas,
 			Metrics:     ref.Metrics,
@@ -54,7 +54,7 @@ func (component *HorizontalPodAutoscalerHandler) StateFinger(obj client.Object)
 	if obj == nil {
 		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
 	}
-	hpa := obj.(*v2beta2.HorizontalPodAutoscaler)
+	hpa := obj.(*autoscalingv2.HorizontalPodAutoscaler)
 	data := map[string]string{}
 	data["spec"] = hpa.Spec.String()
 	return v1.NewComponentState(v1.Success, "ok", data)
@@ -2,18 +2,18 @@ package handler
 
 import (
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
-	"k8s.io/api/networking/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Make make the build-in k8s resource from current component crd
 func (component *IngressComponentHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
-	ingress := &v1beta1.Ingress{
+	ingress := &networkingv1.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			Kind: string(source.ResourceMeta.GetKind()),
 		},
@@ -21,7 +21,7 @@ func (component *IngressComponentHandler) Make(source core.CustomResource) (*cor
 			Name:        string(source.ResourceMeta.GetName()),
 			Namespace:   source.Crd.GetNamespace(),
 			Labels:      Merge(source.ResourceMeta.(*v1.CategoryClusterIngress).Labels, source.Crd.GetLabels()),
-			Annotations: Merge(source.ResourceMeta.(*v1.CategoryClusterIngress).Annotations, source.Crd.GetAnnotations()),
+			Annotations: Merge(source.ResourceMeta.(*v1.CategoryClusterIngress).Annotations, InheritedAnnotations(source.Crd.GetAnnotations())),
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 		},
@@ -40,7 +40,7 @@ func (component *IngressComponentHandler) StateFinger(obj client.Object) *v1.Com
 	if obj == nil {
 		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
 	}
-	is := obj.(*v1beta1.Ingress)
+	is := obj.(*networkingv1.Ingress)
 	data := map[string]string{}
 	data["spec"] = is.Spec.String()
 	return v1.NewComponentState(v1.Success, "ok", data)
@@ -3,7 +3,7 @@ package handler
 import (
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// monitoringGroupVersion the api version of the prometheus operator, its types are built as the unstructured object.
+var monitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+// newMonitoringObject the unstructured prometheus operator object with the owner reference and the labels.
+// The nested values should be the json types, or the deep copy of the unstructured object panics.
+func newMonitoringObject(source core.CustomResource, ref *v1.CategoryClusterComponent, spec map[string]interface{}) *unstructured.Unstructured {
+	meta := source.ResourceMeta
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	obj.SetGroupVersionKind(monitoringGroupVersion.WithKind(string(meta.GetKind())))
+	obj.SetName(string(meta.GetName()))
+	obj.SetNamespace(source.Crd.GetNamespace())
+	obj.SetLabels(Merge(Merge(source.Crd.GetLabels(), GetReferenceLabels(ref, meta.GetKind())), ref.Monitoring.Labels))
+	obj.SetAnnotations(InheritedAnnotations(source.Crd.GetAnnotations()))
+	obj.SetOwnerReferences([]metav1.OwnerReference{ToOwnerReference(source)})
+	return obj
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	target := map[string]interface{}{}
+	for k, v := range m {
+		target[k] = v
+	}
+	return target
+}
+
+func putIfNotEmpty(target map[string]interface{}, key, value string) {
+	if len(value) > 0 {
+		target[key] = value
+	}
+}
+
+func monitorEndpoints(endpoints []v1.MonitorEndpoint) []interface{} {
+	var target []interface{}
+	for _, e := range endpoints {
+		endpoint := map[string]interface{}{"port": e.Port}
+		putIfNotEmpty(endpoint, "path", e.Path)
+		putIfNotEmpty(endpoint, "scheme", e.Scheme)
+		putIfNotEmpty(endpoint, "interval", e.Interval)
+		target = append(target, endpoint)
+	}
+	return target
+}
+
+// Make make the ServiceMonitor or PodMonitor from the component monitoring.
+// The ServiceMonitor selects the services of the component category, it is skipped when there is no service.
+func (component *MonitorHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	meta := source.ResourceMeta.(*core.CategoryComponentObject)
+	ref := meta.Reference.(*v1.CategoryClusterComponent)
+	if ref == nil || ref.Monitoring == nil || ref.Monitoring.Kind != meta.GetKind() {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+
+	var spec map[string]interface{}
+	if meta.GetKind() == PodMonitor {
+		spec = map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{
+					InstanceLabel: source.Crd.GetName(),
+					CategoryLabel: string(ref.GetCategory()),
+				},
+			},
+			"podMetricsEndpoints": monitorEndpoints(ref.Monitoring.Endpoints),
+		}
+	} else {
+		var categories []string
+		for _, svc := range ComponentServices(source.Crd, ref) {
+			categories = append(categories, string(svc.GetCategory()))
+		}
+		if len(categories) == 0 {
+			component.Logger().Info("no service of the component, skip the service monitor", "category", ref.GetCategory())
+			return &core.ResourcesLine{
+				ResourceMeta: source.ResourceMeta,
+			}, nil
+		}
+		sort.Strings(categories)
+		values := make([]interface{}, len(categories))
+		for i, c := range categories {
+			values[i] = c
+		}
+		spec = map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{InstanceLabel: source.Crd.GetName()},
+				"matchExpressions": []interface{}{
+					map[string]interface{}{"key": CategoryLabel, "operator": "In", "values": values},
+				},
+			},
+			"namespaceSelector": map[string]interface{}{
+				"matchNames": []interface{}{source.Crd.GetNamespace()},
+			},
+			"endpoints": monitorEndpoints(ref.Monitoring.Endpoints),
+		}
+	}
+
+	return &core.ResourcesLine{
+		Desired:      newMonitoringObject(source, ref, spec),
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger convert category state to component state
+func (component *MonitorHandler) StateFinger(obj client.Object) *v1.ComponentState {
+	return unstructuredStateFinger(obj)
+}
+
+// OnEvent make and apply will call it.
+func (component *MonitorHandler) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
+
+// Make make the PrometheusRule from the alert rules of the component monitoring.
+func (component *PrometheusRuleHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	meta := source.ResourceMeta.(*core.CategoryComponentObject)
+	ref := meta.Reference.(*v1.CategoryClusterComponent)
+	if ref == nil || ref.Monitoring == nil || len(ref.Monitoring.RuleGroups) == 0 {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+
+	var groups []interface{}
+	for _, g := range ref.Monitoring.RuleGroups {
+		var rules []interface{}
+		for _, r := range g.Rules {
+			rule := map[string]interface{}{"alert": r.Alert, "expr": r.Expr}
+			putIfNotEmpty(rule, "for", r.For)
+			if len(r.Labels) > 0 {
+				rule["labels"] = toInterfaceMap(r.Labels)
+			}
+			if len(r.Annotations) > 0 {
+				rule["annotations"] = toInterfaceMap(r.Annotations)
+			}
+			rules = append(rules, rule)
+		}
+		group := map[string]interface{}{"name": g.Name, "rules": rules}
+		putIfNotEmpty(group, "interval", g.Interval)
+		groups = append(groups, group)
+	}
+
+	return &core.ResourcesLine{
+		Desired:      newMonitoringObject(source, ref, map[string]interface{}{"groups": groups}),
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger convert category state to component state
+func (component *PrometheusRuleHandler) StateFinger(obj client.Object) *v1.ComponentState {
+	return unstructuredStateFinger(obj)
+}
+
+// OnEvent make and apply will call it.
+func (component *PrometheusRuleHandler) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
+
+// unstructuredStateFinger the spec and the labels of the unstructured object, the status is not fingered.
+func unstructuredStateFinger(obj client.Object) *v1.ComponentState {
+	if obj == nil {
+		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
+	}
+	u := obj.(*unstructured.Unstructured)
+	data := map[string]string{}
+	data["spec"] = fmt.Sprintf("%v", u.Object["spec"])
+	data["Labels"] = fmt.Sprintf("%v", u.GetLabels())
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newMonitorSource(kind v1.ComponentKind, monitoring *v1.ComponentMonitoring, services ...*v1.CategoryClusterService) core.CustomResource {
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis", Category: "redis"},
+		ServiceName:             "redis-svc",
+		Monitoring:              monitoring,
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo", Labels: map[string]string{"team": "storage"}},
+		Spec:       v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}, Service: services},
+	}
+	return core.CustomResource{
+		ResourceMeta: &core.CategoryComponentObject{
+			CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name: v1.ComponentName("demo-redis-" + lower(kind)), Category: v1.Category("redis-" + lower(kind)), Component: v1.Component{Kind: kind},
+			},
+			Reference: component,
+		},
+		Crd: crd,
+	}
+}
+
+func lower(kind v1.ComponentKind) string {
+	return strings.ToLower(string(kind))
+}
+
+// assertMonitoringObject the common metadata of the prometheus operator object.
+func assertMonitoringObject(t *testing.T, line *core.ResourcesLine, kind v1.ComponentKind) *unstructured.Unstructured {
+	t.Helper()
+	obj, ok := line.Desired.(*unstructured.Unstructured)
+	if !ok {
+		t.Fatalf("expected the unstructured %s, got %T", kind, line.Desired)
+	}
+	gvk := schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: string(kind)}
+	if obj.GroupVersionKind() != gvk {
+		t.Errorf("expected the %v, got %v", gvk, obj.GroupVersionKind())
+	}
+	if obj.GetName() != "demo-redis-"+lower(kind) || obj.GetNamespace() != "ns" {
+		t.Errorf("unexpected %s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+	}
+	labels := obj.GetLabels()
+	if labels["team"] != "storage" || labels["release"] != "prometheus" || labels[CategoryLabel] != "redis-"+lower(kind) || labels[ReferenceLabel] != "redis" {
+		t.Errorf("expected the cluster, reference and monitoring labels, got %v", labels)
+	}
+	if refs := obj.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != "demo" {
+		t.Errorf("expected the %s is owned by the cluster, got %v", kind, refs)
+	}
+	// the nested values are the json types, the deep copy panics otherwise.
+	obj.DeepCopy()
+	return obj
+}
+
+func TestServiceMonitorMake(t *testing.T) {
+	monitoring := &v1.ComponentMonitoring{
+		Kind:   ServiceMonitor,
+		Labels: map[string]string{"release": "prometheus"},
+		Endpoints: []v1.MonitorEndpoint{
+			{Port: "metrics"},
+			{Port: "exporter", Path: "/probe", Scheme: "https", Interval: "30s"},
+		},
+	}
+	services := []*v1.CategoryClusterService{
+		{CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis-svc"}},
+		{CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis-metrics"},
+			ServiceSpec: corev1.ServiceSpec{Selector: map[string]string{CategoryLabel: "redis"}}},
+		{CommonCategoryComponent: v1.CommonCategoryComponent{Category: "sentinel-svc"},
+			ServiceSpec: corev1.ServiceSpec{Selector: map[string]string{CategoryLabel: "sentinel"}}},
+	}
+	handler := &MonitorHandler{}
+	line, err := handler.Make(newMonitorSource(ServiceMonitor, monitoring, services...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := assertMonitoringObject(t, line, ServiceMonitor)
+
+	matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+	if !reflect.DeepEqual(matchLabels, map[string]string{InstanceLabel: "demo"}) {
+		t.Errorf("expected the services of the cluster are selected, got %v", matchLabels)
+	}
+	expressions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "selector", "matchExpressions")
+	expected := []interface{}{map[string]interface{}{"key": CategoryLabel, "operator": "In", "values": []interface{}{"redis-metrics", "redis-svc"}}}
+	if !reflect.DeepEqual(expressions, expected) {
+		t.Errorf("expected the sorted service categories of the component, got %v", expressions)
+	}
+	namespaces, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "namespaceSelector", "matchNames")
+	if !reflect.DeepEqual(namespaces, []string{"ns"}) {
+		t.Errorf("expected the namespace of the cluster, got %v", namespaces)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+	expected = []interface{}{
+		map[string]interface{}{"port": "metrics"},
+		map[string]interface{}{"port": "exporter", "path": "/probe", "scheme": "https", "interval": "30s"},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("expected the endpoints %v, got %v", expected, endpoints)
+	}
+
+	// the service monitor is skipped without the service of the component.
+	if line, err = handler.Make(newMonitorSource(ServiceMonitor, monitoring, services[2])); err != nil || line.Desired != nil {
+		t.Errorf("expected no service monitor without the service, got %v %v", line.Desired, err)
+	}
+}
+
+func TestPodMonitorMake(t *testing.T) {
+	monitoring := &v1.ComponentMonitoring{
+		Kind:      PodMonitor,
+		Labels:    map[string]string{"release": "prometheus"},
+		Endpoints: []v1.MonitorEndpoint{{Port: "metrics", Path: "/metrics"}},
+	}
+	handler := &MonitorHandler{}
+	line, err := handler.Make(newMonitorSource(PodMonitor, monitoring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := assertMonitoringObject(t, line, PodMonitor)
+	matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+	if !reflect.DeepEqual(matchLabels, map[string]string{InstanceLabel: "demo", CategoryLabel: "redis"}) {
+		t.Errorf("expected the pods of the category are selected, got %v", matchLabels)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(obj.Object, "spec", "podMetricsEndpoints")
+	if !reflect.DeepEqual(endpoints, []interface{}{map[string]interface{}{"port": "metrics", "path": "/metrics"}}) {
+		t.Errorf("expected the pod metrics endpoints, got %v", endpoints)
+	}
+
+	// only the monitor of the configured kind is made.
+	if line, err = handler.Make(newMonitorSource(ServiceMonitor, monitoring)); err != nil || line.Desired != nil {
+		t.Errorf("expected no service monitor when the pod monitor is configured, got %v %v", line.Desired, err)
+	}
+	if line, err = handler.Make(newMonitorSource(PodMonitor, nil)); err != nil || line.Desired != nil {
+		t.Errorf("expected no pod monitor without the monitoring, got %v %v", line.Desired, err)
+	}
+}
+
+func TestPrometheusRuleMake(t *testing.T) {
+	monitoring := &v1.ComponentMonitoring{
+		Labels: map[string]string{"release": "prometheus"},
+		RuleGroups: []v1.MonitorRuleGroup{{
+			Name:     "redis",
+			Interval: "1m",
+			Rules: []v1.MonitorRule{
+				{Alert: "RedisDown", Expr: "redis_up == 0", For: "5m",
+					Labels: map[string]string{"severity": "critical"}, Annotations: map[string]string{"summary": "redis is down"}},
+				{Alert: "RedisMemoryHigh", Expr: "redis_memory_used_bytes > 1e9"},
+			},
+		}},
+	}
+	handler := &PrometheusRuleHandler{}
+	line, err := handler.Make(newMonitorSource(PrometheusRule, monitoring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := assertMonitoringObject(t, line, PrometheusRule)
+	groups, _, _ := unstructured.NestedSlice(obj.Object, "spec", "groups")
+	expected := []interface{}{map[string]interface{}{
+		"name":     "redis",
+		"interval": "1m",
+		"rules": []interface{}{
+			map[string]interface{}{"alert": "RedisDown", "expr": "redis_up == 0", "for": "5m",
+				"labels": map[string]interface{}{"severity": "critical"}, "annotations": map[string]interface{}{"summary": "redis is down"}},
+			map[string]interface{}{"alert": "RedisMemoryHigh", "expr": "redis_memory_used_bytes > 1e9"},
+		},
+	}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expected the rule groups %v, got %v", expected, groups)
+	}
+
+	monitoring.RuleGroups = nil
+	if line, err = handler.Make(newMonitorSource(PrometheusRule, monitoring)); err != nil || line.Desired != nil {
+		t.Errorf("expected no prometheus rule without the rule groups, got %v %v", line.Desired, err)
+	}
+}
+
+func TestMonitorStateFinger(t *testing.T) {
+	monitoring := &v1.ComponentMonitoring{Kind: PodMonitor, Endpoints: []v1.MonitorEndpoint{{Port: "metrics"}}}
+	handler := &MonitorHandler{}
+	line, err := handler.Make(newMonitorSource(PodMonitor, monitoring))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := line.Desired.(*unstructured.Unstructured)
+	state := handler.StateFinger(desired)
+
+	// the status is not fingered, the spec and the labels change the finger.
+	observed := desired.DeepCopy()
+	observed.Object["status"] = map[string]interface{}{"observed": "1"}
+	if handler.StateFinger(observed).Meta != state.Meta {
+		t.Errorf("expected the status is not fingered")
+	}
+	_ = unstructured.SetNestedSlice(observed.Object, []interface{}{map[string]interface{}{"port": "exporter"}}, "spec", "podMetricsEndpoints")
+	if handler.StateFinger(observed).Meta == state.Meta {
+		t.Errorf("expected the spec change is fingered")
+	}
+	observed = desired.DeepCopy()
+	observed.SetLabels(map[string]string{"release": "other"})
+	if handler.StateFinger(observed).Meta == state.Meta {
+		t.Errorf("expected the label change is fingered")
+	}
+	if handler.StateFinger(nil).State != v1.Deleted {
+		t.Errorf("expected the missing monitor is deleted")
+	}
+}
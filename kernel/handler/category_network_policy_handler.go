@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// ServicePolicyPorts the pod ports exposed by the services of the component category.
+func ServicePolicyPorts(crd core.BasicCrd, ref *v1.CategoryClusterComponent) []networkingv1.NetworkPolicyPort {
+	ports := map[string]networkingv1.NetworkPolicyPort{}
+	for _, svc := range ComponentServices(crd, ref) {
+		for _, p := range svc.Ports {
+			protocol := p.Protocol
+			if len(protocol) == 0 {
+				protocol = corev1.ProtocolTCP
+			}
+			// the network policy works on the pod port.
+			port := p.TargetPort
+			if port.IntValue() == 0 && len(port.StrVal) == 0 {
+				port = intstr.FromInt(int(p.Port))
+			}
+			ports[fmt.Sprintf("%s/%s", protocol, port.String())] = networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}
+		}
+	}
+
+	keys := make([]string, 0, len(ports))
+	for k := range ports {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var target []networkingv1.NetworkPolicyPort
+	for _, k := range keys {
+		target = append(target, ports[k])
+	}
+	return target
+}
+
+// Make make the build-in k8s resource from current component crd
+func (component *NetworkPolicyHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	meta := source.ResourceMeta.(*core.CategoryComponentObject)
+	ref := meta.Reference.(*v1.CategoryClusterComponent)
+	if ref == nil || ref.NetworkPolicy == nil {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+
+	// the pods of the same cluster are the peers.
+	ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{InstanceLabel: source.Crd.GetName()},
+				},
+			}},
+		},
+	}
+	// the ports of the services are allowed from the clients, or from anywhere when no client is declared.
+	// The rule without ports allows all the ports, so it is skipped when the category has no service port.
+	ports := ServicePolicyPorts(source.Crd, ref)
+	if len(ports) == 0 {
+		if len(ref.NetworkPolicy.Clients) > 0 {
+			component.Logger().Info("network policy skip the clients, no service port of the category", "category", ref.GetCategory())
+		}
+	} else {
+		ingress = append(ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  ref.NetworkPolicy.Clients,
+			Ports: ports,
+		})
+	}
+
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        string(meta.GetName()),
+			Namespace:   source.Crd.GetNamespace(),
+			Labels:      Merge(nil, source.Crd.GetLabels()),
+			Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(source),
+			},
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind: NetworkPolicy,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					InstanceLabel: source.Crd.GetName(),
+					CategoryLabel: string(ref.GetCategory()),
+				},
+			},
+			Ingress:     ingress,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	policy.Labels = Merge(policy.Labels, GetReferenceLabels(ref, NetworkPolicy))
+
+	return &core.ResourcesLine{
+		Desired:      policy,
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// StateFinger convert category state to component state
+func (component *NetworkPolicyHandler) StateFinger(obj client.Object) *v1.ComponentState {
+	if obj == nil {
+		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
+	}
+	policy := obj.(*networkingv1.NetworkPolicy)
+	data := map[string]string{}
+	data["spec"] = policy.Spec.String()
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
+
+// OnEvent make and apply will call it.
+func (component *NetworkPolicyHandler) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"reflect"
+	"testing"
+)
+
+func newNetworkPolicySource(policy *v1.ComponentNetworkPolicy, ports ...corev1.ServicePort) core.CustomResource {
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis", Category: "redis"},
+		ServiceName:             "redis-svc",
+		NetworkPolicy:           policy,
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"},
+		Spec:       v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}},
+	}
+	if len(ports) > 0 {
+		crd.Spec.Service = []*v1.CategoryClusterService{{
+			CommonCategoryComponent: v1.CommonCategoryComponent{Category: "redis-svc"},
+			ServiceSpec:             corev1.ServiceSpec{Ports: ports},
+		}}
+	}
+	return core.CustomResource{
+		ResourceMeta: &core.CategoryComponentObject{
+			CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name: "demo-redis-networkpolicy", Category: "redis-networkpolicy", Component: v1.Component{Kind: NetworkPolicy},
+			},
+			Reference: component,
+		},
+		Crd: crd,
+	}
+}
+
+func TestNetworkPolicyMake(t *testing.T) {
+	clients := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}}}}
+	servicePorts := []corev1.ServicePort{
+		{Name: "redis", Port: 80, TargetPort: intstr.FromInt(6379)},
+		{Name: "sentinel", Port: 26379, TargetPort: intstr.FromString("sentinel")},
+		{Name: "metrics", Port: 9121, Protocol: corev1.ProtocolTCP},
+	}
+	tests := []struct {
+		name    string
+		policy  *v1.ComponentNetworkPolicy
+		ports   []corev1.ServicePort
+		clients []networkingv1.NetworkPolicyPeer
+		allowed []string
+	}{
+		{name: "peers", policy: &v1.ComponentNetworkPolicy{}},
+		{name: "clients on the service ports", policy: &v1.ComponentNetworkPolicy{Clients: clients}, ports: servicePorts,
+			clients: clients, allowed: []string{"TCP/6379", "TCP/9121", "TCP/sentinel"}},
+		// the rule without ports allows all the ports.
+		{name: "clients without services", policy: &v1.ComponentNetworkPolicy{Clients: clients}},
+		{name: "service ports without clients", policy: &v1.ComponentNetworkPolicy{}, ports: servicePorts[:1],
+			allowed: []string{"TCP/6379"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, err := (&NetworkPolicyHandler{}).Make(newNetworkPolicySource(tt.policy, tt.ports...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy := line.Desired.(*networkingv1.NetworkPolicy)
+			if policy.Name != "demo-redis-networkpolicy" || policy.Spec.PodSelector.MatchLabels[CategoryLabel] != "redis" {
+				t.Errorf("expected the policy selects the pods of the category, got %s %v", policy.Name, policy.Spec.PodSelector)
+			}
+			ingress := policy.Spec.Ingress
+			peer := ingress[0]
+			if len(peer.Ports) != 0 || len(peer.From) != 1 || peer.From[0].PodSelector.MatchLabels[InstanceLabel] != "demo" {
+				t.Errorf("expected all the ports are allowed from the peers, got %v", peer)
+			}
+			if len(tt.allowed) == 0 {
+				if len(ingress) != 1 {
+					t.Errorf("expected only the peer rule, got %v", ingress)
+				}
+				return
+			}
+			if len(ingress) != 2 {
+				t.Fatalf("expected the peer and the service rules, got %v", ingress)
+			}
+			if !reflect.DeepEqual(ingress[1].From, tt.clients) {
+				t.Errorf("expected the service ports are allowed from %v, got %v", tt.clients, ingress[1].From)
+			}
+			var allowed []string
+			for _, p := range ingress[1].Ports {
+				allowed = append(allowed, fmt.Sprintf("%s/%s", *p.Protocol, p.Port.String()))
+			}
+			if !reflect.DeepEqual(allowed, tt.allowed) {
+				t.Errorf("expected the pod ports %v, got %v", tt.allowed, allowed)
+			}
+		})
+	}
+
+	line, err := (&NetworkPolicyHandler{}).Make(newNetworkPolicySource(nil, servicePorts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.Desired != nil {
+		t.Errorf("expected no network policy without the spec, got %v", line.Desired)
+	}
+}
@@ -2,11 +2,11 @@ package handler
 
 import (
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
-	"k8s.io/api/policy/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,12 +33,12 @@ func (component *PodDisruptionBudgetHandler) Make(source core.CustomResource) (*
 		}, nil
 	}
 
-	pdb := &v1beta1.PodDisruptionBudget{
+	pdb := &policyv1.PodDisruptionBudget{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        string(meta.GetName()),
 			Namespace:   source.Crd.GetNamespace(),
 			Labels:      Merge(nil, source.Crd.GetLabels()),
-			Annotations: Merge(nil, source.Crd.GetAnnotations()),
+			Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source),
 			},
@@ -46,7 +46,7 @@ func (component *PodDisruptionBudgetHandler) Make(source core.CustomResource) (*
 		TypeMeta: metav1.TypeMeta{
 			Kind: PodDisruptionBudget,
 		},
-		Spec: v1beta1.PodDisruptionBudgetSpec{
+		Spec: policyv1.PodDisruptionBudgetSpec{
 			MaxUnavailable: AutoScaleMaxUnavailable(ref.Replicas),
 			Selector:       ref.Selector,
 		},
@@ -65,7 +65,7 @@ func (component *PodDisruptionBudgetHandler) StateFinger(obj client.Object) *v1.
 	if obj == nil {
 		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
 	}
-	pdb := obj.(*v1beta1.PodDisruptionBudget)
+	pdb := obj.(*policyv1.PodDisruptionBudget)
 	data := map[string]string{}
 	data["spec"] = pdb.Spec.String()
 	return v1.NewComponentState(v1.Success, "ok", data)
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
@@ -21,7 +21,8 @@ func (component *PersistentVolumeClaimHandler) Make(source core.CustomResource)
 	// Properties which should be provided from real deployed environment.
 	meta := source.ResourceMeta.(*core.CategoryComponentObject)
 	ref := meta.Reference.(*v1.CategoryClusterComponent)
-	if ref == nil || ref.Replicas == nil || *ref.Replicas == 0 {
+	// only the StatefulSet claims the volume for each pod.
+	if ref == nil || ref.Replicas == nil || *ref.Replicas == 0 || ref.PersistentVolumeClaim == nil || ref.GetKind() != StatefulSet {
 		return &core.ResourcesLine{
 			ResourceMeta: source.ResourceMeta,
 		}, nil
@@ -37,7 +38,7 @@ func (component *PersistentVolumeClaimHandler) Make(source core.CustomResource)
 				OwnerReferences: []metav1.OwnerReference{
 					ToOwnerReference(source)},
 				Labels:      Merge(nil, source.Crd.GetLabels()),
-				Annotations: Merge(nil, source.Crd.GetAnnotations()),
+				Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
 			},
 			TypeMeta: metav1.TypeMeta{
 				Kind: PersistentVolumeClaim,
@@ -1,11 +1,12 @@
 package handler
 
 import (
-	"crypto/md5"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	"github.com/kuberator/api/extends"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,28 +19,45 @@ func (component *SecretHandler) Make(source core.CustomResource) (*core.Resource
 	meta := source.ResourceMeta.(*core.CategoryComponentObject)
 	ref := meta.Reference.(*v1.CategoryClusterComponent)
 
-	if ref == nil || ref.Auth == nil {
+	if ref == nil {
 		return &core.ResourcesLine{
 			ResourceMeta: source.ResourceMeta,
 		}, nil
 	}
 
-	auth := &v1.BasicAuth{
-		Role:     ref.Auth.Role,
-		Username: ref.Auth.Username,
-		Password: ref.Auth.Password,
-		Salt:     ref.Auth.Salt,
-		Auth:     ref.Auth.Auth,
+	// the credentials of the named user or the component auth.
+	var data map[string]string
+	var authSource v1.AuthSource
+	var legacy v1.LegacyAuth
+	user, isUser := meta.Object.(*v1.ComponentUser)
+	if isUser {
+		data = map[string]string{"role": user.Role, "username": user.Name}
+		authSource = user.AuthSource
+	} else if ref.Auth != nil {
+		// the plaintext auth of v1beta1 left in the annotation by the previous release is moved into the secret,
+		// later the secret keeps it.
+		legacy = v1.GetLegacyAuth(source.Crd.GetAnnotations(), ref.GetName())
+		data = map[string]string{"role": ref.Auth.Role, "username": ref.Auth.Username}
+		if len(legacy.Auth) > 0 {
+			data["auth"] = legacy.Auth
+		}
+		authSource = ref.Auth.AuthSource
 	}
 
-	if len(auth.Role) == 0 {
-		auth.Role = "root"
-	}
-	if len(auth.Username) == 0 {
-		auth.Username = "root"
+	// the existing secret provides all the credentials, there is nothing to manage.
+	if data == nil || len(authSource.ExistingSecret) > 0 {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
 	}
-	if len(ref.Auth.Password) == 0 {
-		auth.Password = fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s_%s_%s_%s", source.Crd.GetNamespace(), source.Crd.GetName(), string(ref.GetCategory()), auth.Salt))))
+
+	// the password is generated when the secret is created, see PreApply.
+	annotations := Merge(InheritedAnnotations(source.Crd.GetAnnotations()), map[string]string{PasswordSourceAnnotation: PasswordSourceGenerated})
+	if secretRef := authSource.PasswordSecretRef; secretRef != nil {
+		annotations[PasswordSourceAnnotation] = fmt.Sprintf("%s/%s", secretRef.Name, secretRef.Key)
+	} else if len(legacy.Password) > 0 {
+		data[PasswordKey] = legacy.Password
+		annotations[PasswordSourceAnnotation] = PasswordSourceLegacy
 	}
 
 	template := &corev1.Secret{
@@ -49,14 +67,18 @@ func (component *SecretHandler) Make(source core.CustomResource) (*core.Resource
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 			Labels:      Merge(nil, source.Crd.GetLabels()),
-			Annotations: Merge(nil, source.Crd.GetAnnotations()),
+			Annotations: annotations,
 		},
 		TypeMeta:   metav1.TypeMeta{Kind: Secret},
-		StringData: auth.ToMap(),
+		StringData: data,
 		Type:       corev1.SecretTypeBasicAuth,
 	}
 
 	template.Labels = Merge(template.Labels, GetReferenceLabels(ref, Secret))
+	if isUser {
+		template.Labels[CategoryLabel] = string(meta.GetCategory())
+		template.Labels[UserLabel] = user.Name
+	}
 
 	return &core.ResourcesLine{
 		Desired:      template,
@@ -65,10 +87,102 @@ func (component *SecretHandler) Make(source core.CustomResource) (*core.Resource
 }
 
 // StateFinger convert category state to component state
+// The credentials are fingered by their digests, so that they are never printed.
 func (component *SecretHandler) StateFinger(obj client.Object) *v1.ComponentState {
 	if obj == nil {
 		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
 	}
-	secret := obj.(*corev1.Secret)
-	return v1.NewComponentState(v1.Success, "ok", secret.StringData)
+	data := map[string]string{}
+	for k, v := range secretData(obj.(*corev1.Secret)) {
+		data[k] = PasswordDigest(v)
+	}
+	data["passwordSource"] = obj.GetAnnotations()[PasswordSourceAnnotation]
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
+
+// Visitation provision the named user by the extension of the component when its password is changed,
+// and rotate the generated password when the rotation is requested by the annotation of the crd.
+// The rotation is resumed until the request is recorded by the secret, see the rotate of the apply stage.
+func (component *SecretHandler) Visitation(args core.ComponentArgs) *core.ActionCommand {
+	secret, ok := args.Observed.(*corev1.Secret)
+	if !ok || secret == nil || args.Desired == nil {
+		return nil
+	}
+
+	var command *core.ActionCommand
+	if meta, ok := args.ResourceMeta.(*core.CategoryComponentObject); ok {
+		user, isUser := meta.Object.(*v1.ComponentUser)
+		_, sh := extend.GetHandler(meta.Reference.GetCategory())
+		if _, provisioner := sh.(extends.UserProvisioner); isUser && provisioner &&
+			secret.Annotations[ProvisionedAnnotation] != PasswordDigest(string(secret.Data[PasswordKey])) {
+			command = &core.ActionCommand{
+				Action:  v1.Provision,
+				Message: fmt.Sprintf("provision the user %s of %s", user.Name, meta.Reference.GetCategory()),
+				TargetResource: &core.ReferenceObject{
+					Target: args.Desired,
+				},
+			}
+		}
+	}
+
+	request := args.Crd.GetAnnotations()[v1.RotateCredentialsAnnotation]
+	if len(request) > 0 && secret.Annotations[PasswordSourceAnnotation] == PasswordSourceGenerated &&
+		secret.Annotations[RotatedCredentialsAnnotation] != request {
+		rotate := &core.ActionCommand{
+			Action:  v1.Rotate,
+			Message: fmt.Sprintf("rotate the credentials of %s, request %s", secret.Labels[ReferenceLabel], request),
+			TargetResource: &core.ReferenceObject{
+				// grouped by the referenced category, see the rotate of the apply stage.
+				Category: v1.Category(secret.Labels[ReferenceLabel]),
+				Target:   args.Desired,
+			},
+		}
+		if command == nil {
+			command = rotate
+		} else {
+			command.Append(rotate)
+		}
+	}
+	return command
+}
+
+// PreApply how to action when apply.
+// The generated password is generated once when the secret has none, it is never overwritten later.
+// The password read from the referenced secret is not kept in the managed secret.
+func (component *SecretHandler) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
+	if secret, ok := desired.(*corev1.Secret); ok && secret.GetLabels()[ControlLabel] != string(v1.Delete) {
+		switch secret.GetAnnotations()[PasswordSourceAnnotation] {
+		case PasswordSourceGenerated:
+			if !hasPassword(secret) {
+				password, err := GeneratePassword()
+				if err != nil {
+					component.Logger().Error(err, "generate password failed", "name", secret.Name)
+					return &core.ActionCommand{Action: v1.Non, TargetResource: &core.ReferenceObject{}}, core.Result().Error(err)
+				}
+				secret.StringData = Merge(secret.StringData, map[string]string{PasswordKey: password})
+			}
+		case PasswordSourceLegacy:
+			// the v1beta1 password is applied as it is, then it is removed from the crd, see the post apply stage.
+		default:
+			delete(secret.Data, PasswordKey)
+			delete(secret.StringData, PasswordKey)
+		}
+	}
+	return component.CategoryComponentHandler.PreApply(observed, desired)
+}
+
+// secretData the data of the secret, the string data is merged into it as the api server does.
+func secretData(secret *corev1.Secret) map[string]string {
+	data := map[string]string{}
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		data[k] = v
+	}
+	return data
+}
+
+func hasPassword(secret *corev1.Secret) bool {
+	return len(secretData(secret)[PasswordKey]) > 0
 }
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"testing"
+)
+
+func TestSecretStateFinger(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{PasswordSourceAnnotation: PasswordSourceGenerated}},
+		Data: map[string][]byte{
+			PasswordKey:         []byte("new-secret"),
+			PreviousPasswordKey: []byte("old-secret"),
+			"auth":              []byte("legacy-secret"),
+		},
+	}
+	state := (&SecretHandler{}).StateFinger(secret)
+	if strings.Contains(state.Meta, "secret") {
+		t.Errorf("expected every credential is digested, got %s", state.Meta)
+	}
+	if !strings.Contains(state.Meta, "passwordSource="+PasswordSourceGenerated) {
+		t.Errorf("expected the password source is fingered, got %s", state.Meta)
+	}
+}
+
+// newSecretSource the source of the component secret, or the secret of the user when it is set.
+func newSecretSource(auth v1.AuthSource, user *v1.ComponentUser, annotations map[string]string) core.CustomResource {
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis", Category: "redis"},
+		Auth:                    &v1.BasicAuth{Role: "admin", Username: "default", AuthSource: auth},
+	}
+	meta := &core.CategoryComponentObject{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis-secret", Category: "redis-secret", Component: v1.Component{Kind: Secret}},
+		Reference:               component,
+	}
+	if user != nil {
+		meta.Name = v1.ComponentName("demo-redis-user-" + user.Name)
+		meta.Object = user
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo", Annotations: annotations},
+		Spec:       v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}},
+	}
+	return core.CustomResource{ResourceMeta: meta, Crd: crd}
+}
+
+func makeSecret(t *testing.T, source core.CustomResource) *corev1.Secret {
+	line, err := (&SecretHandler{}).Make(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.Desired == nil {
+		return nil
+	}
+	return line.Desired.(*corev1.Secret)
+}
+
+func TestSecretMake(t *testing.T) {
+	secret := makeSecret(t, newSecretSource(v1.AuthSource{}, nil, nil))
+	if secret == nil || secret.StringData["username"] != "default" || secret.StringData["role"] != "admin" {
+		t.Fatalf("expected the credentials of the component auth, got %v", secret)
+	}
+	if _, ok := secret.StringData[PasswordKey]; ok || secret.Annotations[PasswordSourceAnnotation] != PasswordSourceGenerated {
+		t.Errorf("expected the password is generated when the secret is created, got %v", secret.Annotations)
+	}
+
+	user := makeSecret(t, newSecretSource(v1.AuthSource{}, &v1.ComponentUser{Name: "app", Role: "reader"}, nil))
+	if user == nil || user.StringData["username"] != "app" || user.Labels[UserLabel] != "app" {
+		t.Errorf("expected the credentials of the user, got %v", user)
+	}
+
+	legacy := makeSecret(t, newSecretSource(v1.AuthSource{}, nil,
+		map[string]string{v1.LegacyAuthAnnotation: `{"demo-redis":{"password":"legacy"}}`}))
+	if legacy.StringData[PasswordKey] != "legacy" || legacy.Annotations[PasswordSourceAnnotation] != PasswordSourceLegacy {
+		t.Errorf("expected the legacy password is moved into the secret, got %v", legacy.Annotations)
+	}
+	if _, ok := legacy.Annotations[v1.LegacyAuthAnnotation]; ok {
+		t.Errorf("expected the legacy annotation is not inherited")
+	}
+
+	ref := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis-auth"}, Key: "pass"}
+	referenced := makeSecret(t, newSecretSource(v1.AuthSource{PasswordSecretRef: ref}, nil, nil))
+	if referenced.Annotations[PasswordSourceAnnotation] != "redis-auth/pass" {
+		t.Errorf("expected the password source is the referenced secret, got %v", referenced.Annotations)
+	}
+
+	// the existing secret provides all the credentials, nothing is managed.
+	if existing := makeSecret(t, newSecretSource(v1.AuthSource{ExistingSecret: "redis-auth"}, nil, nil)); existing != nil {
+		t.Errorf("expected no desired secret of the existing secret, got %v", existing)
+	}
+	if existing := makeSecret(t, newSecretSource(v1.AuthSource{}, &v1.ComponentUser{Name: "app",
+		AuthSource: v1.AuthSource{ExistingSecret: "app-auth"}}, nil)); existing != nil {
+		t.Errorf("expected no desired secret of the existing user secret, got %v", existing)
+	}
+}
+
+func TestSecretPreApply(t *testing.T) {
+	handler := &SecretHandler{}
+	desired := makeSecret(t, newSecretSource(v1.AuthSource{}, nil, nil))
+	act, result := handler.PreApply(nil, desired)
+	if act.Action != v1.Create || result.IsError() || len(desired.StringData[PasswordKey]) == 0 {
+		t.Fatalf("expected the secret is created with the generated password, got %s", act.Action)
+	}
+
+	// the desired is merged over the observed, the existing password is kept.
+	observed := desired.DeepCopy()
+	observed.StringData = nil
+	observed.Data = map[string][]byte{PasswordKey: []byte("kept")}
+	desired = makeSecret(t, newSecretSource(v1.AuthSource{}, nil, nil))
+	desired.Data = map[string][]byte{PasswordKey: []byte("kept")}
+	if act, _ = handler.PreApply(observed, desired); act.Action != v1.Update {
+		t.Fatalf("expected the secret is updated, got %s", act.Action)
+	}
+	if _, ok := desired.StringData[PasswordKey]; ok || string(desired.Data[PasswordKey]) != "kept" {
+		t.Errorf("expected the existing password is never overwritten, got %v", desired.StringData)
+	}
+
+	// the password read from the referenced secret is not kept in the managed secret.
+	ref := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "redis-auth"}, Key: "pass"}
+	desired = makeSecret(t, newSecretSource(v1.AuthSource{PasswordSecretRef: ref}, nil, nil))
+	desired.Data = map[string][]byte{PasswordKey: []byte("kept")}
+	desired.StringData[PasswordKey] = "generated"
+	if act, _ = handler.PreApply(observed, desired); act.Action != v1.Update {
+		t.Fatalf("expected the secret is updated, got %s", act.Action)
+	}
+	if _, ok := desired.Data[PasswordKey]; ok {
+		t.Errorf("expected the password is stripped from the data")
+	}
+	if _, ok := desired.StringData[PasswordKey]; ok {
+		t.Errorf("expected the password is stripped from the string data")
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"fmt"
 	"github.com/fatih/structs"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -23,19 +24,42 @@ func (component *ServiceComponentHandler) Make(source core.CustomResource) (*cor
 			Name:        string(source.ResourceMeta.GetName()),
 			Namespace:   source.Crd.GetNamespace(),
 			Labels:      Merge(source.ResourceMeta.(*v1.CategoryClusterService).Labels, source.Crd.GetLabels()),
-			Annotations: Merge(source.ResourceMeta.(*v1.CategoryClusterService).Annotations, source.Crd.GetAnnotations()),
+			Annotations: Merge(source.ResourceMeta.(*v1.CategoryClusterService).Annotations, InheritedAnnotations(source.Crd.GetAnnotations())),
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 		},
 		Spec: source.ResourceMeta.(*v1.CategoryClusterService).ServiceSpec,
 	}
 	service.Labels[CategoryLabel] = string(source.ResourceMeta.GetCategory())
+	service.Labels[InstanceLabel] = source.Crd.GetName()
 	return &core.ResourcesLine{
 		Desired:      service,
 		ResourceMeta: source.ResourceMeta,
 	}, nil
 }
 
+// ComponentServices the services of the component category.
+// The service is belonged to the category when it is the peer service, or it selects the component pods.
+func ComponentServices(crd core.BasicCrd, ref *v1.CategoryClusterComponent) []*v1.CategoryClusterService {
+	podLabels := Merge(Merge(ref.Labels, ref.Template.Labels), map[string]string{
+		InstanceLabel: crd.GetName(),
+		CategoryLabel: string(ref.GetCategory()),
+	})
+
+	var services []*v1.CategoryClusterService
+	for _, svc := range crd.GetSpec().Service {
+		if svc == nil {
+			continue
+		}
+		peer := len(ref.ServiceName) > 0 && svc.GetCategory() == v1.Category(ref.ServiceName)
+		selected := len(svc.Selector) > 0 && labels.SelectorFromSet(svc.Selector).Matches(labels.Set(podLabels))
+		if peer || selected {
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
 // StateFinger convert category state to component state
 func (component *ServiceComponentHandler) StateFinger(obj client.Object) *v1.ComponentState {
 	if obj == nil {
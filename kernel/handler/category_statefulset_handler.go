@@ -5,16 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	. "github.com/kuberator/kernel/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sort"
 )
 
 func getCrd(source core.CustomResource) *v1.CategoryClusterComponent {
@@ -23,41 +21,7 @@ func getCrd(source core.CustomResource) *v1.CategoryClusterComponent {
 
 // MergeConf the desired configMap.
 func (component *StatefulSetClusterComponent) MergeConf(source core.CustomResource) []v1.NamedProperties {
-	// Properties which should be provided from real deployed environment.
-	conf := map[string]*v1.NamedProperties{}
-	if source.Crd.GetSpec().Conf != nil {
-		for _, c := range source.Crd.GetSpec().Conf {
-			conf[c.PropertiesName()] = c
-		}
-	}
-
-	// pod conf
-	if getCrd(source).Properties != nil {
-		for _, v := range getCrd(source).Properties {
-			if conf[v.PropertiesName()] == nil {
-				conf[v.PropertiesName()] = v
-			}
-		}
-	}
-
-	if len(conf) == 0 {
-		return nil
-	}
-
-	keys := make([]string, len(conf))
-	i := 0
-	for k := range conf {
-		keys[i] = k
-		i = i + 1
-	}
-	sort.Strings(keys)
-
-	var confList []v1.NamedProperties
-	for _, key := range keys {
-		confList = append(confList, *conf[key])
-	}
-
-	return confList
+	return mergeConf(source)
 }
 
 // Make make the build-in k8s resource from current component crd
@@ -66,6 +30,7 @@ func (component *StatefulSetClusterComponent) Make(source core.CustomResource) (
 	if replicas == nil || *replicas == 0 {
 		return &core.ResourcesLine{ResourceMeta: source.ResourceMeta}, nil
 	}
+	serviceName := GetComponentShotName(source.Crd.GetName(), v1.Category(getCrd(source).ServiceName))
 	// build-in statefulSet
 	statefulSet := &appsv1.StatefulSet{
 		TypeMeta: metav1.TypeMeta{
@@ -75,91 +40,24 @@ func (component *StatefulSetClusterComponent) Make(source core.CustomResource) (
 			Name:        string(source.ResourceMeta.GetName()),
 			Namespace:   source.Crd.GetNamespace(),
 			Labels:      Merge(source.Crd.GetLabels(), getCrd(source).Labels),
-			Annotations: Merge(source.Crd.GetAnnotations(), getCrd(source).Annotations),
+			Annotations: Merge(InheritedAnnotations(source.Crd.GetAnnotations()), getCrd(source).Annotations),
 			OwnerReferences: []metav1.OwnerReference{
 				ToOwnerReference(source)},
 		},
 		Spec: appsv1.StatefulSetSpec{
-			Replicas: replicas,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels:      Merge(getCrd(source).Labels, getCrd(source).Template.Labels),
-					Annotations: Merge(getCrd(source).Annotations, getCrd(source).Template.Annotations),
-				},
-				Spec: getCrd(source).Template.Spec,
-			},
-			Selector:            getCrd(source).Selector,
-			UpdateStrategy:      getCrd(source).UpdateStrategy,
-			PodManagementPolicy: getCrd(source).PodManagementPolicy,
-			ServiceName:         GetComponentShotName(source.Crd.GetName(), v1.Category(getCrd(source).ServiceName)),
+			Replicas:             replicas,
+			Template:             makePodTemplate(source, serviceName),
+			Selector:             getCrd(source).Selector,
+			UpdateStrategy:       getCrd(source).UpdateStrategy,
+			PodManagementPolicy:  getCrd(source).PodManagementPolicy,
+			RevisionHistoryLimit: getCrd(source).RevisionHistoryLimit,
+			ServiceName:          serviceName,
 		},
 	}
-	var RevisionHistoryLimit = int32(10)
-	if len(statefulSet.Spec.UpdateStrategy.Type) == 0 {
-		//OnDelete 更新策略实现了传统（1.7 之前）行为，它也是默认的更新策略。 当你选择这个更新策略并修改 StatefulSet 的 .spec.template 字段时，StatefulSet 控制器将不会自动更新 Pod。
-		statefulSet.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
-	}
-	if len(statefulSet.Spec.PodManagementPolicy) == 0 {
-		statefulSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
-	}
-	if statefulSet.Spec.RevisionHistoryLimit == nil {
-		statefulSet.Spec.RevisionHistoryLimit = &RevisionHistoryLimit
-	}
 
 	//com label
 	statefulSet.Labels[InstanceLabel] = source.Crd.GetName()
 	statefulSet.Labels[CategoryLabel] = string(getCrd(source).GetCategory())
-	statefulSet.Spec.Template.Labels[InstanceLabel] = source.Crd.GetName()
-	statefulSet.Spec.Template.Labels[CategoryLabel] = string(getCrd(source).GetCategory())
-
-	confList := component.MergeConf(source)
-
-	// default env
-	envs := []corev1.EnvVar{
-		{
-			Name: Namespace,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					APIVersion: "v1",
-					FieldPath:  "metadata.namespace",
-				},
-			},
-		},
-		{
-			Name: NodeName,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					APIVersion: "v1",
-					FieldPath:  "spec.nodeName",
-				},
-			},
-		},
-		{
-			Name: HostIp,
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{
-					APIVersion: "v1",
-					FieldPath:  "status.hostIP",
-				},
-			},
-		},
-		{
-			Name:  AppName,
-			Value: source.Crd.GetName(),
-		},
-		{
-			Name:  Category,
-			Value: string(source.ResourceMeta.GetCategory()),
-		},
-		{
-			Name:  ClusterDomain,
-			Value: os.Getenv(ClusterDomain),
-		},
-		{
-			Name:  PeerService,
-			Value: fmt.Sprintf("%s.%s.svc.%s", statefulSet.Spec.ServiceName, source.Crd.GetNamespace(), os.Getenv(ClusterDomain)),
-		},
-	}
 
 	// pvc
 	pvc := MakePersistentVolumeClaim(*statefulSet, getCrd(source))
@@ -169,43 +67,12 @@ func (component *StatefulSetClusterComponent) Make(source core.CustomResource) (
 		}
 	}
 
-	// init containers
-	statefulSet.Spec.Template.Spec.InitContainers = mergeDefaultEnv(envs, statefulSet.Spec.Template.Spec.InitContainers)
-
-	// container
-	statefulSet.Spec.Template.Spec.Containers = mergeDefaultEnv(envs, statefulSet.Spec.Template.Spec.Containers)
-	for c := range statefulSet.Spec.Template.Spec.Containers {
-		var spec = &statefulSet.Spec.Template.Spec
-		var container = &spec.Containers[c]
-
-		// volume
-		vl, vm := BuildConfigMapMount(getCrd(source).Category, source.Crd.GetName(), confList)
-		if vl != nil {
-			spec.Volumes = append(spec.Volumes, vl...)
-		}
-
-		// amount
-		if vm != nil {
-			container.VolumeMounts = append(container.VolumeMounts, vm...)
-		}
-	}
-
 	return &core.ResourcesLine{
 		Desired:      statefulSet,
 		ResourceMeta: source.ResourceMeta,
 	}, nil
 }
 
-func mergeDefaultEnv(env []corev1.EnvVar, containers []corev1.Container) []corev1.Container {
-	for i, c := range containers {
-		var target []corev1.EnvVar
-		target = append(target, env...)
-		target = append(target, c.Env...)
-		containers[i].Env = target
-	}
-	return containers
-}
-
 // StateFinger the crd state
 func (component *StatefulSetClusterComponent) StateFinger(obj client.Object) *v1.ComponentState {
 	data := map[string]string{}
@@ -242,6 +109,9 @@ func (component *StatefulSetClusterComponent) RestartCheck(observed client.Objec
 	if observed != nil && desired != nil {
 		metaO := v1.ToString(PodSpecFinger(observed.(*appsv1.StatefulSet).Spec.Template.Spec), "=")
 		metaD := v1.ToString(PodSpecFinger(desired.(*appsv1.StatefulSet).Spec.Template.Spec), "=")
+		// the conf checksum of the RollingRestart policy.
+		metaO += observed.(*appsv1.StatefulSet).Spec.Template.Annotations[ConfVersion]
+		metaD += desired.(*appsv1.StatefulSet).Spec.Template.Annotations[ConfVersion]
 		if metaO != metaD {
 			component.Logger().Info("StatefulSet pod template is changed")
 			PrintFingerDiff(metaO, metaD)
@@ -375,7 +245,12 @@ func (component *StatefulSetClusterComponent) Visitation(args core.ComponentArgs
 }
 
 // PreApply how to action when apply.
+// The RECOVERY_MODE env is kept while the fail over is in progress, it is reset by the fail over.
 func (component *StatefulSetClusterComponent) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
+	if sts, ok := observed.(*appsv1.StatefulSet); ok && sts != nil && desired != nil &&
+		GetEnv(sts.Spec.Template.Spec.Containers, RecoveryMode) == "true" {
+		ModifyEnv(desired.(*appsv1.StatefulSet).Spec.Template.Spec.Containers, corev1.EnvVar{Name: RecoveryMode, Value: "true"})
+	}
 	act, _ := component.CategoryComponentHandler.PreApply(observed, desired)
 	if act.Action == v1.Update {
 		// ensure the pod add the cluster ok.
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/extend"
+	. "github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Make make the secret of the cluster CA or the component certificate, the certificate is issued by the apply stage, see the issue of it.
+// The secret of the component records the dns names of its certificate, which cover the services of the component and the pods of the StatefulSet.
+func (component *TLSSecretHandler) Make(source core.CustomResource) (*core.ResourcesLine, error) {
+	meta := source.ResourceMeta.(*core.CategoryComponentObject)
+	if source.Crd.GetSpec().TLS == nil {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+
+	template := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: source.Crd.GetNamespace(),
+			Name:      string(meta.GetName()),
+			OwnerReferences: []metav1.OwnerReference{
+				ToOwnerReference(source)},
+			Labels:      Merge(nil, source.Crd.GetLabels()),
+			Annotations: InheritedAnnotations(source.Crd.GetAnnotations()),
+		},
+		TypeMeta: metav1.TypeMeta{Kind: Secret},
+		Type:     corev1.SecretTypeTLS,
+	}
+
+	ref, ok := meta.Reference.(*v1.CategoryClusterComponent)
+	// the certificate of the component is issued by the cert-manager.
+	if ok && ref != nil && ref.Certificate != nil {
+		return &core.ResourcesLine{
+			ResourceMeta: source.ResourceMeta,
+		}, nil
+	}
+	if !ok || ref == nil {
+		template.Labels[InstanceLabel] = source.Crd.GetName()
+		template.Labels[CategoryLabel] = string(meta.GetCategory())
+	} else {
+		template.Labels = Merge(template.Labels, GetReferenceLabels(ref, TLSSecret))
+		template.Annotations[TLSDNSNamesAnnotation] = strings.Join(tlsDNSNames(source.Crd, ref), ",")
+	}
+
+	return &core.ResourcesLine{
+		Desired:      template,
+		ResourceMeta: source.ResourceMeta,
+	}, nil
+}
+
+// tlsDNSNames the dns names of the services of the component, and the pods of the StatefulSet behind its headless service.
+func tlsDNSNames(crd core.BasicCrd, ref *v1.CategoryClusterComponent) []string {
+	namespace := crd.GetNamespace()
+	domain := os.Getenv(ClusterDomain)
+	names := map[string]bool{}
+	add := func(host string) {
+		names[host] = true
+		names[fmt.Sprintf("%s.%s", host, namespace)] = true
+		names[fmt.Sprintf("%s.%s.svc", host, namespace)] = true
+		if len(domain) > 0 {
+			names[fmt.Sprintf("%s.%s.svc.%s", host, namespace, domain)] = true
+		}
+	}
+	for _, svc := range ComponentServices(crd, ref) {
+		add(string(svc.GetName()))
+	}
+
+	if ref.GetKind() == StatefulSet && len(ref.ServiceName) > 0 {
+		serviceName := GetComponentShotName(crd.GetName(), v1.Category(ref.ServiceName))
+		replicas := int32(0)
+		if ref.Replicas != nil {
+			replicas = *ref.Replicas
+		}
+		// the pods scaled by the autoscaler are covered too.
+		if ref.MaxReplicas != nil && *ref.MaxReplicas > replicas {
+			replicas = *ref.MaxReplicas
+		}
+		for i := int32(0); i < replicas; i++ {
+			add(fmt.Sprintf("%s-%d.%s", ref.GetName(), i, serviceName))
+		}
+	}
+
+	target := make([]string, 0, len(names))
+	for name := range names {
+		target = append(target, name)
+	}
+	sort.Strings(target)
+	return target
+}
+
+// StateFinger convert category state to component state
+// The certificate is not fingered, it is checked by the visitation.
+func (component *TLSSecretHandler) StateFinger(obj client.Object) *v1.ComponentState {
+	if obj == nil {
+		return v1.NewComponentState(v1.Deleted, "Deleted", map[string]string{})
+	}
+	data := map[string]string{}
+	data["Type"] = string(obj.(*corev1.Secret).Type)
+	data["dnsNames"] = obj.GetAnnotations()[TLSDNSNamesAnnotation]
+	return v1.NewComponentState(v1.Success, "ok", data)
+}
+
+// PreApply how to action when apply.
+// The certificate is issued when the secret is created, or when its dns names are changed.
+func (component *TLSSecretHandler) PreApply(observed client.Object, desired client.Object) (*core.ActionCommand, core.CommandResult) {
+	if desired == nil || desired.GetLabels()[ControlLabel] == string(v1.Delete) {
+		return component.CategoryComponentHandler.PreApply(observed, desired)
+	}
+	if observed == nil || observed.GetAnnotations()[TLSDNSNamesAnnotation] != desired.GetAnnotations()[TLSDNSNamesAnnotation] {
+		return &core.ActionCommand{
+			Action:  v1.Issue,
+			Message: fmt.Sprintf("issue the certificate of %s", desired.GetName()),
+			TargetResource: &core.ReferenceObject{
+				Target: desired,
+			},
+		}, core.Result()
+	}
+	return component.CategoryComponentHandler.PreApply(observed, desired)
+}
+
+// Visitation renew the certificate of the component before it expires, or when it is not signed by its CA,
+// the pods of the referenced category are restarted to load the new certificate.
+// The CA is not renewed by the operator.
+func (component *TLSSecretHandler) Visitation(args core.ComponentArgs) *core.ActionCommand {
+	secret, ok := args.Observed.(*corev1.Secret)
+	tls := args.Crd.GetSpec().TLS
+	if !ok || secret == nil || args.Desired == nil || tls == nil {
+		return nil
+	}
+	category := secret.Labels[ReferenceLabel]
+	if len(category) == 0 {
+		return nil
+	}
+
+	_, renewBefore := GetTLSDurations(tls)
+	reason := certificateRenewReason(secret, renewBefore)
+	if len(reason) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("the certificate of %s %s, renew it and restart all the %s pod", secret.Name, reason, category)
+	component.Logger().Info(message)
+	command := &core.ActionCommand{
+		Action:  v1.Issue,
+		Message: message,
+		TargetResource: &core.ReferenceObject{
+			Target: args.Desired,
+		},
+	}
+	return command.Append(GetRestartCommand(args.Desired, category, 0, message))
+}
+
+// certificateRenewReason why the certificate of the secret need to be renewed, it is empty when the certificate is valid.
+func certificateRenewReason(secret *corev1.Secret, renewBefore time.Duration) string {
+	cert, err := ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "is invalid"
+	}
+	ca, err := ParseCertificate(secret.Data[TLSCAKey])
+	if err != nil || !bytes.Equal(cert.RawIssuer, ca.RawSubject) || cert.CheckSignatureFrom(ca) != nil {
+		return "is not signed by the CA"
+	}
+	if time.Now().Add(renewBefore).After(cert.NotAfter) {
+		return fmt.Sprintf("expires at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// OnEvent make and apply will call it.
+func (component *TLSSecretHandler) OnEvent(event extend.Event) error {
+	component.Logger().Info("component accept handler event", "category", event.Category, "name", event.Name, "action", event.Action, "state", event.State)
+	return nil
+}
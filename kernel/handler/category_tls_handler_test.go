@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	. "github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTLSComponent the redis StatefulSet behind the peer service, it is selected by the client service too.
+func newTLSComponent() (*v1.CategoryClusterComponent, *v1.MiddlewareCluster) {
+	replicas, maxReplicas := int32(2), int32(3)
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis", Category: "redis", Component: v1.Component{Kind: StatefulSet}},
+		Replicas:                &replicas,
+		MaxReplicas:             &maxReplicas,
+		ServiceName:             "redis-peer",
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo", Labels: map[string]string{"team": "storage"}},
+		Spec: v1.MiddlewareClusterSpec{
+			TLS:        &v1.ClusterTLS{},
+			Components: []*v1.CategoryClusterComponent{component},
+			Service: []*v1.CategoryClusterService{
+				{CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis-peer", Category: "redis-peer"}},
+				{CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-redis-svc", Category: "redis-svc"},
+					ServiceSpec: corev1.ServiceSpec{Selector: map[string]string{CategoryLabel: "redis"}}},
+				{CommonCategoryComponent: v1.CommonCategoryComponent{Name: "demo-sentinel-svc", Category: "sentinel-svc"},
+					ServiceSpec: corev1.ServiceSpec{Selector: map[string]string{CategoryLabel: "sentinel"}}},
+			},
+		},
+	}
+	return component, crd
+}
+
+func newTLSSource(crd *v1.MiddlewareCluster, ref *v1.CategoryClusterComponent) core.CustomResource {
+	meta := &core.CategoryComponentObject{CommonCategoryComponent: v1.CommonCategoryComponent{
+		Name: v1.ComponentName(GetTLSCAName(crd.Name)), Category: TLSCACategory, Component: v1.Component{Kind: TLSSecret},
+	}}
+	if ref != nil {
+		meta.Name = v1.ComponentName(GetTLSSecretName(crd.Name, ref))
+		meta.Category = "redis-tlssecret"
+		meta.Reference = ref
+	}
+	return core.CustomResource{ResourceMeta: meta, Crd: crd}
+}
+
+func TestTLSSecretMake(t *testing.T) {
+	domain := os.Getenv(ClusterDomain)
+	_ = os.Setenv(ClusterDomain, "cluster.local")
+	t.Cleanup(func() { _ = os.Setenv(ClusterDomain, domain) })
+
+	component, crd := newTLSComponent()
+	handler := &TLSSecretHandler{}
+	line, err := handler.Make(newTLSSource(crd, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, ok := line.Desired.(*corev1.Secret)
+	if !ok {
+		t.Fatalf("expected the CA secret, got %T", line.Desired)
+	}
+	if ca.Name != "demo-tls-ca" || ca.Type != corev1.SecretTypeTLS || ca.Labels[InstanceLabel] != "demo" || ca.Labels[CategoryLabel] != TLSCACategory {
+		t.Errorf("unexpected CA secret %s %s %v", ca.Name, ca.Type, ca.Labels)
+	}
+	if len(ca.Annotations[TLSDNSNamesAnnotation]) != 0 || len(ca.Data) != 0 {
+		t.Errorf("expected the CA is issued by the apply stage, got %v %v", ca.Annotations, ca.Data)
+	}
+
+	if line, err = handler.Make(newTLSSource(crd, component)); err != nil {
+		t.Fatal(err)
+	}
+	secret := line.Desired.(*corev1.Secret)
+	if secret.Name != "demo-redis-tlssecret" || secret.Type != corev1.SecretTypeTLS || secret.Labels["team"] != "storage" || secret.Labels[ReferenceLabel] != "redis" {
+		t.Errorf("unexpected certificate secret %s %s %v", secret.Name, secret.Type, secret.Labels)
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].UID != "demo" {
+		t.Errorf("expected the secret is owned by the cluster, got %v", secret.OwnerReferences)
+	}
+
+	// the services of the component and the pods scaled up to the max replicas behind the peer service.
+	peer := GetComponentShotName("demo", "redis-peer")
+	hosts := []string{peer, "demo-redis-svc"}
+	for i := 0; i < 3; i++ {
+		hosts = append(hosts, fmt.Sprintf("demo-redis-%d.%s", i, peer))
+	}
+	var expected []string
+	for _, host := range hosts {
+		expected = append(expected, host, host+".ns", host+".ns.svc", GetServiceHost(host, "ns"))
+	}
+	sort.Strings(expected)
+	if dnsNames := strings.Split(secret.Annotations[TLSDNSNamesAnnotation], ","); !reflect.DeepEqual(dnsNames, expected) {
+		t.Errorf("expected the dns names %v, got %v", expected, dnsNames)
+	}
+
+	crd.Spec.TLS = nil
+	if line, err = handler.Make(newTLSSource(crd, component)); err != nil || line.Desired != nil {
+		t.Errorf("expected no certificate without the tls, got %v %v", line.Desired, err)
+	}
+}
+
+func TestTLSSecretPreApply(t *testing.T) {
+	component, crd := newTLSComponent()
+	handler := &TLSSecretHandler{}
+	line, err := handler.Make(newTLSSource(crd, component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := line.Desired.(*corev1.Secret)
+	if cmd, _ := handler.PreApply(nil, desired); cmd == nil || cmd.Action != v1.Issue {
+		t.Errorf("expected the certificate is issued when the secret is created, got %v", cmd)
+	}
+
+	observed := desired.DeepCopy()
+	observed.Data = map[string][]byte{corev1.TLSCertKey: []byte("cert")}
+	if cmd, _ := handler.PreApply(observed, desired); cmd != nil && cmd.Action == v1.Issue {
+		t.Errorf("expected the issued certificate is kept, got %v", cmd)
+	}
+
+	// the component is scaled out, the new pod is not covered by the certificate.
+	*component.MaxReplicas = 4
+	if line, err = handler.Make(newTLSSource(crd, component)); err != nil {
+		t.Fatal(err)
+	}
+	if cmd, _ := handler.PreApply(observed, line.Desired); cmd == nil || cmd.Action != v1.Issue {
+		t.Errorf("expected the certificate is issued again when the dns names are changed, got %v", cmd)
+	}
+}
+
+func TestTLSSecretVisitation(t *testing.T) {
+	component, crd := newTLSComponent()
+	caCert, caKey, err := GenerateCA("demo-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCert, otherKey, err := GenerateCA("other-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue := func(cert, key []byte, duration time.Duration) *corev1.Secret {
+		leaf, _, err := IssueCertificate(cert, key, "demo-redis-tlssecret", []string{"demo-redis-peer"}, duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-redis-tlssecret", Labels: map[string]string{ReferenceLabel: "redis"}},
+			Data:       map[string][]byte{corev1.TLSCertKey: leaf, TLSCAKey: caCert},
+		}
+	}
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		renew  bool
+	}{
+		{name: "valid", secret: issue(caCert, caKey, v1.DefaultTLSDuration)},
+		{name: "expiring", secret: issue(caCert, caKey, time.Hour), renew: true},
+		{name: "not signed by the CA", secret: issue(otherCert, otherKey, v1.DefaultTLSDuration), renew: true},
+	}
+	handler := &TLSSecretHandler{}
+	line, err := handler.Make(newTLSSource(crd, component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		cmd := handler.Visitation(core.ComponentArgs{CustomResource: core.CustomResource{Crd: crd}, Observed: tt.secret, Desired: line.Desired})
+		if renewed := cmd != nil && cmd.Action == v1.Issue; renewed != tt.renew {
+			t.Errorf("expected the %s certificate is renewed %t, got %v", tt.name, tt.renew, cmd)
+		}
+	}
+}
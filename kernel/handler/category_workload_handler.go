@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	. "github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+)
+
+// mergeConf the desired configMap of the workload component.
+func mergeConf(source core.CustomResource) []v1.NamedProperties {
+	// Properties which should be provided from real deployed environment.
+	conf := map[string]*v1.NamedProperties{}
+	if source.Crd.GetSpec().Conf != nil {
+		for _, c := range source.Crd.GetSpec().Conf {
+			conf[c.PropertiesName()] = c
+		}
+	}
+
+	// pod conf, its mount options take precedence.
+	if getCrd(source).Properties != nil {
+		for _, v := range getCrd(source).Properties {
+			conf[v.PropertiesName()] = v
+		}
+	}
+
+	if len(conf) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(conf))
+	i := 0
+	for k := range conf {
+		keys[i] = k
+		i = i + 1
+	}
+	sort.Strings(keys)
+
+	var confList []v1.NamedProperties
+	for _, key := range keys {
+		confList = append(confList, *conf[key])
+	}
+
+	return confList
+}
+
+// defaultEnv the envs injected into all the containers of the workload.
+func defaultEnv(source core.CustomResource, serviceName string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name: Namespace,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "metadata.namespace",
+				},
+			},
+		},
+		{
+			Name: NodeName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "spec.nodeName",
+				},
+			},
+		},
+		{
+			Name: HostIp,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					APIVersion: "v1",
+					FieldPath:  "status.hostIP",
+				},
+			},
+		},
+		{
+			Name:  AppName,
+			Value: source.Crd.GetName(),
+		},
+		{
+			Name:  Category,
+			Value: string(source.ResourceMeta.GetCategory()),
+		},
+		{
+			Name:  ClusterDomain,
+			Value: os.Getenv(ClusterDomain),
+		},
+		{
+			Name:  PeerService,
+			Value: fmt.Sprintf("%s.%s.svc.%s", serviceName, source.Crd.GetNamespace(), os.Getenv(ClusterDomain)),
+		},
+	}
+}
+
+// userEnv the credentials env of the named users, they are referenced from the secret of the user.
+func userEnv(source core.CustomResource) []corev1.EnvVar {
+	crd := getCrd(source)
+	var envs []corev1.EnvVar
+	for _, user := range crd.Users {
+		if !user.Env {
+			continue
+		}
+		prefix := strings.ToUpper(strings.ReplaceAll(user.Name, "-", "_"))
+		secret := corev1.LocalObjectReference{Name: GetUserSecretName(source.Crd.GetName(), crd, user)}
+		password := &corev1.SecretKeySelector{LocalObjectReference: secret, Key: PasswordKey}
+		if user.PasswordSecretRef != nil {
+			password = user.PasswordSecretRef.DeepCopy()
+		}
+		envs = append(envs, corev1.EnvVar{
+			Name: prefix + "_USERNAME",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: secret, Key: "username"},
+			},
+		}, corev1.EnvVar{
+			Name:      prefix + "_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: password},
+		})
+	}
+	return envs
+}
+
+func mergeDefaultEnv(env []corev1.EnvVar, containers []corev1.Container) []corev1.Container {
+	for i, c := range containers {
+		var target []corev1.EnvVar
+		target = append(target, env...)
+		target = append(target, c.Env...)
+		containers[i].Env = target
+	}
+	return containers
+}
+
+// makePodTemplate the pod template shared by the workloads, with the common labels, the default envs and the config mounts.
+func makePodTemplate(source core.CustomResource, serviceName string) corev1.PodTemplateSpec {
+	crd := getCrd(source)
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      Merge(crd.Labels, crd.Template.Labels),
+			Annotations: Merge(crd.Annotations, crd.Template.Annotations),
+		},
+		Spec: *crd.Template.Spec.DeepCopy(),
+	}
+
+	//com label
+	template.Labels[InstanceLabel] = source.Crd.GetName()
+	template.Labels[CategoryLabel] = string(crd.GetCategory())
+
+	// the pods are rolled when the files of the RollingRestart policy are changed.
+	conf, err := BuildConfData(source.Crd, &ConfSource{Conf: source.Crd.GetSpec().Conf, Properties: crd.Properties})
+	if err == nil {
+		if checksum := GetConfChecksum(crd, conf); len(checksum) > 0 {
+			template.Annotations[ConfVersion] = checksum
+		}
+	}
+
+	// default env
+	envs := append(defaultEnv(source, serviceName), userEnv(source)...)
+	var spec = &template.Spec
+	spec.InitContainers = mergeDefaultEnv(envs, spec.InitContainers)
+	spec.Containers = mergeDefaultEnv(envs, spec.Containers)
+
+	// volume
+	vl, vm := BuildConfigMapMount(crd.Category, GetComponentConfigMapName(source.Crd, crd), mergeConf(source))
+	if vl != nil {
+		spec.Volumes = append(spec.Volumes, vl...)
+	}
+
+	// tls, the certificate of the cert-manager takes precedence over the cluster CA.
+	var tlsSecret, tlsPath string
+	if cert := crd.Certificate; cert != nil {
+		tlsSecret, tlsPath = GetCertificateSecretName(source.Crd.GetName(), crd), cert.MountPath
+	} else if tls := source.Crd.GetSpec().TLS; tls != nil {
+		tlsSecret, tlsPath = GetTLSSecretName(source.Crd.GetName(), crd), tls.MountPath
+	}
+	if len(tlsSecret) > 0 {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: TLSVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: tlsSecret},
+			},
+		})
+		vm = append(vm, ConfMount{VolumeMount: corev1.VolumeMount{Name: TLSVolume, MountPath: tlsPath, ReadOnly: true}})
+	}
+
+	// amount, the mount without the container names is mounted into all the containers except the init containers.
+	for _, m := range vm {
+		for c := range spec.InitContainers {
+			if containsString(m.Containers, spec.InitContainers[c].Name) {
+				spec.InitContainers[c].VolumeMounts = append(spec.InitContainers[c].VolumeMounts, m.VolumeMount)
+			}
+		}
+		for c := range spec.Containers {
+			if len(m.Containers) == 0 || containsString(m.Containers, spec.Containers[c].Name) {
+				spec.Containers[c].VolumeMounts = append(spec.Containers[c].VolumeMounts, m.VolumeMount)
+			}
+		}
+	}
+
+	return template
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorRecreateCheck the selector of the workload is immutable, it need recreate when changed.
+func selectorRecreateCheck(logger logr.Logger, observed, desired *metav1.LabelSelector, target client.Object) *core.ActionCommand {
+	var selectorO, selectorD string
+	if observed != nil {
+		selectorO = fmt.Sprintf("%v", *observed)
+	}
+	if desired != nil {
+		selectorD = fmt.Sprintf("%v", *desired)
+	}
+	if selectorO == selectorD {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s selector is changed, need recreate it", target.GetObjectKind().GroupVersionKind().Kind)
+	logger.Info(message)
+	PrintFingerDiff(selectorO, selectorD)
+	return &core.ActionCommand{
+		Action:  v1.ReCreate,
+		Message: message,
+		TargetResource: &core.ReferenceObject{
+			Category: v1.Category(target.GetLabels()[CategoryLabel]),
+			Target:   target,
+		},
+	}
+}
@@ -2,6 +2,8 @@ package kernel
 
 import (
 	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -9,12 +11,22 @@ import (
 func MakeStage(reconcile *ReconcileContext, source core.TypedCategoryComponent) (*core.ResourcesLine, error) {
 	// make k8s build-in resource
 	ch, sh := extend.GetHandler(source.GetCategory())
+	// the images left empty are resolved by the version catalog.
+	if component, ok := source.(*v1.CategoryClusterComponent); ok {
+		if err := ResolveImages(reconcile, component); err != nil {
+			reconcile.Log.Error(err, "resolve the images failed", "category", source.GetCategory(), "name", source.GetName())
+			reconcile.Recorder.Eventf(reconcile.Crd, common.Warning, "MakeFailed", "make the %s failed, %v", source.GetName(), err)
+			return nil, err
+		}
+	}
 	command, err := ch.Make(core.CustomResource{
 		ResourceMeta: source,
 		Crd:          reconcile.Crd,
 	})
 	if err != nil {
 		reconcile.Log.Error(err, "make build in resource failed", "category", source.GetCategory(), "name", source.GetName())
+		// nothing is applied, the cause is reported by the event of the cluster, e.g. the invalid conf.
+		reconcile.Recorder.Eventf(reconcile.Crd, common.Warning, "MakeFailed", "make the %s failed, %v", source.GetName(), err)
 		return command, err
 	}
 
@@ -38,5 +50,13 @@ func MakeStage(reconcile *ReconcileContext, source core.TypedCategoryComponent)
 		}
 	}
 
+	// build the api version served by the cluster.
+	if command != nil && command.Desired != nil {
+		command.Desired, err = common.ToBuildInVersion(source.GetKind(), command.Desired)
+		if err != nil {
+			reconcile.Log.Error(err, "convert build in resource version failed", "category", source.GetCategory(), "name", source.GetName())
+		}
+	}
+
 	return command, err
 }
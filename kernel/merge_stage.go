@@ -3,6 +3,7 @@ package kernel
 import (
 	"github.com/imdario/mergo"
 	"github.com/kuberator/api/core"
+	"github.com/kuberator/kernel/util"
 )
 
 func MergeStage(reconcile *ReconcileContext, resource *core.ResourcesLine) error {
@@ -20,6 +21,8 @@ func MergeStage(reconcile *ReconcileContext, resource *core.ResourcesLine) error
 			reconcile.Log.Error(err, "state finger stage merge resource cause an error", "category", resource.ResourceMeta.GetCategory(), "name", resource.ResourceMeta.GetName())
 		}
 		resource.Desired = target
+		// the operator annotations of the cluster inherited by the previous version are removed, e.g. the legacy auth.
+		util.RemoveClusterAnnotations(resource.Desired)
 	}
 
 	return nil
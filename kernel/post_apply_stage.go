@@ -1,12 +1,21 @@
 package kernel
 
 import (
+	"encoding/json"
 	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/extend"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func PostApplyStage(reconcile *ReconcileContext, command core.ActionCommand, result core.CommandResult) core.CommandResult {
+	if !result.IsError() && !result.NotEmpty() && (command.Action == v1.Create || command.Action == v1.Update) {
+		result = result.Error(retireLegacyAuth(reconcile, command.ResourceMeta, command.TargetResource.Target))
+	}
+
 	ch, sh := extend.GetHandler(command.TargetResource.Category)
 	// usr define post apply
 	if sh != nil {
@@ -32,3 +41,58 @@ func PostApplyStage(reconcile *ReconcileContext, command core.ActionCommand, res
 
 	return result
 }
+
+// retireLegacyAuth remove the v1beta1 plaintext auth of the component from the crd once the managed secret keeps it,
+// so that the crd never holds the password longer than the first reconcile. The secret is the written or the observed one,
+// the auth is removed whenever the secret keeps the same content, e.g. it is moved by the reconcile before.
+func retireLegacyAuth(reconcile *ReconcileContext, source core.TypedCategoryComponent, obj client.Object) error {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok || secret.Annotations[common.PasswordSourceAnnotation] != common.PasswordSourceLegacy {
+		return nil
+	}
+	meta, ok := source.(*core.CategoryComponentObject)
+	if !ok || meta.Reference == nil {
+		return nil
+	}
+	legacy := v1.GetLegacyAuth(reconcile.Crd.GetAnnotations(), meta.Reference.GetName())
+	if legacy == (v1.LegacyAuth{}) {
+		return nil
+	}
+	if secretValue(secret, common.PasswordKey) != legacy.Password || secretValue(secret, "auth") != legacy.Auth {
+		return nil
+	}
+	annotations := reconcile.Crd.GetAnnotations()
+	if err := v1.RemoveLegacyAuth(annotations, meta.Reference.GetName()); err != nil {
+		return err
+	}
+
+	// the merge patch only touches the annotation, the status of the crd in memory is kept.
+	var value interface{}
+	if data, ok := annotations[v1.LegacyAuthAnnotation]; ok {
+		value = data
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{v1.LegacyAuthAnnotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	crd := reconcile.Crd.DeepCopyObject().(client.Object)
+	if err = reconcile.Client.Patch(reconcile.Context, crd, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return err
+	}
+	reconcile.Crd.SetAnnotations(annotations)
+	reconcile.Crd.SetResourceVersion(crd.GetResourceVersion())
+	reconcile.Log.Info("move the v1beta1 auth into the secret ok", "name", secret.Name, "component", meta.Reference.GetName())
+	return nil
+}
+
+// secretValue the value of the key, the string data is written over the data.
+func secretValue(secret *corev1.Secret, key string) string {
+	if value, ok := secret.StringData[key]; ok {
+		return value
+	}
+	return string(secret.Data[key])
+}
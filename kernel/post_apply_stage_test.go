@@ -0,0 +1,70 @@
+package kernel
+
+import (
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+func TestRetireLegacyAuth(t *testing.T) {
+	crd := &v1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{
+		Name:      "demo",
+		Namespace: "ns",
+		Annotations: map[string]string{
+			v1.LegacyAuthAnnotation: `{"demo-redis":{"password":"secret"},"demo-sentinel":{"password":"other"}}`,
+		},
+	}}
+	reconcile := newFakeReconcile(crd)
+	secret := func(password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        "demo-redis-secret",
+				Annotations: map[string]string{common.PasswordSourceAnnotation: common.PasswordSourceLegacy},
+			},
+			Data: map[string][]byte{common.PasswordKey: []byte(password)},
+		}
+	}
+	source := func(name v1.ComponentName) core.TypedCategoryComponent {
+		component := &v1.CategoryClusterComponent{CommonCategoryComponent: v1.CommonCategoryComponent{Name: name}}
+		return &core.CategoryComponentObject{Reference: component}
+	}
+
+	// the secret does not keep the password yet.
+	if err := retireLegacyAuth(reconcile, source("demo-redis"), secret("generated")); err != nil {
+		t.Fatal(err)
+	}
+	if legacy := v1.GetLegacyAuth(crd.Annotations, "demo-redis"); legacy.Password != "secret" {
+		t.Fatalf("expected the password is kept until the secret keeps it, got %v", crd.Annotations)
+	}
+
+	if err := retireLegacyAuth(reconcile, source("demo-redis"), secret("secret")); err != nil {
+		t.Fatal(err)
+	}
+	stored := &v1.MiddlewareCluster{}
+	if err := reconcile.Client.Get(reconcile.Context, client.ObjectKeyFromObject(crd), stored); err != nil {
+		t.Fatal(err)
+	}
+	if legacy := v1.GetLegacyAuth(stored.Annotations, "demo-redis"); len(legacy.Password) > 0 {
+		t.Errorf("expected the moved password is removed from the crd, got %v", stored.Annotations)
+	}
+	if legacy := v1.GetLegacyAuth(stored.Annotations, "demo-sentinel"); legacy.Password != "other" {
+		t.Errorf("expected the password of the other component is kept, got %v", stored.Annotations)
+	}
+
+	// the unchanged secret is visited, nothing is written by the reconcile.
+	VisitationStage(reconcile, source("demo-sentinel"), secret("other"), secret("other"))
+	if err := reconcile.Client.Get(reconcile.Context, client.ObjectKeyFromObject(crd), stored); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored.Annotations[v1.LegacyAuthAnnotation]; ok {
+		t.Errorf("expected the annotation is removed with the last password, got %v", stored.Annotations)
+	}
+	if crd.ResourceVersion != stored.ResourceVersion {
+		t.Errorf("expected the crd in memory follows the patched version, got %s and %s", crd.ResourceVersion, stored.ResourceVersion)
+	}
+}
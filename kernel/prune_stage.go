@@ -0,0 +1,227 @@
+package kernel
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+)
+
+// PruneStage the components recorded in the status inventory but no longer desired will be deleted.
+// The object of the previous kind is deleted too when the kind of the component is changed under the same name.
+func PruneStage(reconcile *ReconcileContext, desired map[v1.ComponentName]v1.ComponentKind) *core.ActionCommand {
+	var command *core.ActionCommand
+	var revisions []client.Object
+	status := reconcile.Crd.GetStatus()
+	for name, state := range status.ComponentStatus {
+		if _, ok := desired[name]; ok || state == nil {
+			continue
+		}
+		observed, gone := pruneTarget(reconcile, name, state)
+		// drop it from the inventory, e.g. the objects are removed with their CRD.
+		if gone {
+			delete(status.ComponentStatus, name)
+		}
+		if observed == nil {
+			continue
+		}
+
+		// the previous revisions of the immutable configMap are pruned by the history limit.
+		if _, ok := observed.GetLabels()[common.ConfRevisionLabel]; ok && state.Kind == common.ConfigMap {
+			revisions = append(revisions, observed)
+			continue
+		}
+
+		act := pruneCommand(status, name, state, observed, true)
+		if command == nil {
+			command = act
+		} else {
+			command.Append(act)
+		}
+	}
+
+	// the inventory keeps the new kind, the object of the previous kind is pruned by the status observed at the beginning.
+	if reconcile.Status != nil {
+		for name, previous := range reconcile.Status.ComponentStatus {
+			kind, ok := desired[name]
+			if !ok || previous == nil || len(previous.Kind) == 0 || previous.Kind == kind {
+				continue
+			}
+			observed, _ := pruneTarget(reconcile, name, previous)
+			if observed == nil {
+				continue
+			}
+			act := pruneCommand(status, name, previous, observed, false)
+			if command == nil {
+				command = act
+			} else {
+				command.Append(act)
+			}
+		}
+	}
+
+	for _, observed := range pruneConfRevisions(reconcile, revisions) {
+		name := v1.ComponentName(observed.GetName())
+		act := pruneCommand(status, name, status.ComponentStatus[name], observed, true)
+		if command == nil {
+			command = act
+		} else {
+			command.Append(act)
+		}
+	}
+	return command
+}
+
+// pruneTarget the object of the inventory which is controlled by the crd, it is nil when nothing need to prune.
+// The object is gone when it is already deleted or its kind is not served any more.
+func pruneTarget(reconcile *ReconcileContext, name v1.ComponentName, state *v1.ComponentState) (client.Object, bool) {
+	if len(state.Kind) == 0 {
+		found, err := backfillKind(reconcile, name, state)
+		if err != nil {
+			return nil, false
+		}
+		if !found {
+			return nil, true
+		}
+	}
+	// the objects are removed with their CRD, e.g. the prometheus operator is uninstalled.
+	if !common.IsServed(state.Kind) {
+		return nil, true
+	}
+	if reconcile.Crd.GetSpec().GetPrunePolicy(state.Kind) == v1.PolicyRetain {
+		reconcile.Log.Info("prune stage retain the component", "kind", state.Kind, "name", name)
+		return nil, false
+	}
+
+	observed, err := reconcile.GetIfExists(reconcile.Context, reconcile.Namespace, &core.CategoryComponentObject{
+		CommonCategoryComponent: v1.CommonCategoryComponent{
+			Name:      name,
+			Category:  state.Category,
+			Component: v1.Component{Kind: state.Kind},
+		},
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	// the resource is already deleted.
+	if observed == nil {
+		return nil, true
+	}
+
+	// only prune the resource controlled by the current crd.
+	if !isControlledBy(observed, reconcile.Crd) {
+		reconcile.Log.Info("prune stage skip the resource not controlled by the crd", "kind", state.Kind, "name", name)
+		return nil, false
+	}
+	return observed, false
+}
+
+// backfillKind the inventory recorded before the kind is recorded, the kind is found by the live object of the name which
+// is controlled by the crd. It is not found when no such object exists.
+func backfillKind(reconcile *ReconcileContext, name v1.ComponentName, state *v1.ComponentState) (bool, error) {
+	for _, kind := range common.BuildInKinds() {
+		observed, err := reconcile.GetIfExists(reconcile.Context, reconcile.Namespace, &core.CategoryComponentObject{
+			CommonCategoryComponent: v1.CommonCategoryComponent{Name: name, Component: v1.Component{Kind: kind}},
+		})
+		if err != nil {
+			return false, err
+		}
+		if observed != nil && isControlledBy(observed, reconcile.Crd) {
+			reconcile.Log.Info("prune stage backfill the kind of the component", "kind", kind, "name", name)
+			state.Kind = kind
+			state.Category = v1.Category(observed.GetLabels()[common.CategoryLabel])
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pruneConfRevisions the previous revisions of the immutable configMaps which are out of the history limit of the component.
+// The newest revisions are kept, and the revision mounted by any pod of the cluster is kept until the pod is restarted.
+func pruneConfRevisions(reconcile *ReconcileContext, revisions []client.Object) []client.Object {
+	if len(revisions) == 0 {
+		return nil
+	}
+	var pods corev1.PodList
+	err := reconcile.List(reconcile.Context, metav1.ObjectMeta{
+		Namespace: reconcile.Namespace,
+		Labels:    map[string]string{common.InstanceLabel: reconcile.Crd.GetName()},
+	}, &pods)
+	if err != nil {
+		reconcile.Log.Error(err, "prune stage skip the conf revisions, list the pods failed")
+		return nil
+	}
+	mounted := map[string]bool{}
+	for _, pod := range pods.Items {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.ConfigMap != nil {
+				mounted[vol.ConfigMap.Name] = true
+			}
+		}
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		ti, tj := revisions[i].GetCreationTimestamp(), revisions[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+	kept := map[string]int32{}
+	var pruned []client.Object
+	for _, observed := range revisions {
+		if mounted[observed.GetName()] {
+			continue
+		}
+		category := observed.GetLabels()[common.ReferenceLabel]
+		var limit int32
+		if c, ok := reconcile.Crd.GetSpec().GetCategoryResource(v1.Category(category)).(*v1.CategoryClusterComponent); ok && c.ImmutableConf != nil {
+			limit = v1.DefaultConfRevisionHistoryLimit
+			if c.ImmutableConf.RevisionHistoryLimit != nil {
+				limit = *c.ImmutableConf.RevisionHistoryLimit
+			}
+		}
+		if kept[category] < limit {
+			kept[category]++
+			continue
+		}
+		pruned = append(pruned, observed)
+	}
+	return pruned
+}
+
+// pruneCommand delete the observed object itself, it is dropped from the inventory after deleted when the drop is true.
+func pruneCommand(status *v1.MiddlewareClusterStatus, name v1.ComponentName, state *v1.ComponentState, observed client.Object, drop bool) *core.ActionCommand {
+	return &core.ActionCommand{
+		Action:  v1.Delete,
+		Message: fmt.Sprintf("%s %s is removed from the spec, prune it", state.Kind, name),
+		ResourceMeta: &core.CategoryComponentObject{
+			CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name:      name,
+				Category:  state.Category,
+				Component: v1.Component{Kind: state.Kind},
+			},
+		},
+		TargetResource: &core.ReferenceObject{
+			Category: state.Category,
+			Target:   observed,
+		},
+		Callback: func(result *core.CommandResult, cli client.Client, i ...interface{}) error {
+			if !result.IsError() && drop {
+				delete(status.ComponentStatus, name)
+			}
+			return nil
+		},
+	}
+}
+
+func isControlledBy(obj client.Object, owner client.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller && ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+	return false
+}
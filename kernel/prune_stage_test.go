@@ -0,0 +1,118 @@
+package kernel
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+func TestPruneStage(t *testing.T) {
+	controller := true
+	crd := &v1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"}}
+	owned := []metav1.OwnerReference{{Kind: "MiddlewareCluster", Name: "demo", UID: "demo", Controller: &controller}}
+	labels := map[string]string{common.InstanceLabel: "demo"}
+	// the user object copies the labels of the pruned one, it must never be deleted.
+	superset := map[string]string{common.InstanceLabel: "demo", "team": "storage"}
+	objs := []client.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-old", UID: "old", Labels: labels, OwnerReferences: owned}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-user", UID: "user", Labels: superset}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-copy", UID: "copy", Labels: superset, OwnerReferences: owned}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk", UID: "zk", Labels: labels, OwnerReferences: owned}},
+	}
+	reconcile := newFakeReconcile(crd, objs...)
+	status := crd.GetStatus()
+	inventory := func(kind v1.ComponentKind) *v1.ComponentState {
+		state := v1.NewComponentState(v1.Success, "ok", nil)
+		state.Kind = kind
+		return state
+	}
+	status.ComponentStatus["demo-old"] = inventory(common.Service)
+	status.ComponentStatus["demo-user"] = inventory(common.Service)
+	status.ComponentStatus["demo-copy"] = inventory(common.Service)
+	status.ComponentStatus["demo-gone"] = inventory(common.Service)
+	// the kind of the component is changed from the StatefulSet to the Deployment.
+	reconcile.Status = status.DeepCopy()
+	reconcile.Status.ComponentStatus["demo-zk"] = inventory(common.StatefulSet)
+	status.ComponentStatus["demo-zk"] = inventory(common.Deployment)
+
+	command := PruneStage(reconcile, map[v1.ComponentName]v1.ComponentKind{
+		"demo-copy": common.Service,
+		"demo-zk":   common.Deployment,
+	})
+	var pruned []string
+	for cmd := command; cmd != nil; cmd = cmd.Next {
+		pruned = append(pruned, cmd.TargetResource.Target.GetName())
+		result := Apply(reconcile, cmd)
+		if result.IsError() {
+			t.Fatal(result.LastError())
+		}
+		if cmd.Callback != nil {
+			_ = cmd.Callback(&result, reconcile.Client)
+		}
+	}
+	if len(pruned) != 2 {
+		t.Fatalf("expected the removed service and the previous kind are pruned, got %v", pruned)
+	}
+
+	exists := func(obj client.Object) bool {
+		err := reconcile.Client.Get(reconcile.Context, client.ObjectKeyFromObject(obj), obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+	if exists(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-old"}}) {
+		t.Errorf("expected the removed service is pruned")
+	}
+	if exists(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk"}}) {
+		t.Errorf("expected the StatefulSet of the previous kind is pruned")
+	}
+	if !exists(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-user"}}) ||
+		!exists(&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-copy"}}) {
+		t.Errorf("expected the objects with the superset labels are kept")
+	}
+	if _, ok := status.ComponentStatus["demo-old"]; ok {
+		t.Errorf("expected the pruned service is dropped from the inventory")
+	}
+	if _, ok := status.ComponentStatus["demo-gone"]; ok {
+		t.Errorf("expected the deleted service is dropped from the inventory")
+	}
+	if state := status.ComponentStatus["demo-zk"]; state == nil || state.Kind != common.Deployment {
+		t.Errorf("expected the inventory keeps the new kind, got %v", state)
+	}
+}
+
+func TestPruneStageWithoutKind(t *testing.T) {
+	controller := true
+	crd := &v1.MiddlewareCluster{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"}}
+	owned := []metav1.OwnerReference{{Kind: "MiddlewareCluster", Name: "demo", UID: "demo", Controller: &controller}}
+	labels := map[string]string{common.InstanceLabel: "demo", common.CategoryLabel: "zk"}
+	reconcile := newFakeReconcile(crd,
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk", Labels: labels, OwnerReferences: owned}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-user", Labels: labels}})
+	status := crd.GetStatus()
+	// the inventory recorded before the kind is recorded.
+	for _, name := range []v1.ComponentName{"demo-zk", "demo-user", "demo-gone"} {
+		status.ComponentStatus[name] = v1.NewComponentState(v1.Success, "ok", nil)
+	}
+	reconcile.Status = status.DeepCopy()
+
+	command := PruneStage(reconcile, map[v1.ComponentName]v1.ComponentKind{})
+	if command == nil || command.Next != nil || command.TargetResource.Target.GetName() != "demo-zk" {
+		t.Fatalf("expected only the controlled StatefulSet is pruned, got %v", command)
+	}
+	if state := status.ComponentStatus["demo-zk"]; state.Kind != common.StatefulSet || state.Category != "zk" {
+		t.Errorf("expected the kind is backfilled from the live object, got %v", state)
+	}
+	if _, ok := status.ComponentStatus["demo-gone"]; ok {
+		t.Errorf("expected the component without any live object is dropped from the inventory")
+	}
+	if _, ok := status.ComponentStatus["demo-user"]; ok {
+		t.Errorf("expected the component without any controlled object is dropped from the inventory")
+	}
+}
@@ -1,13 +1,32 @@
 package kernel
 
 import (
+	"fmt"
 	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/util"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strings"
 )
 
 func ReduceStage(reconcile *ReconcileContext, result core.CommandResult) core.CommandResult {
 	reconcile.Crd.GetStatus().Gen()
+	ReduceConditions(reconcile, result)
+	ReduceUsers(reconcile)
+	ReduceCatalog(reconcile)
 	status := reconcile.Crd.GetStatus()
+
+	// nothing changed, avoid the status write.
+	if !IsStatusChanged(reconcile.Status, status) {
+		reconcile.Log.Info("crd status not changed, skip update.")
+		return result
+	}
+
 	var err error
 	if err = reconcile.UpdateStatus(reconcile.Context, reconcile.Crd); err != nil {
 		if apierrors.IsConflict(err) {
@@ -30,3 +49,214 @@ func ReduceStage(reconcile *ReconcileContext, result core.CommandResult) core.Co
 
 	return result.Error(err)
 }
+
+// ReduceUsers report the named users of the components from the state of their secrets.
+func ReduceUsers(reconcile *ReconcileContext) {
+	status := reconcile.Crd.GetStatus()
+	var users []v1.UserStatus
+	for _, component := range reconcile.Crd.GetSpec().Components {
+		if component == nil {
+			continue
+		}
+		for _, user := range component.Users {
+			secret := util.GetUserSecretName(reconcile.Crd.GetName(), component, user)
+			state := status.ComponentStatus[v1.ComponentName(secret)]
+			users = append(users, v1.UserStatus{
+				Component: component.GetCategory(),
+				Name:      user.Name,
+				Role:      user.Role,
+				Secret:    secret,
+				// the existing secret is provided by the user.
+				Ready:       len(user.ExistingSecret) > 0 || (state != nil && state.State != v1.Deleted),
+				Provisioned: state != nil && state.GetActionState(v1.Provision).State == v1.Success,
+			})
+		}
+	}
+	status.Users = users
+}
+
+// ReduceCatalog report whether the version of the cluster is listed by its catalog and not deprecated.
+func ReduceCatalog(reconcile *ReconcileContext) {
+	status := reconcile.Crd.GetStatus()
+	spec := reconcile.Crd.GetSpec()
+	if len(spec.Catalog) == 0 {
+		meta.RemoveStatusCondition(&status.Conditions, v1.ConditionVersionSupported)
+		return
+	}
+	condition := metav1.Condition{
+		Type:               v1.ConditionVersionSupported,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: reconcile.Crd.GetGeneration(),
+	}
+	var version *v1.CatalogVersion
+	if reconcile.Catalog != nil {
+		version = reconcile.Catalog.GetVersion(spec.Version)
+	}
+	switch {
+	case reconcile.Catalog == nil:
+		condition.Reason = "CatalogNotFound"
+		condition.Message = fmt.Sprintf("the catalog %s is not found", spec.Catalog)
+	case version == nil:
+		condition.Reason = "VersionUnlisted"
+		condition.Message = fmt.Sprintf("the version %s is not listed by the catalog %s", spec.Version, spec.Catalog)
+	case version.Deprecated:
+		condition.Reason = "VersionDeprecated"
+		condition.Message = fmt.Sprintf("the version %s is deprecated by the catalog %s", spec.Version, spec.Catalog)
+		if len(version.Message) > 0 {
+			condition.Message = fmt.Sprintf("%s, %s", condition.Message, version.Message)
+		}
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "VersionListed"
+		condition.Message = fmt.Sprintf("the version %s is listed by the catalog %s", spec.Version, spec.Catalog)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// ReduceConditions compute the cluster conditions, phase and observed generation from the component status.
+func ReduceConditions(reconcile *ReconcileContext, result core.CommandResult) {
+	status := reconcile.Crd.GetStatus()
+	generation := reconcile.Crd.GetGeneration()
+	paused := reconcile.Crd.GetLabels()[InstancePauseLabel] == "true"
+
+	names := make([]string, 0, len(status.ComponentStatus))
+	for name := range status.ComponentStatus {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var failed, progressing, notReady []string
+	restarting := false
+	for _, name := range names {
+		state := status.ComponentStatus[v1.ComponentName(name)]
+		if state == nil {
+			continue
+		}
+		if state.State == v1.NotReady {
+			notReady = append(notReady, name)
+		}
+		for act, as := range state.ActionState {
+			switch as.State {
+			case v1.Success:
+			case v1.Failed:
+				failed = append(failed, fmt.Sprintf("%s %s: %s", name, act, as.Message))
+			default:
+				progressing = append(progressing, fmt.Sprintf("%s %s", name, act))
+				restarting = restarting || act == v1.Restart || act == v1.FailOver || act == v1.ReCreate
+			}
+		}
+	}
+	upgrading := false
+	if upgrade := status.Upgrade; upgrade != nil {
+		cause := fmt.Sprintf("upgrade %s to %s", status.CurrentVersion, status.TargetVersion)
+		if len(upgrade.Step) > 0 {
+			cause = fmt.Sprintf("%s at the step %s", cause, upgrade.Step)
+		}
+		if upgrade.State == v1.Failed {
+			failed = append(failed, fmt.Sprintf("%s: %s", cause, upgrade.Message))
+		} else {
+			progressing = append(progressing, cause)
+			upgrading = true
+		}
+	}
+	if result.IsError() {
+		failed = append(failed, result.LastError().Error())
+	}
+	sort.Strings(failed)
+	sort.Strings(progressing)
+
+	created := reconcile.Status != nil && reconcile.Status.ObservedGeneration > 0
+	if !result.IsError() && !paused {
+		status.ObservedGeneration = generation
+	}
+
+	setCondition(status, generation, v1.ConditionPaused, paused, "InstancePaused", "Reconciling",
+		fmt.Sprintf("the label %s is true", InstancePauseLabel))
+	setCondition(status, generation, v1.ConditionDegraded, len(failed) > 0, "ActionFailed", "AsExpected",
+		strings.Join(failed, "; "))
+	setCondition(status, generation, v1.ConditionProgressing, len(progressing) > 0, "ActionInProgress", "Reconciled",
+		strings.Join(progressing, "; "))
+	ready := !paused && len(failed) == 0 && len(progressing) == 0 && len(notReady) == 0
+	notReadyMessage := strings.Join(append(notReady, progressing...), "; ")
+	if paused {
+		notReadyMessage = "the reconcile is paused"
+	} else if len(failed) > 0 {
+		notReadyMessage = strings.Join(failed, "; ")
+	}
+	setCondition(status, generation, v1.ConditionReady, ready, "ComponentsReady", "ComponentsNotReady", notReadyMessage)
+
+	switch {
+	case paused:
+		status.Phase = v1.Suspended
+	case len(failed) > 0:
+		status.Phase = v1.Failed
+	case len(progressing) > 0 && upgrading:
+		status.Phase = v1.Upgrading
+	case len(progressing) > 0 && restarting:
+		status.Phase = v1.Restarting
+	case len(progressing) > 0 && !created:
+		status.Phase = v1.Creating
+	case len(progressing) > 0:
+		status.Phase = v1.Updating
+	case len(notReady) > 0:
+		status.Phase = v1.NotReady
+	default:
+		status.Phase = v1.Running
+	}
+}
+
+func setCondition(status *v1.MiddlewareClusterStatus, generation int64, conditionType string, ok bool, trueReason, falseReason, message string) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             falseReason,
+		ObservedGeneration: generation,
+	}
+	if ok {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = trueReason
+		condition.Message = message
+	} else if conditionType == v1.ConditionReady {
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// IsStatusChanged compare the status without the update time and the runtime fields.
+func IsStatusChanged(observed, desired *v1.MiddlewareClusterStatus) bool {
+	if observed == nil || desired == nil {
+		return observed != desired
+	}
+	return !equality.Semantic.DeepEqual(canonicalStatus(observed), canonicalStatus(desired))
+}
+
+func canonicalStatus(status *v1.MiddlewareClusterStatus) *v1.MiddlewareClusterStatus {
+	target := status.DeepCopy()
+	target.UpdateTimestamp = nil
+	for i := range target.Conditions {
+		target.Conditions[i].LastTransitionTime = metav1.Time{}
+	}
+	for _, state := range target.ComponentStatus {
+		if state == nil {
+			continue
+		}
+		state.Meta = ""
+		state.NextUid = ""
+		state.UpdateTimestamp = nil
+		if len(state.Details) == 0 {
+			state.Details = nil
+		}
+		if len(state.ActionState) == 0 {
+			state.ActionState = nil
+		}
+		for act, as := range state.ActionState {
+			as.UpdateTimestamp = nil
+			for key, step := range as.Steps {
+				step.UpdateTimestamp = nil
+				as.Steps[key] = step
+			}
+			state.ActionState[act] = as
+		}
+	}
+	return target
+}
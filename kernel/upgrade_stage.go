@@ -0,0 +1,222 @@
+package kernel
+
+import (
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	"github.com/kuberator/kernel/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"time"
+)
+
+// UpgradeStage advance the upgrade plan from the current version to the spec version, one step in one reconcile at least.
+// The categories of the components which wait their turn are returned, their workloads are not applied until the previous
+// steps are finished. The refused upgrade holds all the components, so that nothing of the new version is applied.
+func UpgradeStage(reconcile *ReconcileContext) (map[v1.Category]bool, error) {
+	status := reconcile.Crd.GetStatus()
+	spec := reconcile.Crd.GetSpec()
+	current, target := status.CurrentVersion, spec.Version
+	// the new cluster and the cluster without the plan run the spec version at once.
+	if len(current) == 0 || current == target || spec.Upgrade == nil {
+		if status.Upgrade != nil {
+			reconcile.Log.Info("upgrade stage finish the upgrade without the plan", "version", target)
+		}
+		status.CurrentVersion = target
+		status.TargetVersion = target
+		status.Upgrade = nil
+		return nil, nil
+	}
+
+	steps := spec.GetUpgradeSteps()
+	upgrade := status.Upgrade
+	// the target is changed, a new upgrade begins from the first step.
+	if upgrade == nil || status.TargetVersion != target {
+		now := metav1.Now()
+		status.TargetVersion = target
+		upgrade = &v1.UpgradeStatus{State: v1.Upgrading, StartTimestamp: &now, StepTimestamp: &now}
+		if !spec.Upgrade.IsAllowed(current, target) {
+			upgrade.State = v1.Failed
+			upgrade.Message = fmt.Sprintf("the upgrade from %s to %s is not supported by the upgrade paths", current, target)
+			reconcile.Recorder.Eventf(reconcile.Crd, common.Warning, "UpgradeRefused", upgrade.Message)
+		} else if len(steps) > 0 {
+			upgrade.Step = steps[0]
+			reconcile.Recorder.Eventf(reconcile.Crd, common.Normal, "UpgradeStarted", "upgrade from %s to %s", current, target)
+		}
+		status.Upgrade = upgrade
+	}
+	// the refused upgrade waits the spec version is changed.
+	if len(upgrade.Step) == 0 && upgrade.State == v1.Failed {
+		return heldCategories(steps, 0), nil
+	}
+
+	for {
+		i := indexOf(steps, upgrade.Step)
+		// the step is removed from the plan, begin again.
+		if i < 0 {
+			if len(steps) == 0 {
+				break
+			}
+			i = 0
+			nextUpgradeStep(upgrade, steps[0])
+		}
+		done, err := advanceUpgradeStep(reconcile, upgrade, current, target)
+		if err != nil || !done {
+			return heldCategories(steps, i+1), err
+		}
+		reconcile.Log.Info("upgrade stage finish the step", "step", upgrade.Step, "from", current, "to", target)
+		if i+1 == len(steps) {
+			break
+		}
+		nextUpgradeStep(upgrade, steps[i+1])
+	}
+
+	reconcile.Recorder.Eventf(reconcile.Crd, common.Normal, "UpgradeSucceeded", "upgrade from %s to %s", current, target)
+	status.CurrentVersion = target
+	status.Upgrade = nil
+	return nil, nil
+}
+
+func nextUpgradeStep(upgrade *v1.UpgradeStatus, step string) {
+	now := metav1.Now()
+	upgrade.Step = step
+	upgrade.State = v1.Upgrading
+	upgrade.Message = ""
+	upgrade.StepTimestamp = &now
+	upgrade.Generation = 0
+}
+
+func indexOf(steps []string, step string) int {
+	for i, s := range steps {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// heldCategories the categories of the components from the step, they wait the previous steps.
+func heldCategories(steps []string, from int) map[v1.Category]bool {
+	held := map[v1.Category]bool{}
+	for i := from; i < len(steps); i++ {
+		if steps[i] != v1.UpgradePreHookStep && steps[i] != v1.UpgradePostHookStep {
+			held[v1.Category(steps[i])] = true
+		}
+	}
+	return held
+}
+
+// advanceUpgradeStep run the hook Job of the step, or check the health gate of the component.
+func advanceUpgradeStep(reconcile *ReconcileContext, upgrade *v1.UpgradeStatus, current, target string) (bool, error) {
+	plan := reconcile.Crd.GetSpec().Upgrade
+	switch upgrade.Step {
+	case v1.UpgradePreHookStep:
+		return runUpgradeHook(reconcile, upgrade, plan.PreUpgrade, current, target)
+	case v1.UpgradePostHookStep:
+		return runUpgradeHook(reconcile, upgrade, plan.PostUpgrade, current, target)
+	}
+
+	component, ok := reconcile.Crd.GetSpec().GetCategoryResource(v1.Category(upgrade.Step)).(*v1.CategoryClusterComponent)
+	if !ok || component == nil {
+		return true, nil
+	}
+	// the workload is applied after the stage, it is checked after the delay.
+	elapsed := time.Since(upgrade.StepTimestamp.Time)
+	if elapsed < plan.HealthGate.Delay.Duration {
+		return false, nil
+	}
+	workload := common.NewBuildInResource(component.GetKind(), types.NamespacedName{
+		Namespace: reconcile.Namespace,
+		Name:      string(component.GetName()),
+	})
+	if err := reconcile.Get(reconcile.Context, workload); client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	// the workload of the previous template may be ready too, so the generation applied by the step is waited.
+	state := reconcile.Crd.GetStatus().ComponentStatus[component.GetName()]
+	applied := upgrade.Generation > 0 && workload.GetGeneration() >= upgrade.Generation
+	if applied && util.IsWorkloadUpgraded(workload) && (state == nil || state.IsActionOk()) {
+		return true, nil
+	}
+	// the upgrade goes on when the component becomes ready later.
+	if elapsed > plan.HealthGate.Timeout.Duration && upgrade.State != v1.Failed {
+		upgrade.State = v1.Failed
+		upgrade.Message = fmt.Sprintf("the %s is not ready in %s", component.GetName(), plan.HealthGate.Timeout.Duration)
+		reconcile.Recorder.Eventf(reconcile.Crd, common.Warning, "UpgradeFailed", upgrade.Message)
+	}
+	return false, nil
+}
+
+// runUpgradeHook create the hook Job of the target version and wait until it is completed.
+// The Job of the previous upgrade is deleted, and the failed Job is created again when it is deleted.
+func runUpgradeHook(reconcile *ReconcileContext, upgrade *v1.UpgradeStatus, hook *v1.UpgradeHook, current, target string) (bool, error) {
+	if hook == nil {
+		return true, nil
+	}
+	desired := makeUpgradeHookJob(reconcile, upgrade.Step, hook, current, target)
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: desired.Namespace, Name: desired.Name}}
+	err := reconcile.Get(reconcile.Context, job)
+	if client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+	if err != nil {
+		reconcile.Log.Info("upgrade stage create the hook job", "name", desired.Name, "version", target)
+		upgrade.State = v1.Upgrading
+		upgrade.Message = fmt.Sprintf("run the hook job %s", desired.Name)
+		return false, reconcile.Create(reconcile.Context, desired)
+	}
+	if job.Annotations[common.UpgradeVersionAnnotation] != target {
+		reconcile.Log.Info("upgrade stage delete the hook job of the previous upgrade", "name", job.Name)
+		if job.DeletionTimestamp != nil {
+			return false, nil
+		}
+		return false, client.IgnoreNotFound(reconcile.Client.Delete(reconcile.Context, job, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			if upgrade.State != v1.Failed {
+				upgrade.State = v1.Failed
+				upgrade.Message = fmt.Sprintf("the hook job %s failed, %s, delete it to run again", job.Name, condition.Message)
+				reconcile.Recorder.Eventf(reconcile.Crd, common.Warning, "UpgradeFailed", upgrade.Message)
+			}
+		}
+	}
+	return false, nil
+}
+
+// makeUpgradeHookJob the hook Job of the step, the versions of the upgrade are injected as the env of its containers.
+func makeUpgradeHookJob(reconcile *ReconcileContext, step string, hook *v1.UpgradeHook, current, target string) *batchv1.Job {
+	category := v1.Category(strings.ToLower(step))
+	spec := *hook.Job.DeepCopy()
+	envs := []corev1.EnvVar{{Name: common.UpgradeFrom, Value: current}, {Name: common.UpgradeTo, Value: target}}
+	spec.Template.Spec.InitContainers = util.ModifyEnv(spec.Template.Spec.InitContainers, envs...)
+	spec.Template.Spec.Containers = util.ModifyEnv(spec.Template.Spec.Containers, envs...)
+	if len(spec.Template.Spec.RestartPolicy) == 0 {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: reconcile.Namespace,
+			Name:      util.GetComponentShotName(reconcile.Crd.GetName(), category),
+			Labels: util.Merge(reconcile.Crd.GetLabels(), map[string]string{
+				common.InstanceLabel: reconcile.Crd.GetName(),
+				common.CategoryLabel: string(category),
+			}),
+			Annotations:     map[string]string{common.UpgradeVersionAnnotation: target},
+			OwnerReferences: []metav1.OwnerReference{util.ToOwnerReference(core.CustomResource{Crd: reconcile.Crd})},
+		},
+		Spec: spec,
+	}
+}
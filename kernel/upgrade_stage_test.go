@@ -0,0 +1,133 @@
+package kernel
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+// newUpgradeReconcile the cluster running 1.0 of the follower and leader, the spec version is 2.0.
+func newUpgradeReconcile(plan *v1.UpgradePlan) *ReconcileContext {
+	replicas := int32(1)
+	component := func(category v1.Category) *v1.CategoryClusterComponent {
+		return &v1.CategoryClusterComponent{
+			CommonCategoryComponent: v1.CommonCategoryComponent{
+				Name: v1.ComponentName("demo-" + category), Category: category, Component: v1.Component{Kind: common.StatefulSet},
+			},
+			Replicas: &replicas,
+		}
+	}
+	plan.HealthGate = &v1.UpgradeHealthGate{Delay: &metav1.Duration{}, Timeout: &metav1.Duration{Duration: time.Minute}}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", UID: "demo"},
+		Spec: v1.MiddlewareClusterSpec{
+			Version:    "2.0",
+			Upgrade:    plan,
+			Components: []*v1.CategoryClusterComponent{component("leader"), component("follower")},
+		},
+		Status: v1.MiddlewareClusterStatus{CurrentVersion: "1.0", TargetVersion: "1.0"},
+	}
+	return newFakeReconcile(crd, newUpgradeWorkload("demo-follower", 1, 1), newUpgradeWorkload("demo-leader", 1, 1))
+}
+
+// newUpgradeWorkload the ready StatefulSet, the generation is observed by the controller when it equals the observed.
+func newUpgradeWorkload(name string, generation, observed int64) *appsv1.StatefulSet {
+	replicas := int32(1)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Generation: generation},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{ObservedGeneration: observed, ReadyReplicas: 1, UpdatedReplicas: 1},
+	}
+}
+
+func rollWorkload(t *testing.T, reconcile *ReconcileContext, name string, generation, observed int64) {
+	workload := &appsv1.StatefulSet{}
+	if err := reconcile.Client.Get(reconcile.Context, client.ObjectKey{Namespace: "ns", Name: name}, workload); err != nil {
+		t.Fatal(err)
+	}
+	workload.Generation = generation
+	workload.Status.ObservedGeneration = observed
+	if err := reconcile.Client.Update(reconcile.Context, workload); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeStage(t *testing.T) {
+	reconcile := newUpgradeReconcile(&v1.UpgradePlan{Order: []v1.Category{"follower", "leader"}})
+	status := reconcile.Crd.GetStatus()
+
+	// the workload of the previous template is ready, but it is not applied by the step yet.
+	held, err := UpgradeStage(reconcile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Upgrade == nil || status.Upgrade.Step != "follower" || !held["leader"] || held["follower"] {
+		t.Fatalf("expected the follower is upgraded first and the leader waits, got %v %v", status.Upgrade, held)
+	}
+
+	// the step applies the template of the target version, it waits the controller rolls it out.
+	status.Upgrade.Generation = 2
+	rollWorkload(t, reconcile, "demo-follower", 2, 1)
+	if _, err = UpgradeStage(reconcile); err != nil || status.Upgrade.Step != "follower" {
+		t.Fatalf("expected the follower waits its rollout, got %v %v", status.Upgrade, err)
+	}
+
+	rollWorkload(t, reconcile, "demo-follower", 2, 2)
+	held, err = UpgradeStage(reconcile)
+	if err != nil || status.Upgrade.Step != "leader" || held["leader"] || status.Upgrade.Generation != 0 {
+		t.Fatalf("expected the leader begins after the follower is rolled out, got %v %v %v", status.Upgrade, held, err)
+	}
+
+	// the template of the leader is not changed by the target version, its generation is recorded as it is.
+	status.Upgrade.Generation = 1
+	if _, err = UpgradeStage(reconcile); err != nil {
+		t.Fatal(err)
+	}
+	if status.Upgrade != nil || status.CurrentVersion != "2.0" {
+		t.Errorf("expected the upgrade is finished, got %v %s", status.Upgrade, status.CurrentVersion)
+	}
+}
+
+func TestUpgradeStageRefused(t *testing.T) {
+	reconcile := newUpgradeReconcile(&v1.UpgradePlan{Paths: []v1.UpgradePath{{From: "1.*", To: []string{"1.*"}}}})
+	status := reconcile.Crd.GetStatus()
+	held, err := UpgradeStage(reconcile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Upgrade == nil || status.Upgrade.State != v1.Failed || !held["leader"] || !held["follower"] {
+		t.Errorf("expected the refused upgrade holds all the components, got %v %v", status.Upgrade, held)
+	}
+	if status.CurrentVersion != "1.0" || status.TargetVersion != "2.0" {
+		t.Errorf("expected the current version is kept, got %s %s", status.CurrentVersion, status.TargetVersion)
+	}
+}
+
+func TestUpgradeStageTimeout(t *testing.T) {
+	reconcile := newUpgradeReconcile(&v1.UpgradePlan{Order: []v1.Category{"follower"}})
+	status := reconcile.Crd.GetStatus()
+	if _, err := UpgradeStage(reconcile); err != nil {
+		t.Fatal(err)
+	}
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	status.Upgrade.StepTimestamp = &started
+	status.Upgrade.Generation = 2
+	rollWorkload(t, reconcile, "demo-follower", 2, 1)
+	held, err := UpgradeStage(reconcile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Upgrade.State != v1.Failed || status.Upgrade.Step != "follower" || !held["leader"] {
+		t.Errorf("expected the step is failed by the health gate, got %v %v", status.Upgrade, held)
+	}
+
+	// the upgrade goes on when the component becomes ready later.
+	rollWorkload(t, reconcile, "demo-follower", 2, 2)
+	if _, err = UpgradeStage(reconcile); err != nil || status.Upgrade.Step != "leader" || status.Upgrade.State != v1.Upgrading {
+		t.Errorf("expected the next step begins, got %v %v", status.Upgrade, err)
+	}
+}
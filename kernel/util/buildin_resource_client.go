@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"github.com/go-logr/logr"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	"github.com/kuberator/kernel/common"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
@@ -24,6 +25,8 @@ import (
 type ReconcileClient struct {
 	Log    logr.Logger   `json:"log,omitempty"`
 	Client client.Client `json:"client,omitempty"`
+	// Config the rest config which the commands are executed in the pods by.
+	Config *rest.Config `json:"-"`
 }
 
 func (cli *ReconcileClient) Get(ctx context.Context, obj client.Object) error {
@@ -65,10 +68,18 @@ func (cli *ReconcileClient) Delete(ctx context.Context, observed client.Object)
 	return client.IgnoreNotFound(cli.Client.Delete(ctx, observed))
 }
 
-func (cli *ReconcileClient) DeleteAllOf(ctx context.Context, object *core.ReferenceObject) error {
-	var labels client.MatchingLabels
-	labels = object.Target.GetLabels()
-	return client.IgnoreNotFound(cli.Client.DeleteAllOf(ctx, object.Target, client.InNamespace(object.Target.GetNamespace()), labels))
+// DeleteObject delete the object itself, the uid precondition avoids deleting the object created again with the same name.
+// The object which is deleted or created again is not an error.
+func (cli *ReconcileClient) DeleteObject(ctx context.Context, observed client.Object) error {
+	var opts []client.DeleteOption
+	if uid := observed.GetUID(); len(uid) > 0 {
+		opts = append(opts, client.Preconditions{UID: &uid})
+	}
+	err := cli.Client.Delete(ctx, observed, opts...)
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
 }
 
 func (reconcile *ReconcileClient) GetIfExists(ctx context.Context, namespace string, source core.TypedCategoryComponent) (client.Object, error) {
@@ -88,174 +99,249 @@ func (reconcile *ReconcileClient) GetIfExists(ctx context.Context, namespace str
 	return observed, err
 }
 
-func (cli *ReconcileClient) ReCreate(ctx context.Context, observed client.Object) error {
+// ReCreate delete the resource with orphan policy and create it again.
+// It will advance one step in every reconcile, and return true when the resource is recreated.
+func (cli *ReconcileClient) ReCreate(ctx context.Context, state *v1.ComponentState, observed client.Object) (bool, error) {
 	reconciledMeta, err := meta.Accessor(observed)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Using a precondition here to make sure we delete the version of the resource we intend to delete and
-	// to avoid accidentally deleting a resource already recreated for example
-	uidToDelete := reconciledMeta.GetUID()
-	resourceVersionToDelete := reconciledMeta.GetResourceVersion()
-
-	if len(uidToDelete) == 0 && len(resourceVersionToDelete) == 0 {
-		err = cli.Get(ctx, observed)
-		if err != nil {
-			return err
-		}
-	}
-
-	propagationPolicy := metav1.DeletePropagationOrphan
 	kind := v1.ComponentKind(observed.GetObjectKind().GroupVersionKind().Kind)
 	namespaceName := types.NamespacedName{
 		Namespace: observed.GetNamespace(),
 		Name:      observed.GetName(),
 	}
 
-	opts := client.DeleteOptions{
-		Preconditions: &metav1.Preconditions{
-			UID:             &uidToDelete,
-			ResourceVersion: &resourceVersionToDelete,
-		},
-		PropagationPolicy: &propagationPolicy,
-	}
+	step := state.GetActionStep(v1.ReCreate, observed.GetName())
+	if step.State != v1.Terminated {
+		// Using a precondition here to make sure we delete the version of the resource we intend to delete and
+		// to avoid accidentally deleting a resource already recreated for example
+		uidToDelete := reconciledMeta.GetUID()
+		resourceVersionToDelete := reconciledMeta.GetResourceVersion()
 
-	err = cli.Client.Delete(ctx, observed, &opts)
-	if err != nil && !apierrors.IsNotFound(err) {
-		cli.Log.Error(err, "delete resource error", "name", observed.GetName())
-		return err
-	}
-
-	deadLine := time.Now().Add(GetRestartTimeout())
-	for true {
-		// resourceVersion should not be set on objects to be created.
-		observed.SetUID("")
-		observed.SetResourceVersion("")
-		observed.SetFinalizers(nil)
-		err = cli.Client.Create(ctx, observed)
-		if err != nil && !apierrors.IsAlreadyExists(err) {
-			return err
+		if len(uidToDelete) == 0 && len(resourceVersionToDelete) == 0 {
+			err = cli.Get(ctx, observed)
+			if err != nil {
+				return false, err
+			}
+			uidToDelete = observed.GetUID()
+			resourceVersionToDelete = observed.GetResourceVersion()
 		}
 
-		current := common.NewBuildInResource(kind, namespaceName)
-		err = cli.Get(ctx, current)
+		propagationPolicy := metav1.DeletePropagationOrphan
+		opts := client.DeleteOptions{
+			Preconditions: &metav1.Preconditions{
+				UID:             &uidToDelete,
+				ResourceVersion: &resourceVersionToDelete,
+			},
+			PropagationPolicy: &propagationPolicy,
+		}
+
+		err = cli.Client.Delete(ctx, observed, &opts)
 		if err != nil && !apierrors.IsNotFound(err) {
-			return err
-		} else if len(current.GetUID()) > 0 && current.GetUID() != uidToDelete {
-			return nil
+			cli.Log.Error(err, "delete resource error", "name", observed.GetName())
+			return false, err
 		}
-		if time.Now().After(deadLine) {
-			return errors.New(fmt.Sprintf("(%s/%s) recreate failed!", reconciledMeta.GetNamespace(), reconciledMeta.GetName()))
+		state.UpdateActionStep(v1.ReCreate, observed.GetName(), v1.Terminated, string(uidToDelete))
+		cli.Log.Info("delete resource with orphan ok, waiting the recreate", "name", observed.GetName())
+		return false, nil
+	}
+
+	current := common.NewBuildInResource(kind, namespaceName)
+	err = cli.Get(ctx, current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	if err == nil && string(current.GetUID()) != step.Uid {
+		cli.Log.Info("resource recreate ok", "name", observed.GetName())
+		return true, nil
+	}
+	if err == nil {
+		if step.IsTimeout(GetRestartTimeout()) {
+			return false, errors.New(fmt.Sprintf("(%s/%s) recreate failed!", reconciledMeta.GetNamespace(), reconciledMeta.GetName()))
 		}
-		cli.Log.Info("waiting the recreate ready", "name", reconciledMeta.GetName())
-		time.Sleep(1 * time.Second)
+		cli.Log.Info("waiting the resource deleted", "name", observed.GetName())
+		return false, nil
 	}
 
-	return err
+	// resourceVersion should not be set on objects to be created.
+	observed.SetUID("")
+	observed.SetResourceVersion("")
+	observed.SetFinalizers(nil)
+	err = cli.Client.Create(ctx, observed)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+	cli.Log.Info("resource recreate ok", "name", observed.GetName())
+	return true, nil
 }
 
-func (cli *ReconcileClient) FailOver(ctx context.Context, observed client.Object, podTemplates ...corev1.Pod) error {
+// recoveryStep the step of the fail over which records the RECOVERY_MODE env is set to the StatefulSet by it.
+const recoveryStep = "recovery-mode"
+
+// FailOver restart the crash pods with their pvc, the pods will be moved to other health node.
+// The RECOVERY_MODE env is set to the live StatefulSet while the crash pods are restarted, and it is reset when they are recovered.
+// It will advance one step in every reconcile, and return true when all the crash pods are recovered.
+func (cli *ReconcileClient) FailOver(ctx context.Context, state *v1.ComponentState, observed client.Object, podTemplates ...corev1.Pod) (bool, error) {
 	if len(podTemplates) == 0 {
-		return nil
+		return true, nil
 	}
 
 	var failOvers []corev1.Pod
 
 	exists, _, err := cli.CheckIfExists(ctx, podTemplates...)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if len(podTemplates) == len(exists) {
-		for _, pod := range exists {
-			if crash := IsPodCrash(pod); crash {
-				failOvers = append(failOvers, pod)
-			}
+	for _, pod := range podTemplates {
+		step := state.GetActionStep(v1.FailOver, pod.Name)
+		// the pod in fail over progress, it may be deleted and waits to be created again.
+		if len(step.State) > 0 && step.State != v1.Success {
+			failOvers = append(failOvers, pod)
+			continue
+		}
+		if current := findPod(pod.Name, exists); current != nil && len(podTemplates) == len(exists) && IsPodCrash(*current) {
+			failOvers = append(failOvers, *current)
 		}
 	}
 
-	sts := observed.(*appsv1.StatefulSet)
-	envMode := GetEnv(sts.Spec.Template.Spec.Containers, "RECOVERY_MODE")
-	recovery := false
+	// the desired StatefulSet never carries the env, it is read from the live one.
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: observed.GetNamespace(), Name: observed.GetName()}}
+	if err = cli.Get(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
 	if len(failOvers) > 0 && len(failOvers) < len(podTemplates)/2+1 {
-		if len(envMode) == 0 || envMode == "false" {
-			recovery = true
+		if GetEnv(sts.Spec.Template.Spec.Containers, common.RecoveryMode) != "true" {
 			// update env set RECOVERY_MODE=true
 			ModifyEnv(sts.Spec.Template.Spec.Containers, []corev1.EnvVar{
 				{
-					Name:  "RECOVERY_MODE",
+					Name:  common.RecoveryMode,
 					Value: "true",
 				},
 			}...)
 			err = cli.Update(ctx, sts)
 			if err != nil {
-				return err
+				return false, err
 			}
 			cli.Log.Info("update StatefulSet env and set RECOVERY_MODE=true ok.", "name", sts.GetName())
 		}
-		// sort by start time desc.
-		err = cli.Restart(ctx, true, true, failOvers)
-		cli.Log.Info("restart crash pod.", "pods", failOvers)
+		if state.GetActionStep(v1.FailOver, recoveryStep).State != v1.InProgress {
+			state.UpdateActionStep(v1.FailOver, recoveryStep, v1.InProgress, string(sts.UID))
+		}
+		done, er := cli.Restart(ctx, state, v1.FailOver, true, true, failOvers)
+		if er != nil || !done {
+			return done, er
+		}
+		cli.Log.Info("restart crash pod ok.", "pods", len(failOvers))
 	}
 
-	// avoid restart.
-	if recovery || len(failOvers) >= len(podTemplates)/2+1 {
-		if GetEnv(sts.Spec.Template.Spec.Containers, "RECOVERY_MODE") == "true" {
-			err = cli.Get(ctx, sts)
-			// update env set RECOVERY_MODE=true
-			ModifyEnv(sts.Spec.Template.Spec.Containers, []corev1.EnvVar{
-				{
-					Name: "RECOVERY_MODE",
-				},
-			}...)
-			err = cli.Update(ctx, sts)
-			cli.Log.Info("update StatefulSet env and reset RECOVERY_MODE ok.", "name", sts.GetName())
+	// all the crash pod recovered, reset the env set by the fail over.
+	if state.GetActionStep(v1.FailOver, recoveryStep).State == v1.InProgress &&
+		GetEnv(sts.Spec.Template.Spec.Containers, common.RecoveryMode) == "true" {
+		ModifyEnv(sts.Spec.Template.Spec.Containers, []corev1.EnvVar{
+			{
+				Name: common.RecoveryMode,
+			},
+		}...)
+		err = cli.Update(ctx, sts)
+		if err != nil {
+			return false, err
 		}
+		cli.Log.Info("update StatefulSet env and reset RECOVERY_MODE ok.", "name", sts.GetName())
 	}
 
-	return err
+	return true, nil
 }
 
-func (cli *ReconcileClient) Restart(ctx context.Context, failOver, waitReady bool, pods []corev1.Pod) error {
+// Restart delete the pods one by one, the progress of each pod is recorded in the action steps.
+// It will advance one step in every reconcile, and return true when all the pods are restarted.
+func (cli *ReconcileClient) Restart(ctx context.Context, state *v1.ComponentState, act v1.Action, failOver, waitReady bool, pods []corev1.Pod) (bool, error) {
 	timeout := GetRestartTimeout()
 
 	if len(pods) == 0 {
-		return errors.New("pod template is empty")
+		return false, errors.New("pod template is empty")
 	}
 
 	// validate pods state
 	exists, notfound, er := cli.CheckIfExists(ctx, pods...)
 	if er != nil {
-		return er
+		return false, er
 	}
 
-	if len(exists) == 0 {
+	if len(exists) == 0 && !isRestarting(state, act, pods) {
 		cli.Log.Info("may all the pods is deleted in other reconcile")
-		return nil
+		return true, nil
+	}
+
+	// check the pod in progress.
+	for _, pod := range pods {
+		step := state.GetActionStep(act, pod.Name)
+		switch step.State {
+		case v1.Stopping:
+			// wait the pvc deleted, avoid pod pending because pvc not exists.
+			current := findPod(pod.Name, exists)
+			if current == nil {
+				if step.IsTimeout(timeout) {
+					state.UpdateActionStep(act, pod.Name, v1.Failed, step.Uid)
+					return false, errors.New("pod recreate failed " + pod.GetName())
+				}
+				return false, nil
+			}
+			deleted, err := cli.IsPodVolumeDeleted(ctx, *current)
+			if err != nil {
+				return false, err
+			}
+			if !deleted {
+				if step.IsTimeout(timeout) {
+					state.UpdateActionStep(act, pod.Name, v1.Failed, step.Uid)
+					return false, errors.New("pvc delete failed " + pod.GetName())
+				}
+				cli.Log.Info("waiting the pvc deleted", "name", pod.GetName())
+				return false, nil
+			}
+			if err = cli.Delete(ctx, current); err != nil {
+				return false, err
+			}
+			state.UpdateActionStep(act, pod.Name, v1.Restarting, string(current.UID))
+			cli.Log.Info("delete pod ok", "name", pod.GetName())
+			return false, nil
+		case v1.Restarting:
+			current := findPod(pod.Name, exists)
+			if current == nil || string(current.UID) == step.Uid || (waitReady && !IsPodReady(*current)) {
+				if step.IsTimeout(timeout) {
+					state.UpdateActionStep(act, pod.Name, v1.Failed, step.Uid)
+					return false, errors.New("pod restart failed " + pod.GetName())
+				}
+				cli.Log.Info("waiting the pod ready", "name", pod.GetName())
+				return false, nil
+			}
+			state.UpdateActionStep(act, pod.Name, v1.Success, string(current.UID))
+			cli.Log.Info("pod restart ok", "name", pod.GetName())
+		}
 	}
 
 	if len(notfound) > 0 {
-		time.Sleep(5 * time.Second)
-		return errors.New(fmt.Sprintf("exists not found pod"))
+		cli.Log.Info("exists not found pod, waiting it created", "pods", len(notfound))
+		return false, nil
 	}
 
 	// delete the crash or not ready pod first.
 	exists = Ordered(exists...)
 	for _, pod := range exists {
+		if state.GetActionStep(act, pod.Name).State == v1.Success {
+			continue
+		}
+
 		// in order to let the pod move to other health node, fail over need delete the bad pod with it's pvc.
 		if failOver {
 			// 1. delete pod
-			cli.Delete(ctx, &pod)
-			deadLine := time.Now().Add(timeout)
-			for e := cli.Get(ctx, &pod); e != nil && apierrors.IsNotFound(e); {
-				if time.Now().After(deadLine) {
-					return errors.New("pod delete failed " + pod.GetName())
-				}
-				cli.Log.Info("waiting the pod deleted", "name", pod.GetName())
-				time.Sleep(3 * time.Second)
+			if err := cli.Delete(ctx, &pod); err != nil {
+				return false, err
 			}
-			cli.Log.Info("delete pod ok", "name", pod.GetName())
-
 			// 2. delete pvc
 			for _, vol := range pod.Spec.Volumes {
 				if vol.PersistentVolumeClaim == nil {
@@ -267,92 +353,126 @@ func (cli *ReconcileClient) Restart(ctx context.Context, failOver, waitReady boo
 						Name:      vol.PersistentVolumeClaim.ClaimName,
 					},
 				}
-				er = cli.Delete(ctx, pvc)
-				if er != nil {
-					if apierrors.IsNotFound(er) {
-						cli.Log.Info("pvc not found, may the pod is deleted in other reconcile", "name", pvc.GetName())
-					} else {
-						return er
-					}
-				}
-
-				// 3. wait pvc deleted. avoid pod pending because pvc not exists.
-				deadLine = time.Now().Add(timeout)
-				for pe := cli.Get(ctx, pvc); pe != nil && apierrors.IsNotFound(er); {
-					if time.Now().After(deadLine) {
-						return errors.New("pvc delete failed " + vol.PersistentVolumeClaim.ClaimName)
-					}
-					cli.Log.Info("waiting the pvc deleted", "name", vol.PersistentVolumeClaim.ClaimName)
-					time.Sleep(3 * time.Second)
+				if err := cli.Delete(ctx, pvc); err != nil {
+					return false, err
 				}
-
-				cli.Log.Info("delete pvc ok", "name", pvc.GetName())
-			}
-		} else {
-			// check is exists pod is not ready, if exists, wait failOver to recovery it and continue.
-			if ready := IsPodReady(exists...); !ready {
-				time.Sleep(5 * time.Second)
-				return errors.New("not all the pods are ready")
 			}
+			// 3. wait pvc deleted in next step.
+			state.UpdateActionStep(act, pod.Name, v1.Stopping, string(pod.UID))
+			cli.Log.Info("delete pod and pvc ok", "name", pod.GetName())
+			return false, nil
 		}
 
-		// delete pod
-		err := cli.Delete(ctx, &pod)
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				cli.Log.Info("pod not found, may the pod is deleted in other reconcile", "name", pod.GetName())
-			}
-			return err
+		// check is exists pod is not ready, if exists, wait failOver to recovery it and continue.
+		if ready := IsPodReady(exists...); !ready {
+			cli.Log.Info("not all the pods are ready, waiting them ready", "name", pod.GetName())
+			return false, nil
 		}
 
+		// delete pod
+		if err := cli.Delete(ctx, &pod); err != nil {
+			return false, err
+		}
+		state.UpdateActionStep(act, pod.Name, v1.Restarting, string(pod.UID))
 		cli.Log.Info("delete pod ok", "name", pod.GetName())
+		return false, nil
+	}
 
-		deadLine := time.Now().Add(timeout)
-		for !cli.TerminalWaitPodReady(ctx, &pod, waitReady, pods...) {
-			if time.Now().After(deadLine) {
-				return errors.New("pod restart failed " + pod.Name)
-			}
-			cli.Log.Info("waiting the pod ready", "name", pod.GetName())
-			time.Sleep(3 * time.Second)
+	return true, nil
+}
+
+// RolloutRestart restart the pods of the deployment or daemonSet by the rollout, the pods have no stable name to restart one by one.
+// It will advance one step in every reconcile, and return true when the rollout is complete.
+func (cli *ReconcileClient) RolloutRestart(ctx context.Context, state *v1.ComponentState, act v1.Action, workload client.Object) (bool, error) {
+	name := workload.GetName()
+	if err := cli.Get(ctx, workload); err != nil {
+		if apierrors.IsNotFound(err) {
+			cli.Log.Info("may the workload is deleted in other reconcile", "name", name)
+			return true, nil
 		}
-		cli.Log.Info("pod restart ok", "name", pod.GetName())
+		return false, err
 	}
 
-	return nil
-}
+	step := state.GetActionStep(act, name)
+	switch step.State {
+	case v1.Success:
+		return true, nil
+	case v1.Restarting:
+		if ready, _ := IsWorkloadReady(workload); !ready {
+			if step.IsTimeout(GetRestartTimeout()) {
+				state.UpdateActionStep(act, name, v1.Failed, step.Uid)
+				return false, errors.New("workload rollout failed " + name)
+			}
+			cli.Log.Info("waiting the workload rollout", "name", name)
+			return false, nil
+		}
+		state.UpdateActionStep(act, name, v1.Success, string(workload.GetUID()))
+		cli.Log.Info("workload rollout ok", "name", name)
+		return true, nil
+	}
 
-func (cli *ReconcileClient) TerminalWaitPodReady(ctx context.Context, current *corev1.Pod, waitReady bool, templates ...corev1.Pod) bool {
-	e, n, er := cli.CheckIfExists(ctx, templates...)
-	if er != nil {
-		return false
+	var template *corev1.PodTemplateSpec
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		template = &w.Spec.Template
+	case *appsv1.DaemonSet:
+		template = &w.Spec.Template
+	default:
+		return false, fmt.Errorf("the %T not support rollout restart", workload)
+	}
+	// the same as kubectl rollout restart.
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
 	}
-	if len(e) == 0 && len(n) == len(templates) {
-		cli.Log.Info("all the pod are deleted", "key", current.GetName())
-		return true
+	template.Annotations[common.RestartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	if err := cli.Update(ctx, workload); err != nil {
+		return false, err
 	}
+	state.UpdateActionStep(act, name, v1.Restarting, string(workload.GetUID()))
+	cli.Log.Info("rollout restart workload ok", "name", name)
+	return false, nil
+}
 
-	// not exists
-	for _, pod := range n {
-		if current.Name == pod.Name {
-			return false
+// IsPodVolumeDeleted is all the pvc of the pod deleted.
+func (cli *ReconcileClient) IsPodVolumeDeleted(ctx context.Context, pod corev1.Pod) (bool, error) {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: pod.Namespace,
+				Name:      vol.PersistentVolumeClaim.ClaimName,
+			},
+		}
+		err := cli.Get(ctx, pvc)
+		if err == nil {
+			return false, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return false, err
 		}
 	}
+	return true, nil
+}
 
-	// exists
-	for _, pod := range e {
-		if current.Name == pod.Name {
-			if current.UID == pod.UID {
-				cli.Log.Info("pod not deleted", "key", pod.GetName())
-				return false
-			}
-			if waitReady {
-				return IsPodReady(pod)
-			}
+// isRestarting any of the pods is deleted by the action and waits to be created again.
+func isRestarting(state *v1.ComponentState, act v1.Action, pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if step := state.GetActionStep(act, pod.Name); step.State == v1.Stopping || step.State == v1.Restarting {
 			return true
 		}
 	}
+	return false
+}
 
-	return true
+func findPod(name string, pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		if pods[i].Name == name {
+			return &pods[i]
+		}
+	}
+	return nil
 }
 
 func (cli *ReconcileClient) CheckIfExists(ctx context.Context, templates ...corev1.Pod) ([]corev1.Pod, []corev1.Pod, error) {
@@ -380,6 +500,17 @@ func (cli *ReconcileClient) CheckIfExists(ctx context.Context, templates ...core
 	return exists, notfound, nil
 }
 
+func GetRequeueInterval() time.Duration {
+	t := os.Getenv("REQUEUE_INTERVAL")
+	if len(t) > 0 {
+		ot, err := strconv.Atoi(t)
+		if err == nil && ot > 0 {
+			return time.Duration(ot) * time.Second
+		}
+	}
+	return 5 * time.Second
+}
+
 func GetRestartTimeout() time.Duration {
 	t := os.Getenv("RESTART_TIMEOUT")
 	if len(t) > 0 {
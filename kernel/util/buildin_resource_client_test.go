@@ -0,0 +1,164 @@
+package util
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func newFakeClient(objs ...client.Object) *ReconcileClient {
+	return &ReconcileClient{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build(),
+		Log:    logr.Discard(),
+	}
+}
+
+func newPod(name, uid string, phase corev1.PodPhase) *corev1.Pod {
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, UID: types.UID(uid), CreationTimestamp: started},
+		Status:     corev1.PodStatus{Phase: phase, StartTime: &started},
+	}
+}
+
+func TestFailOver(t *testing.T) {
+	ctx := context.Background()
+	replicas := int32(3)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk", UID: "sts"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "zk"}}}},
+		},
+	}
+	cli := newFakeClient(sts,
+		newPod("demo-zk-0", "0", corev1.PodRunning),
+		newPod("demo-zk-1", "1", corev1.PodRunning),
+		newPod("demo-zk-2", "2", corev1.PodFailed))
+	state := v1.NewComponentState(v1.Success, "ok", nil)
+	pods := OrderedPod(types.NamespacedName{Namespace: "ns", Name: "demo-zk"}, replicas)
+	// the desired StatefulSet never carries the env.
+	desired := sts.DeepCopy()
+	recoveryMode := func() string {
+		live := &appsv1.StatefulSet{}
+		if err := cli.Client.Get(ctx, client.ObjectKeyFromObject(sts), live); err != nil {
+			t.Fatal(err)
+		}
+		return GetEnv(live.Spec.Template.Spec.Containers, RecoveryMode)
+	}
+	failOver := func() bool {
+		done, err := cli.FailOver(ctx, state, desired, pods...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return done
+	}
+
+	// the env is set and the crash pod is deleted in the same step.
+	if failOver() {
+		t.Fatalf("expected the fail over is in progress")
+	}
+	if recoveryMode() != "true" || state.GetActionStep(v1.FailOver, recoveryStep).State != v1.InProgress {
+		t.Fatalf("expected the RECOVERY_MODE is set and recorded, got %q %v", recoveryMode(), state.ActionState)
+	}
+	if step := state.GetActionStep(v1.FailOver, "demo-zk-2"); step.State != v1.Stopping {
+		t.Fatalf("expected the crash pod is deleted, got %v", step)
+	}
+
+	// the deleted pod waits to be created again, the env is kept.
+	if failOver() || recoveryMode() != "true" {
+		t.Fatalf("expected the fail over waits the pod created")
+	}
+	if err := cli.Client.Create(ctx, newPod("demo-zk-2", "3", corev1.PodPending)); err != nil {
+		t.Fatal(err)
+	}
+	// the pod created before its pvc is deleted is deleted again.
+	if failOver() {
+		t.Fatalf("expected the fail over is in progress")
+	}
+	if err := cli.Client.Create(ctx, newPod("demo-zk-2", "4", corev1.PodRunning)); err != nil {
+		t.Fatal(err)
+	}
+	if !failOver() {
+		t.Fatalf("expected the fail over is done when the pod is running, got %v", state.ActionState)
+	}
+	if recoveryMode() != "" {
+		t.Errorf("expected the RECOVERY_MODE is reset, got %q", recoveryMode())
+	}
+}
+
+func TestEnvFinger(t *testing.T) {
+	finger := EnvFinger([]corev1.EnvVar{{Name: RecoveryMode, Value: "true"}, {Name: "ZK_PORT", Value: "2181"}})
+	if len(finger) != 1 || finger["ZK_PORT"] != "2181" {
+		t.Errorf("expected the RECOVERY_MODE is not fingered, got %v", finger)
+	}
+}
+
+func TestRolloutRestart(t *testing.T) {
+	ctx := context.Background()
+	replicas := int32(2)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-proxy", UID: "proxy", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2, ReadyReplicas: 2},
+	}
+	cli := newFakeClient(deploy, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk"}})
+	state := v1.NewComponentState(v1.Success, "ok", nil)
+	key := types.NamespacedName{Namespace: "ns", Name: "demo-proxy"}
+
+	// 1. the template is annotated as kubectl rollout restart does.
+	done, err := cli.RolloutRestart(ctx, state, v1.Restart, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-proxy"}})
+	if err != nil || done {
+		t.Fatalf("expected the rollout begins, got %v %v", done, err)
+	}
+	current := &appsv1.Deployment{}
+	if err = cli.Client.Get(ctx, key, current); err != nil {
+		t.Fatal(err)
+	}
+	if len(current.Spec.Template.Annotations[RestartedAtAnnotation]) == 0 || state.GetActionStep(v1.Restart, "demo-proxy").State != v1.Restarting {
+		t.Fatalf("expected the restarted annotation and the restarting step, got %v", current.Spec.Template.Annotations)
+	}
+
+	// 2. wait the controller rolls out the new template.
+	current.Generation = 2
+	if err = cli.Update(ctx, current); err != nil {
+		t.Fatal(err)
+	}
+	done, err = cli.RolloutRestart(ctx, state, v1.Restart, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-proxy"}})
+	if err != nil || done {
+		t.Fatalf("expected the rollout waits the new generation observed, got %v %v", done, err)
+	}
+	annotation := current.Spec.Template.Annotations[RestartedAtAnnotation]
+	if err = cli.Client.Get(ctx, key, current); err != nil || current.Spec.Template.Annotations[RestartedAtAnnotation] != annotation {
+		t.Fatalf("expected the waiting rollout is not restarted again")
+	}
+
+	// 3. the rollout is complete.
+	current.Status.ObservedGeneration = 2
+	if err = cli.Update(ctx, current); err != nil {
+		t.Fatal(err)
+	}
+	done, err = cli.RolloutRestart(ctx, state, v1.Restart, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-proxy"}})
+	if err != nil || !done || state.GetActionStep(v1.Restart, "demo-proxy").State != v1.Success {
+		t.Fatalf("expected the rollout is complete, got %v %v", done, err)
+	}
+
+	if _, err = cli.RolloutRestart(ctx, v1.NewComponentState(v1.Success, "ok", nil), v1.Restart,
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-zk"}}); err == nil {
+		t.Errorf("expected the StatefulSet is not supported")
+	}
+	if done, err = cli.RolloutRestart(ctx, v1.NewComponentState(v1.Success, "ok", nil), v1.Restart,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "missing"}}); err != nil || !done {
+		t.Errorf("expected the deleted workload is done, got %v %v", done, err)
+	}
+}
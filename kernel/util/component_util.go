@@ -1,12 +1,17 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strings"
 )
 
@@ -25,6 +30,43 @@ func Merge(src1, src2 map[string]string) map[string]string {
 	return target
 }
 
+// InheritedAnnotations the annotations of the cluster which its resources inherit,
+// the operator annotations of the cluster are kept by the cluster itself.
+func InheritedAnnotations(annotations map[string]string) map[string]string {
+	target := make(map[string]string)
+	for k, v := range annotations {
+		if !strings.HasPrefix(k, v1.GroupVersion.Group+"/") {
+			target[k] = v
+		}
+	}
+	return target
+}
+
+// HasClusterAnnotations the resource carries the operator annotations of the cluster, they are inherited by the previous version.
+func HasClusterAnnotations(obj client.Object) bool {
+	if obj == nil {
+		return false
+	}
+	for _, key := range v1.ClusterAnnotations {
+		if _, ok := obj.GetAnnotations()[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveClusterAnnotations remove the operator annotations of the cluster from the resource.
+func RemoveClusterAnnotations(obj client.Object) {
+	if !HasClusterAnnotations(obj) {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	for _, key := range v1.ClusterAnnotations {
+		delete(annotations, key)
+	}
+	obj.SetAnnotations(annotations)
+}
+
 func GetComponentName(cluster string, category v1.Category, kind v1.ComponentKind) string {
 	return strings.ToLower(fmt.Sprintf("%s-%s-%s", cluster, category, kind))
 }
@@ -33,6 +75,20 @@ func GetComponentShotName(cluster string, category v1.Category) string {
 	return strings.ToLower(fmt.Sprintf("%s-%s", cluster, category))
 }
 
+// GetUserCategory the category of the credentials secret of the named user.
+func GetUserCategory(component *v1.CategoryClusterComponent, user string) v1.Category {
+	return v1.Category(strings.ToLower(fmt.Sprintf("%s-%s-%s", component.GetCategory(), Secret, user)))
+}
+
+// GetUserSecretName the secret name of the named user.
+// The password is read from the referenced secret, or the existing secret provides the credentials.
+func GetUserSecretName(cluster string, component *v1.CategoryClusterComponent, user v1.ComponentUser) string {
+	if len(user.ExistingSecret) > 0 {
+		return user.ExistingSecret
+	}
+	return GetComponentShotName(cluster, GetUserCategory(component, user.Name))
+}
+
 // ToOwnerReference generator the owner reference
 func ToOwnerReference(component core.CustomResource) metav1.OwnerReference {
 	return metav1.OwnerReference{
@@ -55,9 +111,13 @@ func GetReferenceLabels(ref core.TypedCategoryComponent, kind v1.ComponentKind)
 	}
 }
 
+// EnvFinger the finger of the envs, the RECOVERY_MODE env is set by the fail over, it is not fingered.
 func EnvFinger(envs []corev1.EnvVar) map[string]string {
 	target := make(map[string]string)
 	for _, e := range envs {
+		if e.Name == RecoveryMode {
+			continue
+		}
 		if len(e.Value) > 0 {
 			target[e.Name] = e.Value
 		} else if e.ValueFrom != nil {
@@ -158,3 +218,114 @@ func PvcSpecFinger(spec corev1.PersistentVolumeClaimSpec) map[string]string {
 	}
 	return target
 }
+
+// IsWorkloadReady is the workload resource ready.
+// return[0]: all the replicas are ready.
+// return[1]: the object is a workload resource.
+func IsWorkloadReady(obj client.Object) (bool, bool) {
+	switch workload := obj.(type) {
+	case *appsv1.StatefulSet:
+		if workload == nil {
+			return false, false
+		}
+		replicas := int32(1)
+		if workload.Spec.Replicas != nil {
+			replicas = *workload.Spec.Replicas
+		}
+		return workload.Status.ObservedGeneration >= workload.Generation && workload.Status.ReadyReplicas >= replicas, true
+	case *appsv1.Deployment:
+		if workload == nil {
+			return false, false
+		}
+		replicas := int32(1)
+		if workload.Spec.Replicas != nil {
+			replicas = *workload.Spec.Replicas
+		}
+		// the rollout is complete when all the replicas are updated and available, and the old ones are gone.
+		status := workload.Status
+		return status.ObservedGeneration >= workload.Generation && status.UpdatedReplicas >= replicas &&
+			status.AvailableReplicas >= replicas && status.Replicas <= status.UpdatedReplicas, true
+	case *appsv1.DaemonSet:
+		if workload == nil {
+			return false, false
+		}
+		// the rollout is complete when all the scheduled nodes run the updated and available pod.
+		status := workload.Status
+		return status.ObservedGeneration >= workload.Generation && status.UpdatedNumberScheduled >= status.DesiredNumberScheduled &&
+			status.NumberAvailable >= status.DesiredNumberScheduled && status.NumberReady >= status.DesiredNumberScheduled, true
+	}
+	return false, false
+}
+
+// IsWorkloadUpgraded is the workload ready and all its pods are running the latest template.
+func IsWorkloadUpgraded(obj client.Object) bool {
+	ready, ok := IsWorkloadReady(obj)
+	if !ok || !ready {
+		return false
+	}
+	status := GetWorkloadStatus(obj)
+	return status.Updated >= status.Desired
+}
+
+// GetWorkloadStatus the pod counts of the workload resource, it is nil when the object is not a workload.
+func GetWorkloadStatus(obj client.Object) *v1.WorkloadStatus {
+	switch workload := obj.(type) {
+	case *appsv1.StatefulSet:
+		if workload == nil {
+			return nil
+		}
+		return &v1.WorkloadStatus{
+			Desired:   replicasOrDefault(workload.Spec.Replicas),
+			Scheduled: workload.Status.Replicas,
+			Ready:     workload.Status.ReadyReplicas,
+			Updated:   workload.Status.UpdatedReplicas,
+			Available: workload.Status.AvailableReplicas,
+		}
+	case *appsv1.Deployment:
+		if workload == nil {
+			return nil
+		}
+		return &v1.WorkloadStatus{
+			Desired:   replicasOrDefault(workload.Spec.Replicas),
+			Scheduled: workload.Status.Replicas,
+			Ready:     workload.Status.ReadyReplicas,
+			Updated:   workload.Status.UpdatedReplicas,
+			Available: workload.Status.AvailableReplicas,
+		}
+	case *appsv1.DaemonSet:
+		if workload == nil {
+			return nil
+		}
+		// one pod per node.
+		return &v1.WorkloadStatus{
+			Desired:   workload.Status.DesiredNumberScheduled,
+			Scheduled: workload.Status.CurrentNumberScheduled,
+			Ready:     workload.Status.NumberReady,
+			Updated:   workload.Status.UpdatedNumberScheduled,
+			Available: workload.Status.NumberAvailable,
+		}
+	}
+	return nil
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// PasswordDigest the sha256 digest of the password, so that the password is never printed.
+func PasswordDigest(password string) string {
+	digest := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(digest[:])
+}
+
+// GeneratePassword the random password of the crypto/rand.
+func GeneratePassword() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestInheritedAnnotations(t *testing.T) {
+	annotations := InheritedAnnotations(map[string]string{
+		v1.LegacyAuthAnnotation:        `{"demo-redis":{"password":"secret"}}`,
+		v1.RotateCredentialsAnnotation: "1",
+		"team":                         "storage",
+	})
+	if len(annotations) != 1 || annotations["team"] != "storage" {
+		t.Errorf("expected only the user annotations are inherited, got %v", annotations)
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		v1.LegacyAuthAnnotation:                    `{"demo-redis":{"password":"secret"}}`,
+		"apps.devless.toplogy.com/password-source": "legacy",
+	}}}
+	if !HasClusterAnnotations(secret) {
+		t.Fatalf("expected the inherited legacy auth is found")
+	}
+	RemoveClusterAnnotations(secret)
+	if HasClusterAnnotations(secret) || secret.Annotations["apps.devless.toplogy.com/password-source"] != "legacy" {
+		t.Errorf("expected only the cluster annotations are removed, got %v", secret.Annotations)
+	}
+}
+
+func TestIsWorkloadReadyDeployment(t *testing.T) {
+	replicas := int32(2)
+	ready := appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2, ReadyReplicas: 2}
+	cases := []struct {
+		name     string
+		status   func(status *appsv1.DeploymentStatus)
+		expected bool
+	}{
+		{"complete", func(status *appsv1.DeploymentStatus) {}, true},
+		{"generation not observed", func(status *appsv1.DeploymentStatus) { status.ObservedGeneration = 1 }, false},
+		{"not updated", func(status *appsv1.DeploymentStatus) { status.UpdatedReplicas = 1 }, false},
+		{"not available", func(status *appsv1.DeploymentStatus) { status.AvailableReplicas = 1 }, false},
+		{"old pods left", func(status *appsv1.DeploymentStatus) { status.Replicas = 3 }, false},
+	}
+	for _, c := range cases {
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     ready,
+		}
+		c.status(&deploy.Status)
+		got, ok := IsWorkloadReady(deploy)
+		if !ok || got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+		if c.expected && !IsWorkloadUpgraded(deploy) {
+			t.Errorf("%s: expected the deployment is upgraded", c.name)
+		}
+	}
+	if _, ok := IsWorkloadReady((*appsv1.Deployment)(nil)); ok {
+		t.Errorf("expected the nil deployment is not a workload")
+	}
+}
@@ -4,19 +4,25 @@ import (
 	"crypto/md5"
 	"fmt"
 	"github.com/kuberator/api/core"
-	v1 "github.com/kuberator/api/v1beta1"
+	v1 "github.com/kuberator/api/v1"
 	. "github.com/kuberator/kernel/common"
 	corev1 "k8s.io/api/core/v1"
+	"os"
 	"sort"
 	"strings"
 )
 
+// ConfSource the global conf and the properties of the component, they are rendered and merged when the configMap is made.
+type ConfSource struct {
+	Conf       []*v1.NamedProperties
+	Properties []*v1.NamedProperties
+}
+
 func BuildConfResource(cfs []*v1.NamedProperties, components []*v1.CategoryClusterComponent) []*core.CategoryComponentObject {
 	var cms []*core.CategoryComponentObject
 	if components != nil && len(components) > 0 {
 		for _, com := range components {
-			var conf = MergeConf(cfs, com.Properties)
-			if conf == nil || len(conf) == 0 {
+			if len(cfs) == 0 && len(com.Properties) == 0 {
 				continue
 			}
 			cm := &core.CategoryComponentObject{
@@ -27,7 +33,7 @@ func BuildConfResource(cfs []*v1.NamedProperties, components []*v1.CategoryClust
 						Kind: ConfigMap,
 					},
 				},
-				Object:    conf,
+				Object:    &ConfSource{Conf: cfs, Properties: com.Properties},
 				Reference: com,
 			}
 			cms = append(cms, cm)
@@ -36,86 +42,199 @@ func BuildConfResource(cfs []*v1.NamedProperties, components []*v1.CategoryClust
 	return cms
 }
 
-// TODO: 根据conf的path拆分成多个configmap，每个configmap mount到conf的path上.
-func BuildConfigMapMount(category v1.Category, clusterName string, properties []v1.NamedProperties) ([]corev1.Volume, []corev1.VolumeMount) {
+// ConfMount the mount of the configMap volume, it is mounted into the containers by the names, or all the containers when no name.
+type ConfMount struct {
+	corev1.VolumeMount
+	Containers []string
+}
+
+// BuildConfigMapMount one volume of the configMap per path, the volume only projects the files of the path.
+// The path is mounted as a directory, unless all of its files are mounted by the sub path.
+func BuildConfigMapMount(category v1.Category, configMapName string, properties []v1.NamedProperties) ([]corev1.Volume, []ConfMount) {
 	if properties == nil || len(properties) == 0 {
 		return nil, nil
 	}
+	files := map[string][]v1.NamedProperties{}
+	var paths []string
+	for _, p := range properties {
+		if _, ok := files[p.Path]; !ok {
+			paths = append(paths, p.Path)
+		}
+		files[p.Path] = append(files[p.Path], p)
+	}
+	sort.Strings(paths)
+
 	var volumes []corev1.Volume
-	var volumeMounts []corev1.VolumeMount
-	var confVol = corev1.Volume{
-		Name: fmt.Sprintf("%s-%s", AppConfigMapVolume, category),
-		VolumeSource: corev1.VolumeSource{
-			ConfigMap: &corev1.ConfigMapVolumeSource{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: GetComponentName(clusterName, category, ConfigMap),
+	var mounts []ConfMount
+	for i, path := range paths {
+		// the first volume keeps the legacy name.
+		name := fmt.Sprintf("%s-%s", AppConfigMapVolume, category)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		volume := corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMapName,
+					},
 				},
 			},
-		},
-	}
+		}
 
-	var confMount = corev1.VolumeMount{
-		Name:      fmt.Sprintf("%s-%s", AppConfigMapVolume, category),
-		MountPath: properties[0].Path,
-	}
+		subPath := true
+		var containers []string
+		allContainers := false
+		for _, p := range files[path] {
+			volume.ConfigMap.Items = append(volume.ConfigMap.Items, corev1.KeyToPath{
+				Key:  p.Name,
+				Path: p.Name,
+			})
+			subPath = subPath && p.SubPath
+			allContainers = allContainers || len(p.Containers) == 0
+			containers = append(containers, p.Containers...)
+		}
+		volumes = append(volumes, volume)
 
-	for i := range properties {
-		confVol.ConfigMap.Items = append(confVol.ConfigMap.Items, corev1.KeyToPath{
-			Key:  properties[i].Name,
-			Path: properties[i].Name,
+		if subPath {
+			for _, p := range files[path] {
+				mounts = append(mounts, ConfMount{
+					VolumeMount: corev1.VolumeMount{Name: name, MountPath: strings.TrimSuffix(path, "/") + "/" + p.Name, SubPath: p.Name},
+					Containers:  p.Containers,
+				})
+			}
+			continue
+		}
+		// the directory is mounted into the containers of all its files.
+		if allContainers {
+			containers = nil
+		}
+		mounts = append(mounts, ConfMount{
+			VolumeMount: corev1.VolumeMount{Name: name, MountPath: path},
+			Containers:  containers,
 		})
 	}
-
-	volumes = append(volumes, confVol)
-	volumeMounts = append(volumeMounts, confMount)
-	return volumes, volumeMounts
+	return volumes, mounts
 }
 
-// MergeConf merge from base.
-func MergeConf(base []*v1.NamedProperties, component []*v1.NamedProperties) []*v1.NamedProperties {
-	// Properties which should be provided from real deployed environment.
+// MergeConf merge the component properties on the global conf with the same properties name by the conf type, see MergeData.
+// The conf type of the component properties takes precedence.
+func MergeConf(base []*v1.NamedProperties, component []*v1.NamedProperties) ([]*v1.NamedProperties, error) {
 	conf := map[string]*v1.NamedProperties{}
-	if base != nil {
-		for _, c := range base {
+	for _, c := range base {
+		if c != nil {
 			conf[c.PropertiesName()] = c
 		}
 	}
 
 	// component conf
-	if component != nil {
-		if conf == nil {
-			conf = map[string]*v1.NamedProperties{}
+	for _, v := range component {
+		if v == nil {
+			continue
 		}
-		for _, v := range component {
+		b, ok := conf[v.PropertiesName()]
+		if !ok {
 			conf[v.PropertiesName()] = v
-			//if conf[v.PropertiesName()] == nil {
-			//	conf[v.PropertiesName()] = v
-			//} else {
-			//	p := map[string]string{}
-			//	b := conf[v.PropertiesName()]
-			//	for bk, bv := range b.Data {
-			//		p[bk] = bv
-			//	}
-			//	for ck, cv := range v.Data {
-			//		p[ck] = cv
-			//	}
-			//	conf[v.PropertiesName()].Data = p
-			//}
+			continue
+		}
+		merged := v.DeepCopy()
+		if len(merged.Type) == 0 {
+			merged.Type = b.Type
 		}
+		if len(merged.ReloadPolicy) == 0 {
+			merged.ReloadPolicy = b.ReloadPolicy
+		}
+		data, err := MergeData(merged.Type, b.Data, v.Data)
+		if err != nil {
+			return nil, fmt.Errorf("merge the properties %s failed, %v", v.PropertiesName(), err)
+		}
+		merged.Data = data
+		conf[v.PropertiesName()] = merged
 	}
 
+	keys := make([]string, 0, len(conf))
+	for k := range conf {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 	var confList []*v1.NamedProperties
-	for _, v := range conf {
-		confList = append(confList, v)
+	for _, k := range keys {
+		confList = append(confList, conf[k])
 	}
-
-	return confList
+	return confList, nil
 }
 
 func GetConfigMapName(category v1.Category) string {
 	return strings.ToLower(fmt.Sprintf("%s-%s", category, ConfigMap))
 }
 
+// BuildConfData the properties of the configMap, the templated properties are rendered with the topology of the cluster,
+// then the component properties are merged on the global conf.
+func BuildConfData(crd core.BasicCrd, source *ConfSource) ([]*v1.NamedProperties, error) {
+	topology := BuildConfTopology(crd)
+	render := func(properties []*v1.NamedProperties) ([]*v1.NamedProperties, error) {
+		var rendered []*v1.NamedProperties
+		for _, c := range properties {
+			if c == nil {
+				continue
+			}
+			data, err := RenderConf(c, topology)
+			if err != nil {
+				return nil, fmt.Errorf("render the properties %s failed, %v", c.PropertiesName(), err)
+			}
+			r := c.DeepCopy()
+			r.Data = data
+			rendered = append(rendered, r)
+		}
+		return rendered, nil
+	}
+
+	conf, err := render(source.Conf)
+	if err != nil {
+		return nil, err
+	}
+	properties, err := render(source.Properties)
+	if err != nil {
+		return nil, err
+	}
+	return MergeConf(conf, properties)
+}
+
+// GetComponentConfigMapName the name of the configMap of the component, the immutable configMap is named by the revision of its content.
+// The fixed name is used when the conf can not be built, the error is reported by the make of the configMap.
+func GetComponentConfigMapName(crd core.BasicCrd, component *v1.CategoryClusterComponent) string {
+	name := GetComponentName(crd.GetName(), component.GetCategory(), ConfigMap)
+	if component.ImmutableConf == nil {
+		return name
+	}
+	conf, err := BuildConfData(crd, &ConfSource{Conf: crd.GetSpec().Conf, Properties: component.Properties})
+	if err != nil {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", name, GetConfRevision(conf))
+}
+
+// GetConfRevision the revision of the files, it is the short finger of their names and data, so that the renamed file changes it too.
+func GetConfRevision(conf []*v1.NamedProperties) string {
+	var keys []string
+	fingerMap := map[string]string{}
+	for _, c := range conf {
+		if c != nil {
+			keys = append(keys, c.PropertiesName())
+			fingerMap[c.PropertiesName()] = fmt.Sprintf("%x", md5.Sum([]byte(c.Data)))
+		}
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(key)
+		builder.WriteString(fingerMap[key])
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(builder.String())))[:10]
+}
+
 func ConfFinger(confList []v1.NamedProperties) string {
 	keys := make([]string, len(confList))
 	i := 0
@@ -133,3 +252,85 @@ func ConfFinger(confList []v1.NamedProperties) string {
 	}
 	return fmt.Sprintf("%x", md5.Sum([]byte(builder.String())))
 }
+
+// GetServiceHost the dns name of the service, the cluster domain is omitted when it is not set.
+func GetServiceHost(name, namespace string) string {
+	host := fmt.Sprintf("%s.%s.svc", name, namespace)
+	if domain := os.Getenv(ClusterDomain); len(domain) > 0 {
+		host = fmt.Sprintf("%s.%s", host, domain)
+	}
+	return host
+}
+
+// BuildConfTopology the variables of the templated properties from the spec of the cluster.
+func BuildConfTopology(crd core.BasicCrd) v1.ConfTopology {
+	spec := crd.GetSpec()
+	topology := v1.ConfTopology{
+		Name:          crd.GetName(),
+		Namespace:     crd.GetNamespace(),
+		ClusterDomain: os.Getenv(ClusterDomain),
+		Version:       spec.GetVersion(),
+		Components:    map[string]v1.ComponentTopology{},
+		Services:      map[string]v1.ServiceTopology{},
+	}
+	for _, svc := range spec.Service {
+		if svc == nil {
+			continue
+		}
+		name := GetComponentShotName(crd.GetName(), svc.GetCategory())
+		ports := map[string]int32{}
+		for _, port := range svc.Ports {
+			ports[port.Name] = port.Port
+		}
+		topology.Services[string(svc.GetCategory())] = v1.ServiceTopology{
+			Name:  name,
+			Host:  GetServiceHost(name, crd.GetNamespace()),
+			Ports: ports,
+		}
+	}
+	for _, com := range spec.Components {
+		if com == nil {
+			continue
+		}
+		name := string(com.GetName())
+		if len(name) == 0 {
+			name = string(com.GetCategory())
+		}
+		if !strings.HasPrefix(name, crd.GetName()) {
+			name = GetComponentShotName(crd.GetName(), v1.Category(name))
+		}
+		component := v1.ComponentTopology{Name: name}
+		if com.Replicas != nil {
+			component.Replicas = *com.Replicas
+		}
+		if len(com.ServiceName) > 0 {
+			component.ServiceName = GetComponentShotName(crd.GetName(), v1.Category(com.ServiceName))
+			// only the pods of the StatefulSet have the stable dns names.
+			if com.GetKind() == StatefulSet {
+				for i := int32(0); i < component.Replicas; i++ {
+					pod := fmt.Sprintf("%s-%d", name, i)
+					component.Pods = append(component.Pods, pod)
+					component.Hosts = append(component.Hosts, GetServiceHost(fmt.Sprintf("%s.%s", pod, component.ServiceName), crd.GetNamespace()))
+				}
+			}
+		}
+		topology.Components[string(com.GetCategory())] = component
+	}
+	return topology
+}
+
+// RenderConf the data of the properties, the templated data is rendered with the topology.
+func RenderConf(properties *v1.NamedProperties, topology v1.ConfTopology) (string, error) {
+	if !properties.Template {
+		return properties.Data, nil
+	}
+	tpl, err := v1.ParseConfTemplate(properties)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err = tpl.Execute(&builder, topology); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,248 @@
+package util
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestRenderConf(t *testing.T) {
+	replicas := int32(3)
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "zk", Namespace: "ns"},
+		Spec: v1.MiddlewareClusterSpec{
+			Version: "3.8",
+			Service: []*v1.CategoryClusterService{{
+				CommonCategoryComponent: v1.CommonCategoryComponent{Category: "svc"},
+				ServiceSpec:             corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "client", Port: 2181}}},
+			}},
+			Components: []*v1.CategoryClusterComponent{{
+				CommonCategoryComponent: v1.CommonCategoryComponent{Category: "zookeeper", Component: v1.Component{Kind: StatefulSet}},
+				Replicas:                &replicas,
+				ServiceName:             "svc",
+			}},
+		},
+	}
+	properties := &v1.NamedProperties{
+		Name: "zoo.cfg",
+		Data: `{{- range $i, $host := (index .Components "zookeeper").Hosts }}
+server.{{ add $i 1 }}={{ $host }}:2888:3888
+{{- end }}
+clientPort={{ (index .Services "svc").Ports.client }}
+version={{ .Version }}`,
+		PropertiesOptions: v1.PropertiesOptions{Template: true},
+	}
+	data, err := RenderConf(properties, BuildConfTopology(crd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `
+server.1=zk-zookeeper-0.zk-svc.ns.svc:2888:3888
+server.2=zk-zookeeper-1.zk-svc.ns.svc:2888:3888
+server.3=zk-zookeeper-2.zk-svc.ns.svc:2888:3888
+clientPort=2181
+version=3.8`
+	if data != expected {
+		t.Errorf("unexpected rendered data %q", data)
+	}
+
+	properties.Data = "{{ .Missing }}"
+	if _, err = RenderConf(properties, BuildConfTopology(crd)); err == nil {
+		t.Errorf("expected the missing variable is an error")
+	}
+	properties.Template = false
+	if data, err = RenderConf(properties, BuildConfTopology(crd)); err != nil || data != "{{ .Missing }}" {
+		t.Errorf("expected the data is not rendered, got %q %v", data, err)
+	}
+}
+
+func TestMergeConf(t *testing.T) {
+	base := []*v1.NamedProperties{
+		{Path: "/etc", Name: "app.yaml", Type: v1.Yaml, Data: "server:\n  port: 8080\n  threads: 4\nid: 12345678901234567890\n"},
+		{Path: "/etc", Name: "app.json", Type: v1.Json, Data: `{"log": {"level": "info", "file": "app.log"}}`},
+		{Path: "/etc", Name: "app.ini", Type: v1.Ini, Data: "; global\nname = app\n[server]\nport = 8080\nthreads = 4"},
+		{Path: "/etc", Name: "app.txt", Data: "base"},
+	}
+	component := []*v1.NamedProperties{
+		{Path: "/etc", Name: "app.yaml", Data: "server:\n  port: 9090\n"},
+		{Path: "/etc", Name: "app.json", Type: v1.Json, Data: `{"log": {"level": "debug"}}`},
+		{Path: "/etc", Name: "app.ini", Type: v1.Ini, Data: "[server]\nthreads=8\n[client]\ntimeout = 3s"},
+		{Path: "/etc", Name: "app.txt", Data: "component"},
+	}
+	merged, err := MergeConf(base, component)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"app.yaml": "id: 12345678901234567890\nserver:\n  port: 9090\n  threads: 4\n",
+		"app.json": "{\n  \"log\": {\n    \"file\": \"app.log\",\n    \"level\": \"debug\"\n  }\n}",
+		"app.ini":  "; global\nname = app\n[server]\nport = 8080\nthreads=8\n[client]\ntimeout = 3s",
+		"app.txt":  "component",
+	}
+	for _, c := range merged {
+		if c.Data != expected[c.Name] {
+			t.Errorf("unexpected merged %s %q", c.Name, c.Data)
+		}
+	}
+	if base[0].Data != "server:\n  port: 8080\n  threads: 4\nid: 12345678901234567890\n" {
+		t.Errorf("the global conf should not be changed")
+	}
+
+	component[2].Data = "[server\nthreads=8"
+	if _, err = MergeConf(base, component); err == nil {
+		t.Errorf("expected the invalid ini is an error")
+	}
+}
+
+func TestBuildConfigMapMount(t *testing.T) {
+	volumes, mounts := BuildConfigMapMount("server", "demo-server-configmap", []v1.NamedProperties{
+		{Path: "/etc/app/conf", Name: "app.conf"},
+		{Path: "/opt/app/bin", Name: "start.sh", PropertiesOptions: v1.PropertiesOptions{SubPath: true, Containers: []string{"init"}}},
+		{Path: "/etc/app/conf", Name: "log.conf", PropertiesOptions: v1.PropertiesOptions{Containers: []string{"server"}}},
+	})
+	if len(volumes) != 2 || volumes[0].Name != "app-config-volume-server" || volumes[1].Name != "app-config-volume-server-1" {
+		t.Fatalf("expected one volume per path, got %v", volumes)
+	}
+	if volumes[1].ConfigMap.Name != "demo-server-configmap" {
+		t.Errorf("unexpected configMap %s", volumes[1].ConfigMap.Name)
+	}
+	if items := volumes[0].ConfigMap.Items; len(items) != 2 || items[0].Key != "app.conf" || items[1].Key != "log.conf" {
+		t.Errorf("unexpected items of the conf path %v", items)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("unexpected mounts %v", mounts)
+	}
+	if m := mounts[0]; m.MountPath != "/etc/app/conf" || len(m.SubPath) > 0 || m.Containers != nil {
+		t.Errorf("expected the directory is mounted into all the containers, got %v", m)
+	}
+	if m := mounts[1]; m.MountPath != "/opt/app/bin/start.sh" || m.SubPath != "start.sh" || len(m.Containers) != 1 || m.Containers[0] != "init" {
+		t.Errorf("expected the file is mounted by the sub path into the init container, got %v", m)
+	}
+}
+
+func TestComponentConfigMapName(t *testing.T) {
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Category: "server"},
+		Properties:              []*v1.NamedProperties{{Path: "/etc", Name: "app.conf", Data: "a"}},
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec:       v1.MiddlewareClusterSpec{Components: []*v1.CategoryClusterComponent{component}},
+	}
+	name := GetComponentConfigMapName(crd, component)
+	if name != GetComponentName("demo", "server", ConfigMap) {
+		t.Errorf("expected the fixed name without the immutable conf, got %s", name)
+	}
+
+	component.ImmutableConf = &v1.ImmutableConf{}
+	revision := GetComponentConfigMapName(crd, component)
+	if revision == name || len(revision) != len(name)+11 {
+		t.Errorf("expected the name with the revision, got %s", revision)
+	}
+	if GetComponentConfigMapName(crd, component) != revision {
+		t.Errorf("expected the revision is stable")
+	}
+	component.Properties[0].Data = "b"
+	if GetComponentConfigMapName(crd, component) == revision {
+		t.Errorf("expected a new revision when the data is changed")
+	}
+	component.Properties[0].Data = "a"
+	component.Properties[0].Name = "other.conf"
+	if GetComponentConfigMapName(crd, component) == revision {
+		t.Errorf("expected a new revision when the file is renamed")
+	}
+	// the conf checksum of the running workloads is kept, so that they are not rolled by the upgrade of the operator.
+	if ConfFinger([]v1.NamedProperties{{Path: "/etc", Name: "app.conf", Data: "a"}}) !=
+		ConfFinger([]v1.NamedProperties{{Path: "/etc", Name: "other.conf", Data: "a"}}) {
+		t.Errorf("expected the conf finger only covers the data")
+	}
+}
+
+func TestReloadPolicy(t *testing.T) {
+	component := &v1.CategoryClusterComponent{Reload: &v1.ComponentReload{Policy: v1.ReloadSignal}}
+	base := []*v1.NamedProperties{
+		{Path: "/etc", Name: "app.conf", Data: "a", PropertiesOptions: v1.PropertiesOptions{ReloadPolicy: v1.ReloadRollingRestart}},
+		{Path: "/etc", Name: "log.conf", Data: "b"},
+	}
+	merged, err := MergeConf(base, []*v1.NamedProperties{{Path: "/etc", Name: "app.conf", Data: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := GetReloadPolicies(component, merged)
+	if policies["app.conf"] != v1.ReloadRollingRestart || policies["log.conf"] != v1.ReloadSignal {
+		t.Errorf("unexpected policies %v", policies)
+	}
+
+	checksum := GetConfChecksum(component, merged)
+	merged[1].Data = "d"
+	if len(checksum) == 0 || GetConfChecksum(component, merged) != checksum {
+		t.Errorf("expected the checksum only covers the files of the RollingRestart policy")
+	}
+	merged[0].Data = "e"
+	if GetConfChecksum(component, merged) == checksum {
+		t.Errorf("expected the checksum is changed with the file of the RollingRestart policy")
+	}
+	merged[1].SubPath = true
+	if policies = GetReloadPolicies(component, merged); policies["log.conf"] != v1.ReloadRollingRestart {
+		t.Errorf("expected the file mounted by the sub path is rolled, got %s", policies["log.conf"])
+	}
+	merged[1].SubPath = false
+	component.Reload = nil
+	if GetConfChecksum(component, base[1:]) != "" {
+		t.Errorf("expected no checksum without the RollingRestart policy")
+	}
+
+	cases := []struct {
+		policies []v1.ReloadPolicy
+		expected v1.ReloadPolicy
+	}{
+		{nil, v1.ReloadNone},
+		{[]v1.ReloadPolicy{v1.ReloadHTTP, v1.ReloadNone}, v1.ReloadHTTP},
+		{[]v1.ReloadPolicy{v1.ReloadHTTP, v1.ReloadSignal}, v1.ReloadSignal},
+		{[]v1.ReloadPolicy{v1.ReloadSignal, v1.ReloadRollingRestart}, v1.ReloadRollingRestart},
+		{[]v1.ReloadPolicy{v1.ReloadRestart, v1.ReloadRollingRestart}, v1.ReloadRestart},
+	}
+	for _, c := range cases {
+		if policy := StrongestReloadPolicy(c.policies...); policy != c.expected {
+			t.Errorf("expected %s of %v, got %s", c.expected, c.policies, policy)
+		}
+	}
+}
+
+func TestValidateConf(t *testing.T) {
+	schema := `{"type": "object", "properties": {"server": {"type": "object", "properties": {"port": {"type": "integer"}}}}}`
+	cases := []struct {
+		properties v1.NamedProperties
+		line       int
+		invalid    bool
+	}{
+		{properties: v1.NamedProperties{Name: "app.yaml", Type: v1.Yaml, Data: "server:\n  port: 8080\n"}},
+		{properties: v1.NamedProperties{Name: "app.yaml", Type: v1.Yaml, Data: "server:\n  port: 8080\n\tbad: 1\n"}, line: 3, invalid: true},
+		{properties: v1.NamedProperties{Name: "app.json", Type: v1.Json, Data: "{\n  \"server\": {\n    \"port\": 8080,\n  }\n}"}, line: 4, invalid: true},
+		{properties: v1.NamedProperties{Name: "app.ini", Type: v1.Ini, Data: "[server]\nport = 8080\n[client\n"}, line: 3, invalid: true},
+		{properties: v1.NamedProperties{Name: "app.txt", Data: "server: ["}},
+		{properties: v1.NamedProperties{Name: "app.yaml", Type: v1.Yaml, Data: "server:\n  port: 8080\n",
+			PropertiesOptions: v1.PropertiesOptions{Schema: schema}}},
+		{properties: v1.NamedProperties{Name: "app.json", Type: v1.Json, Data: `{"server": {"port": "8080"}}`,
+			PropertiesOptions: v1.PropertiesOptions{Schema: schema}}, invalid: true},
+		{properties: v1.NamedProperties{Name: "app.ini", Type: v1.Ini, Data: "[server]\nport = 8080\n",
+			PropertiesOptions: v1.PropertiesOptions{Schema: schema}}, invalid: true},
+		{properties: v1.NamedProperties{Name: "app.txt", Data: "port",
+			PropertiesOptions: v1.PropertiesOptions{Schema: schema}}, invalid: true},
+	}
+	for _, c := range cases {
+		err := ValidateConf(&c.properties)
+		if !c.invalid {
+			if err != nil {
+				t.Errorf("expected %s is valid, got %v", c.properties.Name, err)
+			}
+			continue
+		}
+		syntaxError, ok := err.(*ConfSyntaxError)
+		if !ok || syntaxError.File != c.properties.Name || syntaxError.Line != c.line {
+			t.Errorf("expected %s is invalid at line %d, got %v", c.properties.Name, c.line, err)
+		}
+	}
+}
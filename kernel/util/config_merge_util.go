@@ -0,0 +1,167 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// MergeData merge the data of the component properties on the global conf by the conf type.
+// The yaml and json are merged by the keys deeply, the ini is merged by the section and key, the text is overridden.
+func MergeData(confType v1.ConfType, base, override string) (string, error) {
+	switch confType {
+	case v1.Yaml:
+		return mergeStructured(base, override, func(data []byte, v interface{}) error {
+			return yaml.Unmarshal(data, v, useNumber)
+		}, yaml.Marshal)
+	case v1.Json:
+		return mergeStructured(base, override, func(data []byte, v interface{}) error {
+			return useNumber(json.NewDecoder(bytes.NewReader(data))).Decode(v)
+		}, func(v interface{}) ([]byte, error) {
+			return json.MarshalIndent(v, "", "  ")
+		})
+	case v1.Ini:
+		return mergeIni(base, override)
+	default:
+		return override, nil
+	}
+}
+
+// useNumber the numbers are kept as they are, e.g. the large integer is not converted to the float.
+func useNumber(decoder *json.Decoder) *json.Decoder {
+	decoder.UseNumber()
+	return decoder
+}
+
+func mergeStructured(base, override string, unmarshal func([]byte, interface{}) error, marshal func(interface{}) ([]byte, error)) (string, error) {
+	var b, o interface{}
+	if err := unmarshal([]byte(base), &b); err != nil {
+		return "", fmt.Errorf("parse the global conf failed, %v", err)
+	}
+	if err := unmarshal([]byte(override), &o); err != nil {
+		return "", fmt.Errorf("parse the component properties failed, %v", err)
+	}
+	data, err := marshal(mergeValue(b, o))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// mergeValue the maps are merged by the keys, the other values are overridden.
+func mergeValue(base, override interface{}) interface{} {
+	b, ok := base.(map[string]interface{})
+	o, isMap := override.(map[string]interface{})
+	if !ok || !isMap {
+		return override
+	}
+	for k, v := range o {
+		if bv, exists := b[k]; exists {
+			b[k] = mergeValue(bv, v)
+		} else {
+			b[k] = v
+		}
+	}
+	return b
+}
+
+type (
+	// iniSection the lines of the ini section, the keys are indexed to the lines.
+	iniSection struct {
+		name  string
+		lines []string
+		keys  map[string]int
+	}
+
+	iniFile struct {
+		sections []*iniSection
+		index    map[string]*iniSection
+	}
+)
+
+func (this *iniFile) section(name string) *iniSection {
+	if s, ok := this.index[name]; ok {
+		return s
+	}
+	s := &iniSection{name: name, keys: map[string]int{}}
+	if len(name) > 0 {
+		s.lines = append(s.lines, fmt.Sprintf("[%s]", name))
+	}
+	this.sections = append(this.sections, s)
+	this.index[name] = s
+	return s
+}
+
+// set the line of the key, the existing key keeps its position.
+func (this *iniSection) set(key, line string) {
+	if i, ok := this.keys[key]; ok {
+		this.lines[i] = line
+		return
+	}
+	this.keys[key] = len(this.lines)
+	this.lines = append(this.lines, line)
+}
+
+// parseIni the sections of the ini, the comments and the blank lines of the base are kept in place.
+func parseIni(data string, file *iniFile, isBase bool) error {
+	current := file.section("")
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case len(trimmed) == 0 || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			if isBase {
+				current.lines = append(current.lines, line)
+			}
+		case strings.HasPrefix(trimmed, "["):
+			if !strings.HasSuffix(trimmed, "]") {
+				return &ConfSyntaxError{Line: n, Err: fmt.Errorf("the section %q is not closed", trimmed)}
+			}
+			current = file.section(strings.TrimSpace(trimmed[1 : len(trimmed)-1]))
+		default:
+			i := strings.IndexAny(trimmed, "=:")
+			if i <= 0 {
+				return &ConfSyntaxError{Line: n, Err: fmt.Errorf("%q is not a key value", trimmed)}
+			}
+			current.set(strings.TrimSpace(trimmed[:i]), line)
+		}
+	}
+	return scanner.Err()
+}
+
+// object the values of the keys, the keys out of the sections are at the top, and the sections are the nested objects.
+func (this *iniFile) object() map[string]interface{} {
+	object := map[string]interface{}{}
+	for _, s := range this.sections {
+		values := object
+		if len(s.name) > 0 {
+			values = map[string]interface{}{}
+			object[s.name] = values
+		}
+		for key, i := range s.keys {
+			line := strings.TrimSpace(s.lines[i])
+			values[key] = strings.TrimSpace(line[strings.IndexAny(line, "=:")+1:])
+		}
+	}
+	return object
+}
+
+func mergeIni(base, override string) (string, error) {
+	file := &iniFile{index: map[string]*iniSection{}}
+	if err := parseIni(base, file, true); err != nil {
+		return "", fmt.Errorf("parse the global conf failed, %v", err)
+	}
+	if err := parseIni(override, file, false); err != nil {
+		return "", fmt.Errorf("parse the component properties failed, %v", err)
+	}
+	var lines []string
+	for _, s := range file.sections {
+		lines = append(lines, s.lines...)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,172 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/kuberator/api/core"
+	v1 "github.com/kuberator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"net"
+	"net/http"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"time"
+)
+
+// reloadPolicyOrder the restart covers the rolling restart, and both of them cover the hot reload.
+var reloadPolicyOrder = map[v1.ReloadPolicy]int{
+	v1.ReloadNone:           0,
+	v1.ReloadHTTP:           1,
+	v1.ReloadSignal:         2,
+	v1.ReloadRollingRestart: 3,
+	v1.ReloadRestart:        4,
+}
+
+// GetReloadPolicies the reload policy of the files by the key of the configMap.
+func GetReloadPolicies(component *v1.CategoryClusterComponent, properties []*v1.NamedProperties) map[string]v1.ReloadPolicy {
+	policies := map[string]v1.ReloadPolicy{}
+	for _, p := range properties {
+		if p != nil {
+			policies[p.Name] = component.GetReloadPolicy(p)
+		}
+	}
+	return policies
+}
+
+// StrongestReloadPolicy the policy which loads all the changed files, it is None when no file is changed.
+func StrongestReloadPolicy(policies ...v1.ReloadPolicy) v1.ReloadPolicy {
+	strongest := v1.ReloadNone
+	for _, policy := range policies {
+		if reloadPolicyOrder[policy] > reloadPolicyOrder[strongest] {
+			strongest = policy
+		}
+	}
+	return strongest
+}
+
+// GetConfChecksum the checksum of the files which are loaded by the rolling restart, the pod template is rolled when it is changed.
+// It is empty when no file is loaded by the rolling restart.
+func GetConfChecksum(component *v1.CategoryClusterComponent, properties []*v1.NamedProperties) string {
+	var files []v1.NamedProperties
+	for _, p := range properties {
+		if p != nil && component.GetReloadPolicy(p) == v1.ReloadRollingRestart {
+			files = append(files, *p)
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	return ConfFinger(files)
+}
+
+// GetReloadCommand the command which reloads the pods of the category referenced by the configMap.
+func GetReloadCommand(configMap client.Object, policy v1.ReloadPolicy, message string) *core.ActionCommand {
+	if configMap == nil {
+		return nil
+	}
+	return &core.ActionCommand{
+		Action:  v1.Reload,
+		Message: message,
+		TargetResource: &core.ReferenceObject{
+			Target:  configMap,
+			Extends: policy,
+		},
+	}
+}
+
+// GetReloadDelay the kubelet refreshes the configMap volume periodically, the pods are reloaded after the delay.
+func GetReloadDelay() time.Duration {
+	t := os.Getenv("RELOAD_DELAY")
+	if len(t) > 0 {
+		ot, err := strconv.Atoi(t)
+		if err == nil && ot >= 0 {
+			return time.Duration(ot) * time.Second
+		}
+	}
+	return 90 * time.Second
+}
+
+// Reload the running pod loads the changed conf by the signal or the http endpoint.
+func (cli *ReconcileClient) Reload(ctx context.Context, pod corev1.Pod, policy v1.ReloadPolicy, reload *v1.ComponentReload) error {
+	switch policy {
+	case v1.ReloadSignal:
+		container := pod.Spec.Containers[0].Name
+		if reload != nil && len(reload.Container) > 0 {
+			container = reload.Container
+		}
+		return cli.Exec(ctx, pod, container, "kill", "-HUP", "1")
+	case v1.ReloadHTTP:
+		if reload == nil || reload.HTTP == nil {
+			return errors.New("the reload endpoint is not set")
+		}
+		return callReloadEndpoint(ctx, pod, reload.HTTP)
+	}
+	return nil
+}
+
+// Exec run the command in the container of the pod, the stderr is returned when it is failed.
+func (cli *ReconcileClient) Exec(ctx context.Context, pod corev1.Pod, container string, command ...string) error {
+	if cli.Config == nil {
+		return errors.New("the rest config is not set, can not exec in the pod")
+	}
+	clientSet, err := kubernetes.NewForConfig(cli.Config)
+	if err != nil {
+		return err
+	}
+	request := clientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(cli.Config, http.MethodPost, request.URL())
+	if err != nil {
+		return err
+	}
+	var stdout, stderr bytes.Buffer
+	if err = executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("exec %v in the pod %s failed, %v %s", command, pod.Name, err, stderr.String())
+	}
+	return nil
+}
+
+// callReloadEndpoint post the reload endpoint of the pod, the certificate of the pod is not verified.
+func callReloadEndpoint(ctx context.Context, pod corev1.Pod, endpoint *v1.ReloadEndpoint) error {
+	if len(pod.Status.PodIP) == 0 {
+		return fmt.Errorf("the pod %s has no ip", pod.Name)
+	}
+	scheme := "http"
+	if endpoint.Scheme == corev1.URISchemeHTTPS {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(endpoint.Port))), endpoint.Path)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("call the reload endpoint of the pod %s failed, %v", pod.Name, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("call the reload endpoint of the pod %s failed, %s", pod.Name, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"strconv"
+	"strings"
+)
+
+// ConfSyntaxError the data of the file can not be parsed by its conf type, or does not match its schema.
+// The line is 0 when it is unknown.
+type ConfSyntaxError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (this *ConfSyntaxError) Error() string {
+	var builder strings.Builder
+	if len(this.File) > 0 {
+		builder.WriteString(fmt.Sprintf("the conf %s is invalid, ", this.File))
+	}
+	if this.Line > 0 {
+		builder.WriteString(fmt.Sprintf("line %d: ", this.Line))
+	}
+	builder.WriteString(this.Err.Error())
+	return builder.String()
+}
+
+// yamlLineError the line of the yaml error, e.g. yaml: line 3: could not find expected ':'.
+var yamlLineError = regexp.MustCompile(`yaml: line (\d+): (.*)$`)
+
+// ValidateConf parse the data of the properties by its conf type, then validate it with the JSON Schema of the properties.
+// The text is not parsed, and it can not be validated by the schema.
+func ValidateConf(properties *v1.NamedProperties) error {
+	value, err := parseConf(properties.Type, properties.Data)
+	if err != nil {
+		var syntaxError *ConfSyntaxError
+		if !errors.As(err, &syntaxError) {
+			syntaxError = &ConfSyntaxError{Err: err}
+		}
+		syntaxError.File = properties.Name
+		return syntaxError
+	}
+	if len(properties.Schema) == 0 {
+		return nil
+	}
+	if properties.Type != v1.Yaml && properties.Type != v1.Json && properties.Type != v1.Ini {
+		return &ConfSyntaxError{File: properties.Name, Err: fmt.Errorf("the schema is not supported by the %s type", properties.Type)}
+	}
+	schema, err := v1.CompileConfSchema(properties)
+	if err != nil {
+		return &ConfSyntaxError{File: properties.Name, Err: err}
+	}
+	if err = schema.Validate(value); err != nil {
+		return &ConfSyntaxError{File: properties.Name, Err: err}
+	}
+	return nil
+}
+
+// parseConf the value of the data as the json value, the text is not parsed.
+func parseConf(confType v1.ConfType, data string) (interface{}, error) {
+	switch confType {
+	case v1.Yaml:
+		converted, err := yaml.YAMLToJSON([]byte(data))
+		if err != nil {
+			if match := yamlLineError.FindStringSubmatch(err.Error()); match != nil {
+				line, _ := strconv.Atoi(match[1])
+				return nil, &ConfSyntaxError{Line: line, Err: errors.New(match[2])}
+			}
+			return nil, err
+		}
+		return decodeJson(converted)
+	case v1.Json:
+		var value interface{}
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			var syntaxError *json.SyntaxError
+			if errors.As(err, &syntaxError) {
+				return nil, &ConfSyntaxError{Line: lineOf(data, syntaxError.Offset), Err: err}
+			}
+			return nil, err
+		}
+		return decodeJson([]byte(data))
+	case v1.Ini:
+		file := &iniFile{index: map[string]*iniSection{}}
+		if err := parseIni(data, file, true); err != nil {
+			return nil, err
+		}
+		return file.object(), nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeJson the numbers are kept as the json number, so that the integer is validated by the schema.
+func decodeJson(data []byte) (interface{}, error) {
+	var value interface{}
+	err := useNumber(json.NewDecoder(bytes.NewReader(data))).Decode(&value)
+	return value, err
+}
+
+// lineOf the line of the offset in the data.
+func lineOf(data string, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return strings.Count(data[:offset], "\n") + 1
+}
@@ -0,0 +1,118 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	. "github.com/kuberator/kernel/common"
+	"math/big"
+	"time"
+)
+
+// CADuration the validity of the cluster CA, the CA is not renewed by the operator.
+const CADuration = 10 * 365 * 24 * time.Hour
+
+// GenerateCA the self-signed CA, the certificate and key are PEM encoded.
+func GenerateCA(commonName string) ([]byte, []byte, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return createCertificate(template, nil, nil, CADuration)
+}
+
+// IssueCertificate the certificate signed by the CA for both the server and the client auth, so that the peers can use the mutual TLS.
+func IssueCertificate(caCert, caKey []byte, commonName string, dnsNames []string, duration time.Duration) ([]byte, []byte, error) {
+	ca, err := ParseCertificate(caCert)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(caKey)
+	if block == nil {
+		return nil, nil, errors.New("the CA key is not PEM encoded")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	return createCertificate(template, ca, key, duration)
+}
+
+func createCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, duration time.Duration) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-5 * time.Minute)
+	template.NotAfter = now.Add(duration)
+	// the self-signed certificate.
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+// ParseCertificate the first certificate of the PEM data.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("the certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// GetTLSCAName the secret name of the cluster CA.
+func GetTLSCAName(cluster string) string {
+	return GetComponentShotName(cluster, TLSCACategory)
+}
+
+// GetTLSSecretName the secret name of the component certificate.
+func GetTLSSecretName(cluster string, component *v1.CategoryClusterComponent) string {
+	return GetComponentShotName(cluster, v1.Category(fmt.Sprintf("%s-%s", component.GetCategory(), TLSSecret)))
+}
+
+// GetCertificateSecretName the secret name of the component certificate issued by the cert-manager.
+func GetCertificateSecretName(cluster string, component *v1.CategoryClusterComponent) string {
+	return GetComponentShotName(cluster, v1.Category(fmt.Sprintf("%s-%s", component.GetCategory(), Certificate)))
+}
+
+// GetTLSDurations the validity and the renew before of the component certificate.
+func GetTLSDurations(tls *v1.ClusterTLS) (time.Duration, time.Duration) {
+	duration, renewBefore := v1.DefaultTLSDuration, v1.DefaultTLSRenewBefore
+	if tls.Duration != nil {
+		duration = tls.Duration.Duration
+	}
+	if tls.RenewBefore != nil {
+		renewBefore = tls.RenewBefore.Duration
+	}
+	return duration, renewBefore
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestIssueCertificate(t *testing.T) {
+	caCert, caKey, err := GenerateCA("cluster-ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dnsNames := []string{"cluster-svc", "cluster-svc.ns.svc", "cluster-component-0.cluster-svc.ns.svc"}
+	cert, _, err := IssueCertificate(caCert, caKey, "cluster-component", dnsNames, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca, err := ParseCertificate(caCert)
+	if err != nil || !ca.IsCA {
+		t.Fatalf("expected the CA certificate, got %v %v", ca, err)
+	}
+	leaf, err := ParseCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = leaf.CheckSignatureFrom(ca); err != nil {
+		t.Errorf("expected the certificate is signed by the CA, got %v", err)
+	}
+	if leaf.NotAfter.After(time.Now().Add(time.Hour)) {
+		t.Errorf("unexpected expiry %v", leaf.NotAfter)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, name := range dnsNames {
+		if _, err = leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots}); err != nil {
+			t.Errorf("expected the certificate covers %s, got %v", name, err)
+		}
+	}
+
+	if _, err = ParseCertificate([]byte("invalid")); err == nil {
+		t.Errorf("expected the invalid certificate is rejected")
+	}
+}
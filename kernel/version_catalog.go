@@ -0,0 +1,82 @@
+package kernel
+
+import (
+	"fmt"
+	v1 "github.com/kuberator/api/v1"
+	"github.com/kuberator/kernel/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolveCatalog get the version catalog referenced by the cluster, and merge the properties of the components on the
+// default properties of their category, so that the conf of the components is built with them.
+// The missing catalog is reported by the status, the images left empty are not resolved without it.
+func ResolveCatalog(reconcile *ReconcileContext) error {
+	spec := reconcile.Crd.GetSpec()
+	reconcile.Catalog = nil
+	if len(spec.Catalog) == 0 {
+		return nil
+	}
+	catalog := &v1.MiddlewareVersionCatalog{ObjectMeta: metav1.ObjectMeta{Name: spec.Catalog}}
+	if err := reconcile.Get(reconcile.Context, catalog); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			reconcile.Log.Info("the version catalog is not found", "catalog", spec.Catalog)
+			return nil
+		}
+		return err
+	}
+	reconcile.Catalog = catalog
+
+	version := catalog.GetVersion(spec.Version)
+	if version == nil {
+		return nil
+	}
+	for _, component := range spec.Components {
+		if component == nil {
+			continue
+		}
+		defaults := version.GetComponent(component.GetCategory())
+		if defaults == nil || len(defaults.Properties) == 0 {
+			continue
+		}
+		merged, err := util.MergeConf(defaults.Properties, component.Properties)
+		if err != nil {
+			return fmt.Errorf("merge the properties of %s on the catalog %s failed, %v", component.GetCategory(), catalog.Name, err)
+		}
+		component.Properties = merged
+	}
+	return nil
+}
+
+// ResolveImages set the images which the containers of the component leave empty by the version catalog,
+// the container without the image is an error.
+func ResolveImages(reconcile *ReconcileContext, component *v1.CategoryClusterComponent) error {
+	spec := reconcile.Crd.GetSpec()
+	var defaults *v1.CatalogComponent
+	if reconcile.Catalog != nil {
+		if version := reconcile.Catalog.GetVersion(spec.Version); version != nil {
+			defaults = version.GetComponent(component.GetCategory())
+		}
+	}
+	resolve := func(containers []corev1.Container) error {
+		for i := range containers {
+			if len(containers[i].Image) > 0 {
+				continue
+			}
+			if defaults != nil {
+				containers[i].Image = defaults.GetImage(containers[i].Name)
+			}
+			if len(containers[i].Image) == 0 {
+				return fmt.Errorf("the image of the container %s is empty, and it is not resolved by the catalog %q of the version %s",
+					containers[i].Name, spec.Catalog, spec.Version)
+			}
+		}
+		return nil
+	}
+	podSpec := &component.Template.Spec
+	if err := resolve(podSpec.InitContainers); err != nil {
+		return err
+	}
+	return resolve(podSpec.Containers)
+}
@@ -0,0 +1,95 @@
+package kernel
+
+import (
+	v1 "github.com/kuberator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func newCatalogReconcile() (*ReconcileContext, *v1.CategoryClusterComponent) {
+	component := &v1.CategoryClusterComponent{
+		CommonCategoryComponent: v1.CommonCategoryComponent{Category: "server"},
+		Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "server"}, {Name: "exporter", Image: "exporter:1.0"}},
+		}},
+	}
+	crd := &v1.MiddlewareCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"},
+		Spec: v1.MiddlewareClusterSpec{
+			Version:    "3.8",
+			Catalog:    "zookeeper",
+			Components: []*v1.CategoryClusterComponent{component},
+		},
+	}
+	catalog := &v1.MiddlewareVersionCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "zookeeper"},
+		Spec: v1.MiddlewareVersionCatalogSpec{Versions: []v1.CatalogVersion{
+			{Version: "3.7", Deprecated: true, Message: "upgrade to 3.8"},
+			{Version: "3.8", Components: []v1.CatalogComponent{{
+				Category: "server",
+				Image:    "zookeeper:3.8",
+				Images:   map[string]string{"init": "busybox:1.36"},
+			}}},
+		}},
+	}
+	return &ReconcileContext{Crd: crd, Catalog: catalog}, component
+}
+
+func TestResolveImages(t *testing.T) {
+	reconcile, component := newCatalogReconcile()
+	if err := ResolveImages(reconcile, component); err != nil {
+		t.Fatal(err)
+	}
+	spec := component.Template.Spec
+	if spec.InitContainers[0].Image != "busybox:1.36" {
+		t.Errorf("expected the image of the container name, got %s", spec.InitContainers[0].Image)
+	}
+	if spec.Containers[0].Image != "zookeeper:3.8" || spec.Containers[1].Image != "exporter:1.0" {
+		t.Errorf("expected only the empty image is resolved by the category, got %v", spec.Containers)
+	}
+
+	reconcile, component = newCatalogReconcile()
+	reconcile.Crd.(*v1.MiddlewareCluster).Spec.Version = "3.7"
+	if err := ResolveImages(reconcile, component); err == nil {
+		t.Errorf("expected the empty image is an error when the version has no image")
+	}
+}
+
+func TestReduceCatalog(t *testing.T) {
+	cases := []struct {
+		version string
+		catalog bool
+		status  metav1.ConditionStatus
+		reason  string
+	}{
+		{"3.8", true, metav1.ConditionTrue, "VersionListed"},
+		{"3.7", true, metav1.ConditionFalse, "VersionDeprecated"},
+		{"3.6", true, metav1.ConditionFalse, "VersionUnlisted"},
+		{"3.8", false, metav1.ConditionFalse, "CatalogNotFound"},
+	}
+	for _, c := range cases {
+		reconcile, _ := newCatalogReconcile()
+		crd := reconcile.Crd.(*v1.MiddlewareCluster)
+		crd.Spec.Version = c.version
+		if !c.catalog {
+			reconcile.Catalog = nil
+		}
+		ReduceCatalog(reconcile)
+		condition := meta.FindStatusCondition(crd.Status.Conditions, v1.ConditionVersionSupported)
+		if condition == nil || condition.Status != c.status || condition.Reason != c.reason {
+			t.Errorf("expected %s %s of the version %s, got %v", c.status, c.reason, c.version, condition)
+		}
+	}
+
+	reconcile, _ := newCatalogReconcile()
+	crd := reconcile.Crd.(*v1.MiddlewareCluster)
+	ReduceCatalog(reconcile)
+	crd.Spec.Catalog = ""
+	ReduceCatalog(reconcile)
+	if len(crd.Status.Conditions) > 0 {
+		t.Errorf("expected the condition is removed without the catalog, got %v", crd.Status.Conditions)
+	}
+}
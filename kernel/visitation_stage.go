@@ -26,6 +26,11 @@ func VisitationStage(reconcile *ReconcileContext, source core.TypedCategoryCompo
 		Logger:   reconcile.Log,
 	}
 
+	// the secret may keep the legacy auth already, so that nothing is written and the auth is removed here.
+	if err := retireLegacyAuth(reconcile, source, observed); err != nil {
+		reconcile.Log.Error(err, "remove the v1beta1 auth kept by the secret failed", "name", source.GetName())
+	}
+
 	// usr define per apply not return action, it will be use base action.
 	if sh != nil {
 		command = sh.Visitation(args)
@@ -18,7 +18,9 @@ package main
 
 import (
 	"flag"
+	"github.com/kuberator/kernel/common"
 	"github.com/kuberator/kernel/util"
+	"k8s.io/client-go/discovery"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -32,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	appsv1 "github.com/kuberator/api/v1"
 	appsv1beta1 "github.com/kuberator/api/v1beta1"
 	"github.com/kuberator/controllers"
 	//+kubebuilder:scaffold:imports
@@ -46,6 +49,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(appsv1beta1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -82,15 +86,39 @@ func main() {
 		os.Exit(1)
 	}
 
+	// choose the api version of the build-in resource served by the cluster.
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	versions, err := common.SelectBuildInVersions(discoveryClient, mgr.GetScheme())
+	if err != nil {
+		setupLog.Error(err, "unable to select build-in resource versions")
+		os.Exit(1)
+	}
+	setupLog.Info("build-in resource versions selected", "versions", versions)
+
 	if err = (&controllers.MiddlewareClusterReconciler{
 		Log:      ctrl.Log.WithName("controllers").WithName("MiddlewareCluster"),
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("MiddlewareCluster"),
+		Config:   mgr.GetConfig(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MiddlewareCluster")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&appsv1.MiddlewareCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MiddlewareCluster")
+			os.Exit(1)
+		}
+		if err = (&appsv1beta1.MiddlewareCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MiddlewareCluster", "version", "v1beta1")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {